@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/urfave/cli/v2"
@@ -11,12 +12,15 @@ import (
 	"github.com/peak/s5cmd/v2/log"
 	"github.com/peak/s5cmd/v2/log/stat"
 	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/ratelimit"
 	"github.com/peak/s5cmd/v2/storage"
 )
 
 const (
 	defaultWorkerCount = 256
+	autoMaxWorkerCount = 4 * defaultWorkerCount
 	defaultRetryCount  = 10
+	numWorkersAuto     = "auto"
 
 	appName = "s5cmd"
 )
@@ -30,10 +34,10 @@ var app = &cli.App{
 			Name:  "json",
 			Usage: "enable JSON formatted output",
 		},
-		&cli.IntFlag{
+		&cli.GenericFlag{
 			Name:  "numworkers",
-			Value: defaultWorkerCount,
-			Usage: "number of workers execute operation on each object",
+			Value: &NumWorkersValue{Default: defaultWorkerCount},
+			Usage: "number of workers execute operation on each object, or auto to adjust it to throttling and throughput",
 		},
 		&cli.IntFlag{
 			Name:    "retry-count",
@@ -90,17 +94,39 @@ var app = &cli.App{
 			Name:  "credentials-file",
 			Usage: "use the specified credentials file instead of the default credentials file",
 		},
+		&cli.StringFlag{
+			Name:  "failed-ops",
+			Usage: "write the failed operations to the file as commands, which can be retried with the run command",
+		},
+		&cli.StringFlag{
+			Name:  "limit-rate",
+			Usage: "limit the total bandwidth of transfers, e.g. 50MB/s",
+		},
+		&cli.StringFlag{
+			Name:  "limit-upload-rate",
+			Usage: "limit the bandwidth of uploads, e.g. 20MB/s",
+		},
+		&cli.StringFlag{
+			Name:  "limit-download-rate",
+			Usage: "limit the bandwidth of downloads, e.g. 20MB/s",
+		},
 	},
 	Before: func(c *cli.Context) error {
 		retryCount := c.Int("retry-count")
-		workerCount := c.Int("numworkers")
+		workerCount := numWorkers(c)
 		printJSON := c.Bool("json")
 		logLevel := c.String("log")
 		isStat := c.Bool("stat")
 		endpointURL := c.String("endpoint-url")
 
 		log.Init(logLevel, printJSON)
-		parallel.Init(workerCount)
+		if autoNumWorkers(c) {
+			parallel.InitAdaptive(autoMaxWorkerCount)
+			storage.SetThrottledFunc(parallel.Throttled)
+			stat.Gauge("concurrency", func() int64 { return int64(parallel.Concurrency()) })
+		} else {
+			parallel.Init(workerCount)
+		}
 
 		if retryCount < 0 {
 			err := fmt.Errorf("retry count cannot be a negative value")
@@ -118,6 +144,18 @@ var app = &cli.App{
 			return err
 		}
 
+		if err := initRateLimits(c); err != nil {
+			printError(commandFromContext(c), c.Command.Name, err)
+			return err
+		}
+
+		if failedOpsPath := c.String("failed-ops"); failedOpsPath != "" {
+			if err := openFailedOps(failedOpsPath); err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+				return err
+			}
+		}
+
 		if isStat {
 			stat.InitStat()
 		}
@@ -169,8 +207,14 @@ var app = &cli.App{
 		if c.Bool("stat") && len(stat.Statistics()) > 0 {
 			log.Stat(stat.Statistics())
 		}
+		if c.Bool("stat") && len(stat.Gauges()) > 0 {
+			log.Stat(stat.Gauges())
+		}
 
 		parallel.Close()
+		if err := closeFailedOps(); err != nil {
+			printError(commandFromContext(c), c.Command.Name, err)
+		}
 		log.Close()
 		return nil
 	},
@@ -178,6 +222,9 @@ var app = &cli.App{
 
 // NewStorageOpts creates storage.Options object from the given context.
 func NewStorageOpts(c *cli.Context) storage.Options {
+	// the key is validated by the commands which accept it.
+	sseCustomerKey, _ := loadSSECustomerKey(c, "sse-c-key")
+
 	return storage.Options{
 		DryRun:                 c.Bool("dry-run"),
 		Endpoint:               c.String("endpoint-url"),
@@ -190,6 +237,8 @@ func NewStorageOpts(c *cli.Context) storage.Options {
 		CredentialFile:         c.String("credentials-file"),
 		LogLevel:               log.LevelFromString(c.String("log")),
 		NoSuchUploadRetryCount: c.Int("no-such-upload-retry-count"),
+		SSECustomerKey:         sseCustomerKey,
+		UseIgnoreFiles:         c.Bool("use-ignore-files"),
 	}
 }
 
@@ -210,6 +259,15 @@ func Commands() []*cli.Command {
 		NewVersionCommand(),
 		NewBucketVersionCommand(),
 		NewPresignCommand(),
+		NewJournalCommand(),
+		NewTagCommand(),
+		NewRestoreCommand(),
+		NewStatCommand(),
+		NewSetmetaCommand(),
+		NewMPUCommand(),
+		NewRestorePrefixCommand(),
+		NewUndeleteCommand(),
+		NewExtractCommand(),
 	}
 }
 
@@ -229,3 +287,39 @@ func Main(ctx context.Context, args []string) error {
 
 	return app.RunContext(ctx, args)
 }
+
+// numWorkers returns the number of workers given with the numworkers flag.
+// The default number of workers is returned if it is "auto".
+func numWorkers(c *cli.Context) int {
+	n, err := strconv.Atoi(c.String("numworkers"))
+	if err != nil {
+		return defaultWorkerCount
+	}
+	return n
+}
+
+// autoNumWorkers reports whether the number of workers is adjusted at runtime.
+func autoNumWorkers(c *cli.Context) bool {
+	return c.String("numworkers") == numWorkersAuto
+}
+
+// initRateLimits parses the rate limit flags and sets the limits shared by all
+// transfers.
+func initRateLimits(c *cli.Context) error {
+	var rates [3]int64
+	for i, name := range []string{"limit-rate", "limit-upload-rate", "limit-download-rate"} {
+		value := c.String(name)
+		if value == "" {
+			continue
+		}
+
+		rate, err := ratelimit.ParseRate(value)
+		if err != nil {
+			return fmt.Errorf("bad value for --%v: %w", name, err)
+		}
+		rates[i] = rate
+	}
+
+	ratelimit.Init(rates[0], rates[1], rates[2])
+	return nil
+}
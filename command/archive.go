@@ -0,0 +1,386 @@
+package command
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/progressbar"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// archiveIndexSuffix is appended to the keys of the archives to get the keys
+// of their indexes.
+const archiveIndexSuffix = ".index.json"
+
+// archiveFormat is a format of the archives which local files are uploaded
+// as.
+type archiveFormat struct {
+	// compression of the archive, nil for the tar archives which are not
+	// compressed.
+	compression *compression
+	contentType string
+}
+
+// archiveFormats are the supported archive formats, keyed by their names.
+var archiveFormats = map[string]archiveFormat{
+	"tar": {
+		contentType: "application/x-tar",
+	},
+	"tar.gz": {
+		compression: &gzipCompression,
+		contentType: "application/gzip",
+	},
+	"tar.zst": {
+		compression: &zstdCompression,
+		contentType: "application/zstd",
+	},
+}
+
+// NewArchiveFlags returns the flags of uploading local files as an archive.
+func NewArchiveFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "archive",
+			Usage: "upload the source files as a single archive object of the given format, tar, tar.gz or tar.zst, e.g. --archive tar.gz",
+		},
+		&cli.BoolFlag{
+			Name:  "archive-index",
+			Usage: "upload the index of the members of a tar archive next to it, which allows extracting single members with ranged requests",
+		},
+	}
+}
+
+// lookupArchiveFormat returns the archive format of the given name. It
+// returns nil if the name is empty.
+func lookupArchiveFormat(name string) (*archiveFormat, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	format, ok := archiveFormats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported archive format %q: must be tar, tar.gz or tar.zst", name)
+	}
+	return &format, nil
+}
+
+// validateArchive validates the archive flags of the copy command.
+func validateArchive(c *cli.Context, srcurl, dsturl *url.URL) error {
+	if !c.IsSet("archive") {
+		if c.Bool("archive-index") {
+			return fmt.Errorf(`"archive-index" flag requires "archive" flag`)
+		}
+		return nil
+	}
+
+	format, err := lookupArchiveFormat(c.String("archive"))
+	if err != nil {
+		return err
+	}
+
+	if srcurl.IsRemote() || !dsturl.IsRemote() {
+		return fmt.Errorf(`"archive" flag is only supported for uploads`)
+	}
+
+	if dsturl.IsBucket() || dsturl.IsPrefix() {
+		return fmt.Errorf("target %q must be an object", dsturl)
+	}
+
+	if c.IsSet("compress") {
+		return fmt.Errorf(`"archive" and "compress" flags cannot be used together`)
+	}
+
+	if c.Bool("resume") {
+		return fmt.Errorf(`"resume" flag cannot be used with "archive" flag`)
+	}
+
+	if c.Bool("archive-index") {
+		if format.compression != nil {
+			return fmt.Errorf(`"archive-index" flag is only supported for tar archives`)
+		}
+		if c.IsSet("cse-key-file") || c.IsSet("cse-passphrase-file") {
+			return fmt.Errorf(`"archive-index" flag cannot be used with client-side encryption`)
+		}
+	}
+	return nil
+}
+
+// archiveIndex is the index of the members of a tar archive, which is
+// uploaded next to the archive.
+type archiveIndex struct {
+	Members []archiveMember `json:"members"`
+}
+
+// archiveMember is a member of a tar archive in its index.
+type archiveMember struct {
+	Name string `json:"name"`
+	// Offset is the offset of the content of the member in the archive.
+	Offset  int64     `json:"offset"`
+	Size    int64     `json:"size"`
+	Mode    int64     `json:"mode"`
+	ModTime time.Time `json:"mod_time"`
+	IsDir   bool      `json:"is_dir,omitempty"`
+}
+
+// archiveIndexURL returns the url of the index of the archive.
+func archiveIndexURL(u *url.URL) (*url.URL, error) {
+	return url.New(u.String()+archiveIndexSuffix, url.WithRaw(true))
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// archiveWriter writes the tar archive of local files to a writer, and keeps
+// the index of the written members.
+type archiveWriter struct {
+	tw *tar.Writer
+	// tarw counts the bytes of the tar archive to find the offsets of the
+	// members.
+	tarw *countingWriter
+	// zw compresses the archive, it is nil for the archives which are not
+	// compressed.
+	zw    io.WriteCloser
+	index archiveIndex
+}
+
+func newArchiveWriter(w io.Writer, format *archiveFormat) *archiveWriter {
+	a := &archiveWriter{}
+	if format.compression != nil {
+		a.zw = format.compression.newWriter(w)
+		w = a.zw
+	}
+	a.tarw = &countingWriter{w: w}
+	a.tw = tar.NewWriter(a.tarw)
+	return a
+}
+
+// add writes the member of the given name to the archive. The content of the
+// regular files is read from r.
+func (a *archiveWriter) add(name string, fi os.FileInfo, r io.Reader) error {
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+
+	hdr.Name = filepath.ToSlash(name)
+	if fi.IsDir() {
+		hdr.Name += "/"
+	}
+
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	member := archiveMember{
+		Name:    hdr.Name,
+		Offset:  a.tarw.n,
+		Size:    hdr.Size,
+		Mode:    hdr.Mode,
+		ModTime: hdr.ModTime.UTC(),
+		IsDir:   fi.IsDir(),
+	}
+
+	if !fi.IsDir() {
+		if _, err := io.CopyN(a.tw, r, hdr.Size); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+	}
+
+	a.index.Members = append(a.index.Members, member)
+	return nil
+}
+
+// close writes the end of the archive.
+func (a *archiveWriter) close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	if a.zw != nil {
+		return a.zw.Close()
+	}
+	return nil
+}
+
+// archiveUpload streams the archive of the source files into the upload of
+// the destination object.
+type archiveUpload struct {
+	*archiveWriter
+
+	pw *io.PipeWriter
+	// size is the size of the uploaded object.
+	size *countingWriter
+	// err is the error which stopped writing the archive.
+	err  error
+	done chan error
+}
+
+// startArchiveUpload starts uploading the archive of the source files, which
+// are added to the archive afterwards.
+func (c Copy) startArchiveUpload(ctx context.Context) (*archiveUpload, error) {
+	// override destination region if set
+	if c.dstRegion != "" {
+		c.storageOpts.SetRegion(c.dstRegion)
+	}
+	dstClient, err := storage.NewRemoteStorage(ctx, c.dst, c.storageOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := storage.Metadata{
+		UserDefined:        c.metadata,
+		ACL:                c.acl,
+		CacheControl:       c.cacheControl,
+		Expires:            c.expires,
+		StorageClass:       string(c.storageClass),
+		ContentType:        c.archive.contentType,
+		ContentEncoding:    c.contentEncoding,
+		ContentDisposition: c.contentDisposition,
+		EncryptionMethod:   c.encryptionMethod,
+		EncryptionKeyID:    c.encryptionKeyID,
+		SSECustomerKey:     c.sseCustomerKey,
+		Tags:               c.tags,
+	}
+	if c.contentType != "" {
+		metadata.ContentType = c.contentType
+	}
+
+	pr, pw := io.Pipe()
+	body, userDefined, err := encryptReader(c.cseKey, pr, metadata.UserDefined)
+	if err != nil {
+		return nil, err
+	}
+	metadata.UserDefined = userDefined
+
+	size := &countingWriter{w: pw}
+	upload := &archiveUpload{
+		archiveWriter: newArchiveWriter(size, c.archive),
+		pw:            pw,
+		size:          size,
+		done:          make(chan error, 1),
+	}
+
+	go func() {
+		err := dstClient.Put(ctx, body, c.dst, metadata, c.concurrency, c.partSize)
+		// stop writing the archive if the upload fails.
+		pr.CloseWithError(err)
+		upload.done <- err
+	}()
+	return upload, nil
+}
+
+// addFile adds the local file of the object to the archive. It returns the
+// errors of the files which are not added to the archive. The errors which
+// stop writing the archive are returned once by finish.
+func (a *archiveUpload) addFile(object *storage.Object, name string, pb progressbar.ProgressBar) error {
+	if a.err != nil {
+		return nil
+	}
+
+	file, err := os.Open(object.URL.Absolute())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := a.add(name, fi, newCountingReaderWriter(file, pb)); err != nil {
+		a.err = err
+		a.pw.CloseWithError(err)
+		return nil
+	}
+	pb.IncrementCompletedObjects()
+	return nil
+}
+
+// finish writes the end of the archive and waits for the upload to finish.
+func (a *archiveUpload) finish() error {
+	if a.err == nil {
+		a.err = a.close()
+	}
+	a.pw.CloseWithError(a.err)
+
+	err := <-a.done
+	if a.err != nil {
+		return a.err
+	}
+	return err
+}
+
+// finishArchiveUpload finishes the upload of the archive and uploads its
+// index if asked.
+func (c Copy) finishArchiveUpload(ctx context.Context, upload *archiveUpload) error {
+	if err := upload.finish(); err != nil {
+		return err
+	}
+
+	if c.archiveIndex {
+		indexurl, err := archiveIndexURL(c.dst)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(upload.index)
+		if err != nil {
+			return err
+		}
+
+		if c.dstRegion != "" {
+			c.storageOpts.SetRegion(c.dstRegion)
+		}
+		dstClient, err := storage.NewRemoteStorage(ctx, indexurl, c.storageOpts)
+		if err != nil {
+			return err
+		}
+
+		metadata := storage.Metadata{
+			ACL:              c.acl,
+			StorageClass:     string(c.storageClass),
+			ContentType:      "application/json",
+			EncryptionMethod: c.encryptionMethod,
+			EncryptionKeyID:  c.encryptionKeyID,
+			SSECustomerKey:   c.sseCustomerKey,
+		}
+		err = dstClient.Put(ctx, strings.NewReader(string(data)), indexurl, metadata, c.concurrency, c.partSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.showProgress {
+		msg := log.InfoMessage{
+			Operation:   c.op,
+			Source:      c.src,
+			Destination: c.dst,
+			Object: &storage.Object{
+				Size:         upload.size.n,
+				StorageClass: c.storageClass,
+			},
+		}
+		log.Info(msg)
+	}
+	return nil
+}
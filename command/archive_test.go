@@ -0,0 +1,183 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestArchiveWriter(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	workdir := fs.NewDir(t, "archive",
+		fs.WithFile("a.txt", "content of a", fs.WithTimestamps(modTime, modTime)),
+		fs.WithDir("sub",
+			fs.WithFile("b.txt", "content of b"),
+			fs.WithFile("empty.txt", ""),
+		),
+	)
+
+	format, err := lookupArchiveFormat("TAR")
+	assert.NilError(t, err)
+
+	var buf bytes.Buffer
+	w := newArchiveWriter(&buf, format)
+
+	for _, name := range []string{"a.txt", "sub", "sub/b.txt", "sub/empty.txt"} {
+		file, err := os.Open(filepath.Join(workdir.Path(), name))
+		assert.NilError(t, err)
+
+		fi, err := file.Stat()
+		assert.NilError(t, err)
+
+		assert.NilError(t, w.add(name, fi, file))
+		file.Close()
+	}
+	assert.NilError(t, w.close())
+
+	archive := buf.Bytes()
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.DeepEqual(t, names, []string{"a.txt", "sub/", "sub/b.txt", "sub/empty.txt"})
+
+	expected := map[string]string{
+		"a.txt":         "content of a",
+		"sub/b.txt":     "content of b",
+		"sub/empty.txt": "",
+	}
+
+	members := w.index.Members
+	assert.Equal(t, len(members), 4)
+	assert.Assert(t, members[1].IsDir)
+	assert.Assert(t, members[0].ModTime.Equal(modTime))
+	for _, member := range members {
+		if member.IsDir {
+			continue
+		}
+		content := archive[member.Offset : member.Offset+member.Size]
+		assert.Equal(t, string(content), expected[member.Name])
+	}
+}
+
+func TestLookupArchiveFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := lookupArchiveFormat("")
+	assert.NilError(t, err)
+	assert.Assert(t, format == nil)
+
+	format, err = lookupArchiveFormat("tar.gz")
+	assert.NilError(t, err)
+	assert.Equal(t, format.compression.encoding, "gzip")
+
+	format, err = lookupArchiveFormat("TAR.ZST")
+	assert.NilError(t, err)
+	assert.Equal(t, format.compression.encoding, "zstd")
+	assert.Equal(t, format.contentType, "application/zstd")
+
+	_, err = lookupArchiveFormat("zip")
+	assert.ErrorContains(t, err, `unsupported archive format "zip": must be tar, tar.gz or tar.zst`)
+}
+
+func TestMemberPath(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		expected string
+		err      string
+	}{
+		{name: "a.txt", expected: "a.txt"},
+		{name: "sub/", expected: "sub"},
+		{name: "./sub/../b.txt", expected: "b.txt"},
+		{name: "../a.txt", err: `member "../a.txt" is outside of the destination directory`},
+		{name: "sub/../../a.txt", err: `member "sub/../../a.txt" is outside of the destination directory`},
+		{name: "/etc/passwd", err: `member "/etc/passwd" is outside of the destination directory`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := memberPath(tc.name)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.expected)
+		})
+	}
+}
+
+func TestReadArchiveIndexAndMember(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	u, err := url.New("mem://test-read-archive-index/backup.tar")
+	assert.NilError(t, err)
+
+	client, err := storage.NewRemoteStorage(ctx, u, storage.Options{})
+	assert.NilError(t, err)
+
+	modTime := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	index := archiveIndex{
+		Members: []archiveMember{
+			{Name: "dir/file.txt", Offset: 4, Size: 7, Mode: 0600, ModTime: modTime},
+		},
+	}
+	data, err := json.Marshal(index)
+	assert.NilError(t, err)
+
+	indexurl, err := archiveIndexURL(u)
+	assert.NilError(t, err)
+	assert.Equal(t, indexurl.String(), "mem://test-read-archive-index/backup.tar.index.json")
+
+	assert.NilError(t, client.Put(ctx, bytes.NewReader([]byte("....content....")), u, storage.Metadata{}, 1, 5*megabytes))
+	assert.NilError(t, client.Put(ctx, bytes.NewReader(data), indexurl, storage.Metadata{}, 1, 5*megabytes))
+
+	got, err := readArchiveIndex(ctx, client, u)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, &index)
+
+	member := got.Members[0]
+	rc, err := client.ReadRange(ctx, u, member.Offset, member.Size)
+	assert.NilError(t, err)
+	defer rc.Close()
+
+	pathname := filepath.Join(t.TempDir(), "file.txt")
+	assert.NilError(t, writeMember(pathname, member, rc))
+
+	content, err := os.ReadFile(pathname)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "content")
+
+	fi, err := os.Stat(pathname)
+	assert.NilError(t, err)
+	assert.Equal(t, fi.Mode().Perm(), os.FileMode(0600))
+	assert.Assert(t, fi.ModTime().Equal(modTime))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/peak/s5cmd/v2/encryption"
 	"github.com/peak/s5cmd/v2/log/stat"
 	"github.com/peak/s5cmd/v2/orderedwriter"
 	"github.com/peak/s5cmd/v2/storage"
@@ -28,6 +29,9 @@ Examples:
 
 	2. Print specific version of a remote object's content to stdout
 		 > s5cmd {{.HelpName}} --version-id VERSION_ID s3://bucket/prefix/object
+
+	3. Print the decompressed content of an object compressed with gzip content encoding
+		 > s5cmd {{.HelpName}} --decompress s3://bucket/prefix/object.gz
 `
 
 func NewCatCommand() *cli.Command {
@@ -56,6 +60,23 @@ func NewCatCommand() *cli.Command {
 				Value:   defaultPartSize,
 				Usage:   "size of each part transferred between host and remote server, in MiB",
 			},
+			&cli.StringFlag{
+				Name:  "sse-c-key",
+				Usage: "customer provided 256-bit key (raw, hex or base64) of the object encrypted on the server side",
+			},
+			&cli.StringFlag{
+				Name:  "sse-c-key-file",
+				Usage: "read the customer provided key of server side encryption from the given file",
+			},
+			&cli.StringFlag{
+				Name:  "cse-key-file",
+				Usage: "decrypt the objects encrypted on the client side with the 256-bit key in the given file (raw, hex or base64)",
+			},
+			&cli.StringFlag{
+				Name:  "cse-passphrase-file",
+				Usage: "decrypt the objects encrypted on the client side with a key derived from the passphrase in the given file",
+			},
+			NewDecompressFlag(),
 		},
 		CustomHelpTemplate: catHelpTemplate,
 		Before: func(c *cli.Context) error {
@@ -78,6 +99,12 @@ func NewCatCommand() *cli.Command {
 				return err
 			}
 
+			cseKey, err := clientEncryptionKey(c)
+			if err != nil {
+				printError(fullCommand, op, err)
+				return err
+			}
+
 			return Cat{
 				src:         src,
 				op:          op,
@@ -86,6 +113,8 @@ func NewCatCommand() *cli.Command {
 				storageOpts: NewStorageOpts(c),
 				concurrency: c.Int("concurrency"),
 				partSize:    c.Int64("part-size") * megabytes,
+				cseKey:      cseKey,
+				decompress:  c.Bool("decompress"),
 			}.Run(c.Context)
 		},
 	}
@@ -102,22 +131,32 @@ type Cat struct {
 	storageOpts storage.Options
 	concurrency int
 	partSize    int64
+
+	// cseKey is the key of client-side encryption.
+	cseKey *encryption.Key
+
+	// decompress the object by its content encoding.
+	decompress bool
 }
 
 // Run prints content of given source to standard output.
 func (c Cat) Run(ctx context.Context) error {
-	client, err := storage.NewRemoteClient(ctx, c.src, c.storageOpts)
+	client, err := storage.NewRemoteStorage(ctx, c.src, c.storageOpts)
 	if err != nil {
 		printError(c.fullCommand, c.op, err)
 		return err
 	}
-	_, err = client.Stat(ctx, c.src)
+	obj, err := client.Stat(ctx, c.src)
 	if err != nil {
 		printError(c.fullCommand, c.op, err)
 		return err
 	}
-	buf := orderedwriter.New(os.Stdout)
-	_, err = client.Get(ctx, c.src, buf, c.concurrency, c.partSize)
+	if c.decompress {
+		_, err = getDecompressedObject(ctx, client, obj, c.cseKey, os.Stdout, c.concurrency, c.partSize)
+	} else {
+		buf := orderedwriter.New(os.Stdout)
+		_, err = getObject(ctx, client, obj, c.cseKey, buf, c.concurrency, c.partSize)
+	}
 	if err != nil {
 		printError(c.fullCommand, c.op, err)
 		return err
@@ -152,5 +191,9 @@ func validateCatCommand(c *cli.Context) error {
 		return err
 	}
 
-	return nil
+	if err := validateSSECustomerKey(c); err != nil {
+		return err
+	}
+
+	return validateClientEncryption(c)
 }
@@ -0,0 +1,185 @@
+package command
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/encryption"
+	"github.com/peak/s5cmd/v2/orderedwriter"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// compression is a compression format of the uploaded objects, which is
+// stored as the content encoding of the objects.
+type compression struct {
+	// encoding is the content encoding of the compressed objects.
+	encoding string
+	// suffix is appended to the keys of the compressed objects if asked.
+	suffix string
+
+	newWriter func(w io.Writer) io.WriteCloser
+	newReader func(r io.Reader) (io.Reader, error)
+}
+
+// gzipCompression is the gzip compression format.
+var gzipCompression = compression{
+	encoding:  "gzip",
+	suffix:    ".gz",
+	newWriter: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	newReader: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+}
+
+// zstdCompression is the zstd compression format.
+var zstdCompression = compression{
+	encoding: "zstd",
+	suffix:   ".zst",
+	newWriter: func(w io.Writer) io.WriteCloser {
+		// the encoder can only fail with invalid options.
+		enc, _ := zstd.NewWriter(w)
+		return enc
+	},
+	newReader: func(r io.Reader) (io.Reader, error) {
+		// the decoder with a concurrency of 1 decodes in the calling
+		// goroutine, hence it does not need to be closed.
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return dec, nil
+	},
+}
+
+// compressions are the supported compression formats, keyed by their names.
+var compressions = map[string]compression{
+	"gzip": gzipCompression,
+	"zstd": zstdCompression,
+}
+
+// NewCompressFlags returns the flags of compressing the uploaded objects.
+func NewCompressFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress the uploaded objects on the fly and set their content encoding, gzip or zstd, e.g. --compress gzip",
+		},
+		&cli.BoolFlag{
+			Name:  "compress-suffix",
+			Usage: "append the extension of the compression, e.g. .gz or .zst, to the keys of the compressed objects",
+		},
+	}
+}
+
+// NewDecompressFlag returns the flag of decompressing the downloaded objects.
+func NewDecompressFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "decompress",
+		Usage: "decompress the downloaded objects by their content encodings, gzip or zstd",
+	}
+}
+
+// lookupCompression returns the compression of the given name. It returns
+// nil if the name is empty.
+func lookupCompression(name string) (*compression, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	comp, ok := compressions[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression %q: must be gzip or zstd", name)
+	}
+	return &comp, nil
+}
+
+// validateCompression validates the compression flags of the commands which
+// upload objects.
+func validateCompression(c *cli.Context) error {
+	if _, err := lookupCompression(c.String("compress")); err != nil {
+		return err
+	}
+
+	if c.Bool("compress-suffix") && !c.IsSet("compress") {
+		return fmt.Errorf(`"compress-suffix" flag requires "compress" flag`)
+	}
+
+	if c.IsSet("compress") && c.IsSet("content-encoding") {
+		return fmt.Errorf(`"compress" and "content-encoding" flags cannot be used together`)
+	}
+	return nil
+}
+
+// compressReader returns a reader of the compressed content of r. The reader
+// must be closed to release the compressing goroutine.
+func compressReader(comp *compression, r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w := comp.newWriter(pw)
+		_, err := io.Copy(w, r)
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// withCompressionSuffix returns the url of the compressed object with the
+// suffix of the compression.
+func withCompressionSuffix(comp *compression, u *url.URL) (*url.URL, error) {
+	return url.New(u.String()+comp.suffix, url.WithRaw(true))
+}
+
+// getDecompressedObject writes the content of the object to w, which is
+// decompressed by the content encoding of the object, and returns the size
+// of the decompressed content. The objects which are not compressed are
+// written as they are. The parts of the object are downloaded concurrently
+// but decompressed in order.
+func getDecompressedObject(
+	ctx context.Context,
+	client storage.RemoteStorage,
+	obj *storage.Object,
+	key *encryption.Key,
+	w io.Writer,
+	concurrency int,
+	partSize int64,
+) (int64, error) {
+	encoding := strings.ToLower(strings.TrimSpace(obj.ContentEncoding))
+	if encoding == "" || encoding == "identity" {
+		return getObject(ctx, client, obj, key, orderedwriter.New(w), concurrency, partSize)
+	}
+
+	var comp *compression
+	for _, c := range compressions {
+		if c.encoding == encoding || "x-"+c.encoding == encoding {
+			c := c
+			comp = &c
+		}
+	}
+	if comp == nil {
+		return 0, fmt.Errorf("unsupported content encoding %q of %q", obj.ContentEncoding, obj.URL)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := getObject(ctx, client, obj, key, orderedwriter.New(pw), concurrency, partSize)
+		pw.CloseWithError(err)
+	}()
+
+	r, err := comp.newReader(pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return 0, err
+	}
+
+	n, err := io.Copy(w, r)
+	// stop the download if the content can not be decompressed.
+	pr.CloseWithError(err)
+	return n, err
+}
@@ -0,0 +1,145 @@
+package command
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestCompressReader(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("s5cmd ", 1000)
+
+	testcases := []struct {
+		name      string
+		newReader func(r io.Reader) (io.Reader, error)
+	}{
+		{
+			name:      "GZIP",
+			newReader: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:      "zstd",
+			newReader: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			comp, err := lookupCompression(tc.name)
+			assert.NilError(t, err)
+
+			compressed := compressReader(comp, strings.NewReader(content))
+			defer compressed.Close()
+
+			r, err := tc.newReader(compressed)
+			assert.NilError(t, err)
+
+			got, err := io.ReadAll(r)
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), content)
+		})
+	}
+}
+
+func TestLookupCompression(t *testing.T) {
+	t.Parallel()
+
+	comp, err := lookupCompression("")
+	assert.NilError(t, err)
+	assert.Assert(t, comp == nil)
+
+	comp, err = lookupCompression("zstd")
+	assert.NilError(t, err)
+	assert.Equal(t, comp.suffix, ".zst")
+
+	_, err = lookupCompression("lz4")
+	assert.ErrorContains(t, err, `unsupported compression "lz4": must be gzip or zstd`)
+}
+
+func TestGetDecompressedObject(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	content := strings.Repeat("s5cmd ", 1000)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, err := zw.Write([]byte(content))
+	assert.NilError(t, err)
+	assert.NilError(t, zw.Close())
+
+	var zstdCompressed bytes.Buffer
+	zsw, err := zstd.NewWriter(&zstdCompressed)
+	assert.NilError(t, err)
+	_, err = zsw.Write([]byte(content))
+	assert.NilError(t, err)
+	assert.NilError(t, zsw.Close())
+
+	testcases := []struct {
+		name     string
+		key      string
+		body     []byte
+		encoding string
+		expected string
+	}{
+		{
+			name:     "gzip",
+			key:      "object.gz",
+			body:     compressed.Bytes(),
+			encoding: "gzip",
+			expected: content,
+		},
+		{
+			name:     "zstd",
+			key:      "object.zst",
+			body:     zstdCompressed.Bytes(),
+			encoding: "zstd",
+			expected: content,
+		},
+		{
+			name:     "not compressed",
+			key:      "object.txt",
+			body:     []byte(content),
+			expected: content,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.New("mem://test-get-decompressed-object/" + tc.key)
+			assert.NilError(t, err)
+
+			client, err := storage.NewRemoteStorage(ctx, u, storage.Options{})
+			assert.NilError(t, err)
+
+			metadata := storage.Metadata{ContentEncoding: tc.encoding}
+			assert.NilError(t, client.Put(ctx, bytes.NewReader(tc.body), u, metadata, 1, 5*megabytes))
+
+			obj, err := client.Stat(ctx, u)
+			assert.NilError(t, err)
+
+			var got bytes.Buffer
+			n, err := getDecompressedObject(ctx, client, obj, nil, &got, 1, 5*megabytes)
+			assert.NilError(t, err)
+			assert.Equal(t, n, int64(len(tc.expected)))
+			assert.Equal(t, got.String(), tc.expected)
+		})
+	}
+}
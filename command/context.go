@@ -53,6 +53,13 @@ func contextValue(c *cli.Context, flagname string) []string {
 			return []string{strconv.FormatBool(c.Bool(flagname))}
 		case int, int64:
 			return []string{strconv.FormatInt(c.Int64(flagname), 10)}
+		case MapValue:
+			var result []string
+			for k, v := range val.(MapValue) {
+				result = append(result, k+"="+v)
+			}
+			sort.Strings(result)
+			return result
 		default:
 			return []string{fmt.Sprintf("%v", val)}
 		}
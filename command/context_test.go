@@ -135,6 +135,21 @@ func TestGenerateCommand(t *testing.T) {
 			},
 			expectedCommand: `cp --exclude='*.log' --exclude='*.txt' "/source/dir" "s3://bucket/prefix/"`,
 		},
+		{
+			name: "map-flag",
+			cmd:  "cp",
+			flags: []cli.Flag{
+				&MapFlag{
+					Name:  "tag",
+					Value: MapValue{"retention": "30d", "project": "s5cmd"},
+				},
+			},
+			urls: []*url.URL{
+				mustNewURL(t, "/source/dir"),
+				mustNewURL(t, "s3://bucket/prefix/"),
+			},
+			expectedCommand: `cp --tag='project=s5cmd' --tag='retention=30d' "/source/dir" "s3://bucket/prefix/"`,
+		},
 		{
 			name:  "command-with-multiple-args",
 			cmd:   "rm",
@@ -176,6 +191,15 @@ func TestGenerateCommand(t *testing.T) {
 					for _, s := range v.Value() {
 						ctx.Set(f.Name, s)
 					}
+				} else if v, ok := f.Value.(MapValue); ok {
+					values := MapValue{}
+					for key, value := range v {
+						values[key] = value
+						delete(v, key)
+					}
+					for key, value := range values {
+						ctx.Set(f.Name, key+"="+value)
+					}
 				} else {
 					ctx.Set(f.Name, f.Value.String())
 				}
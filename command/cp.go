@@ -12,10 +12,12 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/urfave/cli/v2"
 
+	"github.com/peak/s5cmd/v2/encryption"
 	errorpkg "github.com/peak/s5cmd/v2/error"
 	"github.com/peak/s5cmd/v2/log"
 	"github.com/peak/s5cmd/v2/log/stat"
@@ -30,6 +32,10 @@ const (
 	defaultPartSize        = 50 // MiB
 	megabytes              = 1024 * 1024
 	kilobytes              = 1024
+
+	// the suffix of the files which resumable downloads are written into
+	// until they complete.
+	partialDownloadSuffix = ".s5cmd-partial"
 )
 
 var copyHelpTemplate = `Name:
@@ -126,10 +132,46 @@ Examples:
 	28. Download a file from S3 preserving the ownership it was originally uploaded with
 		 > s5cmd --preserve-ownership s3://bucket/myfile.css.br myfile.css.br
 
+	29. Upload a large file to S3 and continue from the uploaded parts if it is interrupted
+		 > s5cmd {{.HelpName}} --resume bigfile.iso s3://bucket/
+
+	30. Download a large S3 object and continue from the downloaded ranges if it is interrupted
+		 > s5cmd {{.HelpName}} --resume s3://bucket/bigfile.iso .
+
+	31. Set tags of the objects during upload or copy
+		 > s5cmd {{.HelpName}} --tag "project=s5cmd" --tag "retention=30d" 'dir/*' s3://bucket/prefix/
+
+	32. Restore the archived objects with bulk retrieval and download them once they are restored
+		 > s5cmd {{.HelpName}} --restore-if-needed --restore-tier Bulk "s3://bucket/prefix/*" dir/
+
+	33. Download the objects which are larger than 1GB
+		 > s5cmd {{.HelpName}} --min-size 1G "s3://bucket/prefix/*" dir/
+
+	34. Upload a directory except the files matching the patterns of the .s5cmdignore files in it
+		 > s5cmd {{.HelpName}} --use-ignore-files dir/ s3://bucket/prefix/
+
+	35. Copy the objects into year and month prefixes by the dates in their names, e.g. 2024-05-report.csv to 2024/05/report.csv
+		 > s5cmd {{.HelpName}} --rename '(\d{4})-(\d{2})-(.*) -> $1/$2/$3' "s3://bucket/reports/*" s3://bucket/archive/
+
+	36. Upload the files into prefixes by their modification dates
+		 > s5cmd {{.HelpName}} --rename '{mtime:2006/01/02}/{basename}' 'dir/*' s3://bucket/prefix/
+
+	37. Compress the files with gzip while uploading them, and append .gz to their keys
+		 > s5cmd {{.HelpName}} --compress gzip --compress-suffix 'dir/*.log' s3://bucket/logs/
+
+	38. Download the objects and decompress them by their content encodings
+		 > s5cmd {{.HelpName}} --decompress "s3://bucket/logs/*" dir/
+
+	39. Upload a directory as a single tar archive object, with an index of its members
+		 > s5cmd {{.HelpName}} --archive tar --archive-index dir/ s3://bucket/backup.tar
+
+	40. Upload the files which match a wildcard as a single tar archive object compressed with gzip
+		 > s5cmd {{.HelpName}} --archive tar.gz 'dir/*.log' s3://bucket/logs.tar.gz
+
 `
 
 func NewSharedFlags() []cli.Flag {
-	return []cli.Flag{
+	flags := []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "no-follow-symlinks",
 			Usage: "do not follow symbolic links",
@@ -154,6 +196,10 @@ func NewSharedFlags() []cli.Flag {
 			Name:  "metadata",
 			Usage: "set arbitrary metadata for the object, e.g. --metadata 'foo=bar' --metadata 'fizz=buzz'",
 		},
+		&MapFlag{
+			Name:  "tag",
+			Usage: "set tags for the object, e.g. --tag 'project=s5cmd' --tag 'retention=30d'",
+		},
 		&cli.StringFlag{
 			Name:  "sse",
 			Usage: "perform server side encryption of the data at its destination, e.g. aws:kms",
@@ -228,7 +274,15 @@ func NewSharedFlags() []cli.Flag {
 			Name:  "preserve-ownership",
 			Usage: "preserve the ownership (owner/group) on disk while uploading and set the ownership from s3 while downloading.",
 		},
+		&cli.BoolFlag{
+			Name:  "resume",
+			Usage: "record the progress of uploads and downloads and continue the interrupted ones from where they left off",
+		},
 	}
+	flags = append(flags, NewSSECustomerKeyFlags(true)...)
+	flags = append(flags, NewFilterFlags()...)
+	flags = append(flags, NewIgnoreFlags()...)
+	return append(flags, NewClientEncryptionFlags()...)
 }
 
 func NewCopyCommandFlags() []cli.Flag {
@@ -262,7 +316,18 @@ func NewCopyCommandFlags() []cli.Flag {
 			Aliases: []string{"sp"},
 			Usage:   "show a progress bar",
 		},
+		&cli.BoolFlag{
+			Name:  "restore-if-needed",
+			Usage: "restore the archived source objects and copy them once they are restored",
+		},
+		NewDecompressFlag(),
+		&cli.StringFlag{
+			Name:  "rename",
+			Usage: "rewrite the names of the source objects under the destination with a regular expression and a template, e.g. '(\\d{4})-(\\d{2})-(.*) -> $1/$2/$3', or with a template, e.g. '{mtime:2006/01/02}/{basename}'",
+		},
 	}
+	copyFlags = append(copyFlags, NewRestoreFlags("restore-")...)
+	copyFlags = append(copyFlags, NewCompressFlags()...)
 	sharedFlags := NewSharedFlags()
 	return append(copyFlags, sharedFlags...)
 }
@@ -272,7 +337,7 @@ func NewCopyCommand() *cli.Command {
 		Name:               "cp",
 		HelpName:           "cp",
 		Usage:              "copy objects",
-		Flags:              NewCopyCommandFlags(),
+		Flags:              append(NewCopyCommandFlags(), NewArchiveFlags()...),
 		CustomHelpTemplate: copyHelpTemplate,
 		Before: func(c *cli.Context) error {
 			err := validateCopyCommand(c)
@@ -326,10 +391,39 @@ type Copy struct {
 	contentEncoding       string
 	contentDisposition    string
 	metadata              map[string]string
+	tags                  map[string]string
 	showProgress          bool
 	preserveTimestamp     bool
 	preserveOwnership     bool
+	resume                bool
 	progressbar           progressbar.ProgressBar
+	filter                objectFilter
+	ignores               ignoreFilter
+	rename                *renamer
+	decompress            bool
+
+	// restores of archived source objects
+	restoreIfNeeded bool
+	restoreDays     int64
+	restoreTier     string
+	restoreTimeout  time.Duration
+
+	// customer provided keys of server side encryption
+	sseCustomerKey           string
+	sseCopySourceCustomerKey string
+
+	// cseKey is the key of client-side encryption.
+	cseKey *encryption.Key
+
+	// compression of the uploaded objects, and whether its suffix is
+	// appended to the keys of the objects.
+	compression    *compression
+	compressSuffix bool
+
+	// archive is the format of the archive which the source files are
+	// uploaded as, and archiveIndex is whether its index is uploaded.
+	archive      *archiveFormat
+	archiveIndex bool
 
 	// patterns
 	excludePatterns []*regexp.Regexp
@@ -343,6 +437,8 @@ type Copy struct {
 	concurrency int
 	partSize    int64
 	storageOpts storage.Options
+
+	failedOps *failedOps
 }
 
 // NewCopy creates Copy from cli.Context.
@@ -377,12 +473,68 @@ func NewCopy(c *cli.Context, deleteSource bool) (*Copy, error) {
 		return nil, err
 	}
 
+	tags, ok := c.Value("tag").(MapValue)
+	if !ok {
+		err := errors.New("tag flag is not a map")
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	sseCustomerKey, err := loadSSECustomerKey(c, "sse-c-key")
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	sseCopySourceCustomerKey, err := loadSSECustomerKey(c, "sse-c-copy-source-key")
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	filter, err := newObjectFilter(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	ignores, err := newIgnoreFilter(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	rename, err := newRenamer(c.String("rename"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	compression, err := lookupCompression(c.String("compress"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	archive, err := lookupArchiveFormat(c.String("archive"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	cseKey, err := clientEncryptionKey(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
 	return &Copy{
 		src:          src,
 		dst:          dst,
 		op:           c.Command.Name,
 		fullCommand:  fullCommand,
 		deleteSource: deleteSource,
+		failedOps:    newFailedOps(c),
 		// flags
 		noClobber:             c.Bool("no-clobber"),
 		ifSizeDiffer:          c.Bool("if-size-differ"),
@@ -405,10 +557,28 @@ func NewCopy(c *cli.Context, deleteSource bool) (*Copy, error) {
 		contentEncoding:       c.String("content-encoding"),
 		contentDisposition:    c.String("content-disposition"),
 		metadata:              metadata,
+		tags:                  tags,
 		showProgress:          c.Bool("show-progress"),
 		progressbar:           commandProgressBar,
+		filter:                filter,
+		ignores:               ignores,
+		rename:                rename,
+		decompress:            c.Bool("decompress"),
+		compression:           compression,
+		compressSuffix:        c.Bool("compress-suffix"),
+		archive:               archive,
+		archiveIndex:          c.Bool("archive-index"),
 		preserveTimestamp:     c.Bool("preserve-timestamp"),
 		preserveOwnership:     c.Bool("preserve-ownership"),
+		resume:                c.Bool("resume"),
+		restoreIfNeeded:       c.Bool("restore-if-needed"),
+		restoreDays:           c.Int64("restore-days"),
+		restoreTier:           c.String("restore-tier"),
+		restoreTimeout:        restoreTimeout(c, "restore-"),
+		cseKey:                cseKey,
+
+		sseCustomerKey:           sseCustomerKey,
+		sseCopySourceCustomerKey: sseCopySourceCustomerKey,
 
 		// region settings
 		srcRegion: c.String("source-region"),
@@ -463,6 +633,7 @@ func (c Copy) Run(ctx context.Context) error {
 				os.Exit(1)
 			}
 			printError(c.fullCommand, c.op, err)
+			c.failedOps.record(err)
 			merrorWaiter = multierror.Append(merrorWaiter, err)
 		}
 	}()
@@ -490,6 +661,28 @@ func (c Copy) Run(ctx context.Context) error {
 		return err
 	}
 
+	// renamed keeps the source of each renamed destination to detect the
+	// sources which are renamed to the same destination.
+	renamed := map[string]*url.URL{}
+
+	// the archived source objects are waited to be restored outside of the
+	// worker pool.
+	var restores *restoreWaiter
+	if c.restoreIfNeeded {
+		restores = newRestoreWaiter(ctx, c.op, c.restoreTimeout, waiter)
+	}
+
+	// the source files are added to the archive instead of being uploaded
+	// one by one.
+	var archive *archiveUpload
+	if c.archive != nil {
+		archive, err = c.startArchiveUpload(ctx)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+	}
+
 	for object := range objch {
 		if errorpkg.IsCancelation(object.Err) {
 			continue
@@ -508,7 +701,7 @@ func (c Copy) Run(ctx context.Context) error {
 			continue
 		}
 
-		if object.StorageClass.IsGlacier() && !c.forceGlacierTransfer {
+		if object.StorageClass.IsGlacier() && !c.forceGlacierTransfer && !c.restoreIfNeeded {
 			if !c.ignoreGlacierWarnings {
 				err := fmt.Errorf("object '%v' is on Glacier storage", object)
 				merrorObjects = multierror.Append(merrorObjects, err)
@@ -525,9 +718,47 @@ func (c Copy) Run(ctx context.Context) error {
 			continue
 		}
 
+		if c.ignores.isExcluded(object, c.src.Prefix) {
+			continue
+		}
+
+		if !c.filter.isSelected(object) {
+			continue
+		}
+
 		srcurl := object.URL
 		var task parallel.Task
 
+		objname := destinationName(srcurl, c.flatten, isBatch)
+		if c.rename != nil {
+			objname, err = c.rename.rename(objname, object)
+			if err == nil {
+				if src, ok := renamed[objname]; ok {
+					err = fmt.Errorf("%q and %q are renamed to the same destination %q", src, srcurl, objname)
+				}
+				renamed[objname] = srcurl
+			}
+			if err != nil {
+				merrorObjects = multierror.Append(merrorObjects, err)
+				printError(c.fullCommand, c.op, err)
+				continue
+			}
+		}
+
+		if archive != nil {
+			if objname == "." {
+				continue
+			}
+
+			c.progressbar.AddTotalBytes(object.Size)
+			c.progressbar.IncrementTotalObjects()
+			if err := archive.addFile(object, objname, c.progressbar); err != nil {
+				merrorObjects = multierror.Append(merrorObjects, err)
+				printError(c.fullCommand, c.op, err)
+			}
+			continue
+		}
+
 		if object.Size == 0 && !(srcurl.Type == c.dst.Type) {
 			obj, err := client.Stat(ctx, srcurl)
 			if err == nil {
@@ -539,32 +770,90 @@ func (c Copy) Run(ctx context.Context) error {
 
 		switch {
 		case srcurl.Type == c.dst.Type: // local->local or remote->remote
-			task = c.prepareCopyTask(ctx, srcurl, c.dst, isBatch, c.metadata)
+			task = c.prepareCopyTask(ctx, srcurl, c.dst, objname, object.Size, c.metadata)
 		case srcurl.IsRemote(): // remote->local
-			task = c.prepareDownloadTask(ctx, srcurl, c.dst, isBatch, object.Type.IsDir())
+			task = c.prepareDownloadTask(ctx, srcurl, c.dst, objname, isBatch, object.Type.IsDir())
 		case c.dst.IsRemote(): // local->remote
-			task = c.prepareUploadTask(ctx, srcurl, c.dst, isBatch, c.metadata)
+			task = c.prepareUploadTask(ctx, srcurl, c.dst, objname, c.metadata)
 		default:
 			panic("unexpected src-dst pair")
 		}
+
+		if restores != nil && srcurl.IsRemote() {
+			restores.expect()
+			task = c.prepareRestoreTask(ctx, srcurl, task, restores)
+		}
 		parallel.Run(task, waiter)
 	}
+
+	if archive != nil {
+		if err := c.finishArchiveUpload(ctx, archive); err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(c.fullCommand, c.op, err)
+		}
+	}
+
+	if restores != nil {
+		restores.Wait()
+	}
 	waiter.Wait()
 	<-errDoneCh
 
 	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
 }
 
+// prepareRestoreTask returns a task which restores the source object if it
+// is archived. The given task is run once the object is restored, the object
+// is waited by the restore waiter in the meantime.
+func (c Copy) prepareRestoreTask(
+	ctx context.Context,
+	srcurl *url.URL,
+	task parallel.Task,
+	restores *restoreWaiter,
+) func() error {
+	return func() error {
+		client, err := storage.NewClient(ctx, srcurl, c.sourceStorageOpts(c.dst))
+		if err != nil {
+			restores.done()
+			return err
+		}
+
+		s3Client, ok := client.(*storage.S3)
+		if !ok {
+			restores.done()
+			return task()
+		}
+
+		restoring, err := restoreIfNeeded(ctx, s3Client, srcurl, c.restoreDays, c.restoreTier)
+		if err == nil && restoring && !c.storageOpts.DryRun {
+			restores.add(s3Client, srcurl, c.dst, task)
+			return nil
+		}
+		restores.done()
+
+		if err != nil {
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: c.dst,
+				Err: err,
+			}
+		}
+		return task()
+	}
+}
+
 func (c Copy) prepareCopyTask(
 	ctx context.Context,
 	srcurl *url.URL,
 	dsturl *url.URL,
-	isBatch bool,
+	objname string,
+	size int64,
 	metadata map[string]string,
 ) func() error {
 	return func() error {
-		dsturl = prepareRemoteDestination(srcurl, dsturl, c.flatten, isBatch)
-		err := c.doCopy(ctx, srcurl, dsturl, metadata)
+		dsturl = prepareRemoteDestination(objname, dsturl)
+		err := c.doCopy(ctx, srcurl, dsturl, size, metadata)
 		if err != nil {
 			return &errorpkg.Error{
 				Op:  c.op,
@@ -582,11 +871,12 @@ func (c Copy) prepareDownloadTask(
 	ctx context.Context,
 	srcurl *url.URL,
 	dsturl *url.URL,
+	objname string,
 	isBatch bool,
 	srcIsDir bool,
 ) func() error {
 	return func() error {
-		dsturl, err := prepareLocalDestination(ctx, srcurl, dsturl, c.flatten, isBatch, c.storageOpts, srcIsDir)
+		dsturl, err := prepareLocalDestination(ctx, objname, dsturl, c.flatten, isBatch, c.storageOpts, srcIsDir)
 		if err != nil {
 			return err
 		}
@@ -608,11 +898,18 @@ func (c Copy) prepareUploadTask(
 	ctx context.Context,
 	srcurl *url.URL,
 	dsturl *url.URL,
-	isBatch bool,
+	objname string,
 	metadata map[string]string,
 ) func() error {
 	return func() error {
-		dsturl = prepareRemoteDestination(srcurl, dsturl, c.flatten, isBatch)
+		dsturl = prepareRemoteDestination(objname, dsturl)
+		if c.compression != nil && c.compressSuffix {
+			var err error
+			dsturl, err = withCompressionSuffix(c.compression, dsturl)
+			if err != nil {
+				return err
+			}
+		}
 		err := c.doUpload(ctx, srcurl, dsturl, metadata)
 		if err != nil {
 			return &errorpkg.Error{
@@ -629,7 +926,7 @@ func (c Copy) prepareUploadTask(
 
 // doDownload is used to fetch a remote object and save as a local object.
 func (c Copy) doDownload(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
-	srcClient, err := storage.NewRemoteClient(ctx, srcurl, c.storageOpts)
+	srcClient, err := storage.NewRemoteStorage(ctx, srcurl, c.storageOpts)
 	if err != nil {
 		return err
 	}
@@ -665,14 +962,32 @@ func (c Copy) doDownload(ctx context.Context, srcurl *url.URL, dsturl *url.URL)
 		if err != nil {
 			return err
 		}
+	} else if c.resume {
+		// partial downloads are written as they are received, they can not
+		// be decrypted.
+		if encryption.IsEncrypted(srcObj.UserDefined) {
+			return fmt.Errorf(`"resume" flag cannot be used with objects encrypted on the client side`)
+		}
+		s3Client, ok := srcClient.(*storage.S3)
+		if !ok {
+			return fmt.Errorf("resuming downloads is not supported for %q urls", srcurl.Scheme)
+		}
+		size, err = c.doResumableDownload(ctx, s3Client, dstClient, srcurl, dsturl)
+		if err != nil {
+			return err
+		}
 	} else {
 		file, err := dstClient.CreateTemp(dstPath, dstFile)
 		if err != nil {
 			return err
 		}
 
-		writer := newCountingReaderWriter(file, c.progressbar)
-		size, err = srcClient.Get(ctx, srcurl, writer, c.concurrency, c.partSize)
+		if c.decompress {
+			size, err = getDecompressedObject(ctx, srcClient, srcObj, c.cseKey, file, c.concurrency, c.partSize)
+		} else {
+			writer := newCountingReaderWriter(file, c.progressbar)
+			size, err = getObject(ctx, srcClient, srcObj, c.cseKey, writer, c.concurrency, c.partSize)
+		}
 
 		file.Close()
 		if err != nil {
@@ -743,6 +1058,61 @@ func (c Copy) doDownload(ctx context.Context, srcurl *url.URL, dsturl *url.URL)
 	return nil
 }
 
+// doResumableDownload downloads the source into a partial file next to the
+// destination, which is kept along with a journal of its downloaded ranges if
+// the download fails. The partial file is moved to the destination once the
+// download completes. If the source has changed since the partial file is
+// created, the partial file is discarded and the download is started over.
+func (c Copy) doResumableDownload(
+	ctx context.Context,
+	srcClient *storage.S3,
+	dstClient *storage.Filesystem,
+	srcurl, dsturl *url.URL,
+) (int64, error) {
+	partialPath := dsturl.Absolute() + partialDownloadSuffix
+	journalPath := partialPath + ".json"
+
+	// the ranges in a journal without its partial file are lost.
+	if _, err := os.Stat(partialPath); errors.Is(err, os.ErrNotExist) {
+		if err := dstClient.RemovePartial(journalPath); err != nil {
+			return 0, err
+		}
+	}
+
+	file, size, err := c.downloadPartial(ctx, srcClient, dstClient, srcurl, partialPath, journalPath)
+	if errors.Is(err, storage.ErrObjectChanged) {
+		if err := dstClient.RemovePartial(partialPath, journalPath); err != nil {
+			return 0, err
+		}
+		file, size, err = c.downloadPartial(ctx, srcClient, dstClient, srcurl, partialPath, journalPath)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return size, dstClient.Rename(file, dsturl.Absolute())
+}
+
+// downloadPartial downloads the missing ranges of the source into the partial
+// file. The partial file is closed when the download returns.
+func (c Copy) downloadPartial(
+	ctx context.Context,
+	srcClient *storage.S3,
+	dstClient *storage.Filesystem,
+	srcurl *url.URL,
+	partialPath, journalPath string,
+) (*os.File, int64, error) {
+	file, err := dstClient.OpenPartial(partialPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	writer := newCountingReaderWriter(file, c.progressbar)
+	size, err := srcClient.GetResumable(ctx, srcurl, writer, journalPath, c.concurrency, c.partSize)
+	return file, size, err
+}
+
 func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL, extradata map[string]string) error {
 	srcClient := storage.NewLocalClient(c.storageOpts)
 
@@ -765,7 +1135,7 @@ func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL, ex
 	if c.dstRegion != "" {
 		c.storageOpts.SetRegion(c.dstRegion)
 	}
-	dstClient, err := storage.NewRemoteClient(ctx, dsturl, c.storageOpts)
+	dstClient, err := storage.NewRemoteStorage(ctx, dsturl, c.storageOpts)
 	if err != nil {
 		return err
 	}
@@ -780,6 +1150,8 @@ func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL, ex
 		ContentDisposition: c.contentDisposition,
 		EncryptionMethod:   c.encryptionMethod,
 		EncryptionKeyID:    c.encryptionKeyID,
+		SSECustomerKey:     c.sseCustomerKey,
+		Tags:               c.tags,
 	}
 
 	if c.preserveTimestamp {
@@ -809,9 +1181,41 @@ func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL, ex
 	if err != nil {
 		return err
 	}
-	if fi.IsDir() {
+	switch {
+	case fi.IsDir():
 		err = dstClient.CreateDir(ctx, dsturl, metadata)
-	} else {
+	case c.compression != nil:
+		compressed := compressReader(c.compression, reader)
+		defer compressed.Close()
+
+		metadata.ContentEncoding = c.compression.encoding
+
+		var body io.Reader
+		body, metadata.UserDefined, err = encryptReader(c.cseKey, compressed, metadata.UserDefined)
+		if err != nil {
+			return err
+		}
+		err = dstClient.Put(ctx, body, dsturl, metadata, c.concurrency, c.partSize)
+	case c.cseKey != nil:
+		var encrypted io.Reader
+		encrypted, metadata.UserDefined, err = encryptReader(c.cseKey, reader, metadata.UserDefined)
+		if err != nil {
+			return err
+		}
+		err = dstClient.Put(ctx, encrypted, dsturl, metadata, c.concurrency, c.partSize)
+	case c.resume && fi.Size() > c.partSize:
+		s3Client, ok := dstClient.(*storage.S3)
+		if !ok {
+			return fmt.Errorf("resuming uploads is not supported for %q urls", dsturl.Scheme)
+		}
+
+		var journalDir string
+		journalDir, err = storage.DefaultJournalDir()
+		if err != nil {
+			return err
+		}
+		err = s3Client.PutResumable(ctx, reader, srcurl, fi.Size(), fi.ModTime(), dsturl, metadata, c.concurrency, c.partSize, journalDir)
+	default:
 		err = dstClient.Put(ctx, reader, dsturl, metadata, c.concurrency, c.partSize)
 	}
 
@@ -848,7 +1252,7 @@ func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL, ex
 	return nil
 }
 
-func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL, extradata map[string]string) error {
+func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL, size int64, extradata map[string]string) error {
 	// override destination region if set
 	if c.dstRegion != "" {
 		c.storageOpts.SetRegion(c.dstRegion)
@@ -869,6 +1273,11 @@ func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL, extradata map
 		ContentDisposition: c.contentDisposition,
 		EncryptionMethod:   c.encryptionMethod,
 		EncryptionKeyID:    c.encryptionKeyID,
+
+		SSECustomerKey:           c.sseCustomerKey,
+		SSECopySourceCustomerKey: c.sseCopySourceCustomerKey,
+		Tags:                     c.tags,
+		SourceSize:               size,
 	}
 
 	err = c.shouldOverride(ctx, srcurl, dsturl)
@@ -880,7 +1289,19 @@ func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL, extradata map
 		return err
 	}
 
-	err = dstClient.Copy(ctx, srcurl, dsturl, metadata)
+	if srcurl.Scheme == dsturl.Scheme {
+		// the given user metadata replaces the one of the source, which must
+		// keep the keys of the objects encrypted on the client side.
+		if len(metadata.UserDefined) != 0 {
+			metadata.UserDefined, err = c.withEncryptionMetadata(ctx, srcurl, metadata.UserDefined)
+			if err != nil {
+				return err
+			}
+		}
+		err = dstClient.Copy(ctx, srcurl, dsturl, metadata, c.concurrency, c.partSize)
+	} else {
+		err = c.doTransfer(ctx, srcurl, dsturl, metadata)
+	}
 	if err != nil {
 		return err
 	}
@@ -909,6 +1330,75 @@ func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL, extradata map
 	return nil
 }
 
+// doTransfer copies an object between the remote storages of different
+// backends, which can not copy objects on the server side, by streaming the
+// source object to the destination.
+func (c Copy) doTransfer(ctx context.Context, srcurl, dsturl *url.URL, metadata storage.Metadata) error {
+	srcClient, err := storage.NewRemoteStorage(ctx, srcurl, c.sourceStorageOpts(dsturl))
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := storage.NewRemoteStorage(ctx, dsturl, c.storageOpts)
+	if err != nil {
+		return err
+	}
+
+	// the objects encrypted on the client side are transferred as is, along
+	// with their keys.
+	metadata.UserDefined, err = c.withEncryptionMetadata(ctx, srcurl, metadata.UserDefined)
+	if err != nil {
+		return err
+	}
+
+	reader, err := srcClient.Read(ctx, srcurl)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return dstClient.Put(ctx, reader, dsturl, metadata, c.concurrency, c.partSize)
+}
+
+// withEncryptionMetadata returns the given user metadata along with the
+// client-side encryption metadata of the source object, if any.
+func (c Copy) withEncryptionMetadata(ctx context.Context, srcurl *url.URL, userDefined map[string]string) (map[string]string, error) {
+	srcClient, err := storage.NewRemoteStorage(ctx, srcurl, c.sourceStorageOpts(c.dst))
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := srcClient.Stat(ctx, srcurl)
+	if err != nil {
+		return nil, err
+	}
+
+	cse := encryption.Metadata(obj.UserDefined)
+	if len(cse) == 0 {
+		return userDefined, nil
+	}
+
+	result := make(map[string]string, len(userDefined)+len(cse))
+	for k, v := range userDefined {
+		result[k] = v
+	}
+	for k, v := range cse {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// sourceStorageOpts returns the storage options to read the source objects
+// copied to the given destination. The source objects of remote copies are
+// read with the customer provided key of the copy source.
+func (c Copy) sourceStorageOpts(dsturl *url.URL) storage.Options {
+	opts := c.storageOpts
+	if dsturl.IsRemote() {
+		opts.SSECustomerKey = c.sseCopySourceCustomerKey
+	}
+	return opts
+}
+
 // shouldOverride function checks if the destination should be overridden if
 // the source-destination pair and given copy flags conform to the
 // override criteria. For example; "cp -n -s <src> <dst>" should not override
@@ -920,7 +1410,7 @@ func (c Copy) shouldOverride(ctx context.Context, srcurl *url.URL, dsturl *url.U
 		return nil
 	}
 
-	srcClient, err := storage.NewClient(ctx, srcurl, c.storageOpts)
+	srcClient, err := storage.NewClient(ctx, srcurl, c.sourceStorageOpts(dsturl))
 	if err != nil {
 		return err
 	}
@@ -974,16 +1464,9 @@ func (c Copy) shouldOverride(ctx context.Context, srcurl *url.URL, dsturl *url.U
 // prepareRemoteDestination will return a new destination URL for
 // remote->remote and local->remote copy operations.
 func prepareRemoteDestination(
-	srcurl *url.URL,
+	objname string,
 	dsturl *url.URL,
-	flatten bool,
-	isBatch bool,
 ) *url.URL {
-	objname := srcurl.Base()
-	if isBatch && !flatten {
-		objname = srcurl.Relative()
-	}
-
 	if objname == "." {
 		return dsturl
 	}
@@ -998,18 +1481,13 @@ func prepareRemoteDestination(
 // remote->local copy operations.
 func prepareLocalDestination(
 	ctx context.Context,
-	srcurl *url.URL,
+	objname string,
 	dsturl *url.URL,
 	flatten bool,
 	isBatch bool,
 	storageOpts storage.Options,
 	srcIsDir bool,
 ) (*url.URL, error) {
-	objname := srcurl.Base()
-	if isBatch && !flatten {
-		objname = srcurl.Relative()
-	}
-
 	client := storage.NewLocalClient(storageOpts)
 
 	if isBatch {
@@ -1036,22 +1514,36 @@ func prepareLocalDestination(
 	}
 	var objNotFound *storage.ErrGivenObjectNotFound
 	if errors.As(err, &objNotFound) {
+		if strings.HasSuffix(dsturl.Absolute(), "/") && !srcIsDir {
+			dsturl = dsturl.Join(objname)
+		}
 		err := client.MkdirAll(dsturl.Dir())
 		if err != nil {
 			return nil, err
 		}
-		if strings.HasSuffix(dsturl.Absolute(), "/") && !srcIsDir {
-			dsturl = dsturl.Join(objname)
-		}
 	} else {
 		if obj.Type.IsDir() && !srcIsDir {
+			// the renamed objects can be in the subdirectories.
 			dsturl = obj.URL.Join(objname)
+			err := client.MkdirAll(dsturl.Dir())
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return dsturl, nil
 }
 
+// destinationName returns the name of the source object under the
+// destination prefix or directory.
+func destinationName(srcurl *url.URL, flatten bool, isBatch bool) string {
+	if isBatch && !flatten {
+		return srcurl.Relative()
+	}
+	return srcurl.Base()
+}
+
 // statObject checks if the object from given url exists. If no object is
 // found, error and returning object would be nil.
 func statObject(ctx context.Context, url *url.URL, client storage.Storage) (*storage.Object, error) {
@@ -1094,9 +1586,13 @@ func validateCopyCommand(c *cli.Context) error {
 		return fmt.Errorf("source argument must contain wildcard character")
 	}
 
+	if err := validateArchive(c, srcurl, dsturl); err != nil {
+		return err
+	}
+
 	// 'cp dir/* s3://bucket/prefix': expect a trailing slash to avoid any
-	// surprises.
-	if srcurl.IsWildcard() && dsturl.IsRemote() && !dsturl.IsPrefix() && !dsturl.IsBucket() {
+	// surprises. The archives are uploaded as a single object.
+	if srcurl.IsWildcard() && dsturl.IsRemote() && !dsturl.IsPrefix() && !dsturl.IsBucket() && !c.IsSet("archive") {
 		return fmt.Errorf("target %q must be a bucket or a prefix", dsturl)
 	}
 
@@ -1108,7 +1604,60 @@ func validateCopyCommand(c *cli.Context) error {
 		return err
 	}
 
+	if err := validateSSECustomerKey(c); err != nil {
+		return err
+	}
+
+	if err := validateClientEncryption(c); err != nil {
+		return err
+	}
+
+	if _, err := newObjectFilter(c); err != nil {
+		return err
+	}
+
+	if _, err := newIgnoreFilter(c); err != nil {
+		return err
+	}
+
+	if _, err := newRenamer(c.String("rename")); err != nil {
+		return err
+	}
+
+	if err := validateCompression(c); err != nil {
+		return err
+	}
+
+	if c.IsSet("compress") && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf(`"compress" flag is only supported for uploads`)
+	}
+
+	if c.Bool("decompress") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf(`"decompress" flag is only supported for downloads`)
+	}
+
+	// the journals of the uploads keep the ranges of the files, which are
+	// not the ranges of the compressed content.
+	if c.Bool("resume") && c.IsSet("compress") {
+		return fmt.Errorf(`"resume" flag cannot be used with "compress" flag`)
+	}
+
+	if c.Bool("resume") && c.Bool("decompress") {
+		return fmt.Errorf(`"resume" flag cannot be used with "decompress" flag`)
+	}
+
+	if c.Bool("restore-if-needed") {
+		if err := validateRestoreTier(c.String("restore-tier")); err != nil {
+			return err
+		}
+		if c.Int64("restore-days") < 1 {
+			return fmt.Errorf("restore-days must be a positive number")
+		}
+	}
+
 	switch {
+	case c.IsSet("archive"):
+		return nil
 	case srcurl.Type == dsturl.Type:
 		return validateCopy(srcurl, dsturl)
 	case dsturl.IsRemote():
@@ -1210,6 +1759,10 @@ func (r *countingReaderWriter) ReadAt(p []byte, off int64) (int, error) {
 	return n, err
 }
 
+func (r *countingReaderWriter) Truncate(size int64) error {
+	return r.fp.Truncate(size)
+}
+
 func (r *countingReaderWriter) Seek(offset int64, whence int) (int64, error) {
 	return r.fp.Seek(offset, whence)
 }
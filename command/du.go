@@ -47,6 +47,9 @@ Examples:
 	
 	7. Show disk usage of a specific version of an object in the bucket
 		 > s5cmd {{.HelpName}} --version-id VERSION_ID s3://bucket/object
+
+	8. Show disk usage of all objects in the given storage class which are modified before a date
+		 > s5cmd {{.HelpName}} --only-storage-class STANDARD_IA --older-than 2026-01-02 "s3://bucket/*"
 `
 
 func NewSizeCommand() *cli.Command {
@@ -55,7 +58,7 @@ func NewSizeCommand() *cli.Command {
 		HelpName:           "du",
 		Usage:              "show object size usage",
 		CustomHelpTemplate: sizeHelpTemplate,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:    "group",
 				Aliases: []string{"g"},
@@ -78,7 +81,7 @@ func NewSizeCommand() *cli.Command {
 				Name:  "version-id",
 				Usage: "use the specified version of an object",
 			},
-		},
+		}, append(NewFilterFlags(), NewIgnoreFlags()...)...),
 		Before: func(c *cli.Context) error {
 			err := validateDUCommand(c)
 			if err != nil {
@@ -99,6 +102,18 @@ func NewSizeCommand() *cli.Command {
 				return err
 			}
 
+			filter, err := newObjectFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			ignores, err := newIgnoreFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
 			return Size{
 				src:         srcurl,
 				op:          c.Command.Name,
@@ -107,6 +122,8 @@ func NewSizeCommand() *cli.Command {
 				groupByClass: c.Bool("group"),
 				humanize:     c.Bool("humanize"),
 				exclude:      c.StringSlice("exclude"),
+				filter:       filter,
+				ignores:      ignores,
 
 				storageOpts: NewStorageOpts(c),
 			}.Run(c.Context)
@@ -127,6 +144,8 @@ type Size struct {
 	groupByClass bool
 	humanize     bool
 	exclude      []string
+	filter       objectFilter
+	ignores      ignoreFilter
 
 	storageOpts storage.Options
 }
@@ -165,6 +184,14 @@ func (sz Size) Run(ctx context.Context) error {
 			continue
 		}
 
+		if sz.ignores.isExcluded(object, sz.src.Prefix) {
+			continue
+		}
+
+		if !sz.filter.isSelected(object) {
+			continue
+		}
+
 		storageClass := string(object.StorageClass)
 		s := storageTotal[storageClass]
 		s.addObject(object)
@@ -271,5 +298,13 @@ func validateDUCommand(c *cli.Context) error {
 		return fmt.Errorf(versioningNotSupportedWarning, endpoint)
 	}
 
+	if _, err := newObjectFilter(c); err != nil {
+		return err
+	}
+
+	if _, err := newIgnoreFilter(c); err != nil {
+		return err
+	}
+
 	return nil
 }
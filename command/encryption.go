@@ -0,0 +1,185 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/encryption"
+	"github.com/peak/s5cmd/v2/storage"
+)
+
+// NewClientEncryptionFlags returns the flags of client-side encryption.
+func NewClientEncryptionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "cse-key-file",
+			Usage: "encrypt uploaded and decrypt downloaded objects on the client side with the 256-bit key in the given file (raw, hex or base64)",
+		},
+		&cli.StringFlag{
+			Name:  "cse-passphrase-file",
+			Usage: "encrypt uploaded and decrypt downloaded objects on the client side with a key derived from the passphrase in the given file",
+		},
+	}
+}
+
+// NewSSECustomerKeyFlags returns the flags of server side encryption with
+// customer provided keys. The flags of the key of the source objects of
+// remote copies are included if copySource is set.
+func NewSSECustomerKeyFlags(copySource bool) []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sse-c-key",
+			Usage: "customer provided 256-bit key (raw, hex or base64) of server side encryption: encrypts the uploaded and copied objects, and is used to read the encrypted objects",
+		},
+		&cli.StringFlag{
+			Name:  "sse-c-key-file",
+			Usage: "read the customer provided key of server side encryption from the given file",
+		},
+	}
+	if !copySource {
+		return flags
+	}
+	return append(flags,
+		&cli.StringFlag{
+			Name:  "sse-c-copy-source-key",
+			Usage: "customer provided 256-bit key (raw, hex or base64) of the source objects of remote copies which are encrypted on the server side",
+		},
+		&cli.StringFlag{
+			Name:  "sse-c-copy-source-key-file",
+			Usage: "read the customer provided key of the source objects of remote copies from the given file",
+		},
+	)
+}
+
+// loadSSECustomerKey returns the customer provided key given with the flag
+// of the given name, or in the file given with its "-file" counterpart. It
+// returns an empty string if no key is given.
+func loadSSECustomerKey(c *cli.Context, name string) (string, error) {
+	value, path := c.String(name), c.String(name+"-file")
+
+	var data []byte
+	switch {
+	case value != "" && path != "":
+		return "", fmt.Errorf(`"%v" and "%v-file" flags cannot be used together`, name, name)
+	case value != "":
+		data = []byte(value)
+	case path != "":
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", nil
+	}
+
+	key, err := encryption.ParseKey(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid %v: %w", name, err)
+	}
+	return string(key), nil
+}
+
+func validateSSECustomerKey(c *cli.Context) error {
+	for _, name := range []string{"sse-c-key", "sse-c-copy-source-key"} {
+		if _, err := loadSSECustomerKey(c, name); err != nil {
+			return err
+		}
+	}
+
+	if c.String("sse") != "" && (c.String("sse-c-key") != "" || c.String("sse-c-key-file") != "") {
+		return fmt.Errorf(`"sse" and "sse-c-key" flags cannot be used together`)
+	}
+	return nil
+}
+
+// isClientEncryptionSet reports whether a key of client-side encryption is
+// given.
+func isClientEncryptionSet(c *cli.Context) bool {
+	return c.String("cse-key-file") != "" || c.String("cse-passphrase-file") != ""
+}
+
+func validateClientEncryption(c *cli.Context) error {
+	if c.String("cse-key-file") != "" && c.String("cse-passphrase-file") != "" {
+		return fmt.Errorf(`"cse-key-file" and "cse-passphrase-file" flags cannot be used together`)
+	}
+	if isClientEncryptionSet(c) && c.Bool("resume") {
+		return fmt.Errorf(`"resume" flag cannot be used with client-side encryption`)
+	}
+	return nil
+}
+
+// clientEncryptionKey loads the key of client-side encryption. It returns nil
+// if no key is given.
+func clientEncryptionKey(c *cli.Context) (*encryption.Key, error) {
+	if path := c.String("cse-key-file"); path != "" {
+		return encryption.LoadKeyFile(path)
+	}
+	if path := c.String("cse-passphrase-file"); path != "" {
+		return encryption.LoadPassphraseFile(path)
+	}
+	return nil, nil
+}
+
+// encryptReader returns a reader which encrypts the content of r with a new
+// data key, and the user metadata which includes the wrapped data key. It
+// returns r and the user metadata as is if key is nil.
+func encryptReader(
+	key *encryption.Key,
+	r io.Reader,
+	userDefined map[string]string,
+) (io.Reader, map[string]string, error) {
+	if key == nil {
+		return r, userDefined, nil
+	}
+
+	envelope, metadata, err := key.NewEnvelope()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range userDefined {
+		if _, ok := metadata[k]; !ok {
+			metadata[k] = v
+		}
+	}
+	return envelope.NewEncryptingReader(r), metadata, nil
+}
+
+// getObject writes the content of the object to w, and returns its size. The
+// objects encrypted on the client side are decrypted with the given key.
+func getObject(
+	ctx context.Context,
+	client storage.RemoteStorage,
+	obj *storage.Object,
+	key *encryption.Key,
+	w io.WriterAt,
+	concurrency int,
+	partSize int64,
+) (int64, error) {
+	if !encryption.IsEncrypted(obj.UserDefined) {
+		return client.Get(ctx, obj.URL, w, concurrency, partSize)
+	}
+
+	envelope, err := key.OpenEnvelope(obj.UserDefined)
+	if err != nil {
+		return 0, err
+	}
+
+	dw, err := envelope.NewDecryptingWriterAt(w, obj.Size)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := client.Get(ctx, obj.URL, dw, concurrency, partSize); err != nil {
+		return 0, err
+	}
+	if err := dw.Close(); err != nil {
+		return 0, err
+	}
+	return dw.Size(), nil
+}
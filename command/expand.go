@@ -19,12 +19,16 @@ func expandSource(
 	followSymlinks bool,
 	srcurl *url.URL,
 ) (<-chan *storage.Object, error) {
-	var objType storage.ObjectType
+	var (
+		obj     *storage.Object
+		objType storage.ObjectType
+	)
 	// if the source is local, we send a Stat call to know if  we have
 	// directory or file to walk. For remote storage, we don't want to send
 	// Stat since it doesn't have any folder semantics.
 	if !srcurl.IsWildcard() {
-		obj, err := client.Stat(ctx, srcurl)
+		var err error
+		obj, err = client.Stat(ctx, srcurl)
 		if err != nil {
 			return nil, err
 		}
@@ -38,7 +42,15 @@ func expandSource(
 
 	ch := make(chan *storage.Object, 1)
 	if storage.ShouldProcessURL(srcurl, followSymlinks) {
-		ch <- &storage.Object{URL: srcurl, Type: objType}
+		// the size, the modification time and the etag are kept for the
+		// filters and the rename templates.
+		ch <- &storage.Object{
+			URL:     srcurl,
+			Type:    objType,
+			Size:    obj.Size,
+			ModTime: obj.ModTime,
+			Etag:    obj.Etag,
+		}
 	}
 	close(ch)
 	return ch, nil
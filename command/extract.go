@@ -0,0 +1,490 @@
+package command
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/encryption"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/orderedwriter"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+var extractHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source destination
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Extract a tar archive object into a local directory
+		 > s5cmd {{.HelpName}} s3://bucket/backup.tar dir/
+
+	2. Extract a tar archive object compressed with gzip or zstd into a local directory
+		 > s5cmd {{.HelpName}} s3://bucket/backup.tar.zst dir/
+
+	3. Extract the members of a tar archive object which match a wildcard
+		 > s5cmd {{.HelpName}} --include "logs/*" s3://bucket/backup.tar dir/
+
+	4. Extract a single member of a tar archive object with ranged requests by using the index of the archive
+		 > s5cmd {{.HelpName}} --use-index --include "logs/app.log" s3://bucket/backup.tar dir/
+`
+
+func NewExtractCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "extract",
+		HelpName: "extract",
+		Usage:    "extract tar archive objects into local directories",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "exclude the members which match the given wildcard",
+			},
+			&cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "extract only the members which match the given wildcard",
+			},
+			&cli.BoolFlag{
+				Name:  "use-index",
+				Usage: "read the members with ranged requests by using the index uploaded with the archive",
+			},
+			&cli.IntFlag{
+				Name:    "concurrency",
+				Aliases: []string{"c"},
+				Value:   defaultCopyConcurrency,
+				Usage:   "number of concurrent parts transferred between host and remote server",
+			},
+			&cli.IntFlag{
+				Name:    "part-size",
+				Aliases: []string{"p"},
+				Value:   defaultPartSize,
+				Usage:   "size of each part transferred between host and remote server, in MiB",
+			},
+			&cli.StringFlag{
+				Name:  "sse-c-key",
+				Usage: "customer provided 256-bit key (raw, hex or base64) of the archive encrypted on the server side",
+			},
+			&cli.StringFlag{
+				Name:  "sse-c-key-file",
+				Usage: "read the customer provided key of server side encryption from the given file",
+			},
+			&cli.StringFlag{
+				Name:  "cse-key-file",
+				Usage: "decrypt the archive encrypted on the client side with the 256-bit key in the given file (raw, hex or base64)",
+			},
+			&cli.StringFlag{
+				Name:  "cse-passphrase-file",
+				Usage: "decrypt the archive encrypted on the client side with a key derived from the passphrase in the given file",
+			},
+		},
+		CustomHelpTemplate: extractHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateExtractCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			extract, err := NewExtract(c)
+			if err != nil {
+				return err
+			}
+			return extract.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, false, false)
+	return cmd
+}
+
+// Extract holds extract operation flags and states.
+type Extract struct {
+	src         *url.URL
+	dst         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	excludePatterns []*regexp.Regexp
+	includePatterns []*regexp.Regexp
+	useIndex        bool
+	concurrency     int
+	partSize        int64
+
+	// cseKey is the key of client-side encryption.
+	cseKey *encryption.Key
+
+	storageOpts storage.Options
+}
+
+// NewExtract creates Extract from cli.Context.
+func NewExtract(c *cli.Context) (*Extract, error) {
+	fullCommand := commandFromContext(c)
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	dst, err := url.New(c.Args().Get(1))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	excludePatterns, err := createRegexFromWildcard(c.StringSlice("exclude"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	includePatterns, err := createRegexFromWildcard(c.StringSlice("include"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	cseKey, err := clientEncryptionKey(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	return &Extract{
+		src:         src,
+		dst:         dst,
+		op:          c.Command.Name,
+		fullCommand: fullCommand,
+
+		excludePatterns: excludePatterns,
+		includePatterns: includePatterns,
+		useIndex:        c.Bool("use-index"),
+		concurrency:     c.Int("concurrency"),
+		partSize:        c.Int64("part-size") * megabytes,
+		cseKey:          cseKey,
+
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// Run extracts the members of the archive into the destination directory.
+func (e Extract) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteStorage(ctx, e.src, e.storageOpts)
+	if err != nil {
+		printError(e.fullCommand, e.op, err)
+		return err
+	}
+
+	if err := storage.NewLocalClient(e.storageOpts).MkdirAll(e.dst.Absolute()); err != nil {
+		printError(e.fullCommand, e.op, err)
+		return err
+	}
+
+	if e.useIndex {
+		return e.extractWithIndex(ctx, client)
+	}
+	return e.extractStream(ctx, client)
+}
+
+// gzipMagic and zstdMagic are the magic numbers of the archives compressed
+// with gzip and zstd.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// extractStream extracts the members of the archive while downloading it.
+// The archives compressed with gzip or zstd are decompressed.
+func (e Extract) extractStream(ctx context.Context, client storage.RemoteStorage) error {
+	obj, err := client.Stat(ctx, e.src)
+	if err != nil {
+		printError(e.fullCommand, e.op, err)
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	// stop the download if the archive can not be extracted.
+	defer pr.Close()
+
+	go func() {
+		_, err := getObject(ctx, client, obj, e.cseKey, orderedwriter.New(pw), e.concurrency, e.partSize)
+		pw.CloseWithError(err)
+	}()
+
+	br := bufio.NewReader(pr)
+	var r io.Reader = br
+	if magic, err := br.Peek(4); err == nil {
+		switch {
+		case bytes.Equal(magic[:2], gzipMagic):
+			r, err = gzipCompression.newReader(br)
+		case bytes.Equal(magic, zstdMagic):
+			r, err = zstdCompression.newReader(br)
+		}
+		if err != nil {
+			printError(e.fullCommand, e.op, err)
+			return err
+		}
+	}
+
+	var merror error
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			printError(e.fullCommand, e.op, err)
+			return multierror.Append(merror, err).ErrorOrNil()
+		}
+
+		if e.isExcluded(hdr.Name) {
+			continue
+		}
+
+		member := archiveMember{
+			Name:    hdr.Name,
+			Size:    hdr.Size,
+			Mode:    hdr.Mode,
+			ModTime: hdr.ModTime,
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			member.IsDir = true
+		case tar.TypeReg:
+		default:
+			err := fmt.Errorf("member %q of %q is not a regular file or a directory", hdr.Name, e.src)
+			printError(e.fullCommand, e.op, err)
+			merror = multierror.Append(merror, err)
+			continue
+		}
+
+		if err := e.extractMember(member, tr); err != nil {
+			printError(e.fullCommand, e.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}
+	return merror
+}
+
+// extractWithIndex extracts the members of the archive which are read with
+// ranged requests by using the index of the archive.
+func (e Extract) extractWithIndex(ctx context.Context, client storage.RemoteStorage) error {
+	index, err := readArchiveIndex(ctx, client, e.src)
+	if err != nil {
+		printError(e.fullCommand, e.op, err)
+		return err
+	}
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(e.fullCommand, e.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	for _, member := range index.Members {
+		if e.isExcluded(member.Name) {
+			continue
+		}
+
+		member := member
+		task := func() error {
+			if member.IsDir || member.Size == 0 {
+				return e.extractMember(member, bytes.NewReader(nil))
+			}
+
+			rc, err := client.ReadRange(ctx, e.src, member.Offset, member.Size)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+
+			return e.extractMember(member, rc)
+		}
+		parallel.Run(task, waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return merror
+}
+
+// readArchiveIndex reads the index which is uploaded next to the archive.
+func readArchiveIndex(ctx context.Context, client storage.RemoteStorage, src *url.URL) (*archiveIndex, error) {
+	indexurl, err := archiveIndexURL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := client.Read(ctx, indexurl)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the index of %q: %w", src, err)
+	}
+	defer rc.Close()
+
+	var index archiveIndex
+	if err := json.NewDecoder(rc).Decode(&index); err != nil {
+		return nil, fmt.Errorf("could not read the index of %q: %w", src, err)
+	}
+	return &index, nil
+}
+
+// extractMember writes the member, whose content is read from r, under the
+// destination directory.
+func (e Extract) extractMember(member archiveMember, r io.Reader) error {
+	name, err := memberPath(member.Name)
+	if err != nil {
+		return err
+	}
+
+	dsturl := e.dst.Join(name)
+	pathname := filepath.Join(e.dst.Absolute(), filepath.FromSlash(name))
+
+	client := storage.NewLocalClient(e.storageOpts)
+	if member.IsDir {
+		return client.MkdirAll(pathname)
+	}
+
+	if err := client.MkdirAll(filepath.Dir(pathname)); err != nil {
+		return err
+	}
+
+	if !e.storageOpts.DryRun {
+		if err := writeMember(pathname, member, r); err != nil {
+			return err
+		}
+	}
+
+	msg := log.InfoMessage{
+		Operation:   e.op,
+		Source:      e.src,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size: member.Size,
+		},
+	}
+	log.Info(msg)
+	return nil
+}
+
+// writeMember writes the content of the member to the file, and sets the
+// mode and the modification time of the file.
+func writeMember(pathname string, member archiveMember, r io.Reader) error {
+	file, err := os.OpenFile(pathname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(member.Mode).Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(file, r, member.Size); err != nil {
+		file.Close()
+		return fmt.Errorf("%v: %w", member.Name, err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	modTime := member.ModTime
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	return os.Chtimes(pathname, modTime, modTime)
+}
+
+// memberPath returns the path of the member under the destination directory.
+// It fails for the members which are outside of the destination directory.
+func memberPath(name string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("member %q is outside of the destination directory", name)
+	}
+	return clean, nil
+}
+
+// isExcluded reports whether the member is excluded by the exclude and
+// include flags.
+func (e Extract) isExcluded(name string) bool {
+	if isURLMatched(e.excludePatterns, name, "") {
+		return true
+	}
+	if len(e.includePatterns) > 0 {
+		return !isURLMatched(e.includePatterns, name, "")
+	}
+	return false
+}
+
+func validateExtractCommand(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return fmt.Errorf("expected source and destination arguments")
+	}
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	dst, err := url.New(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() || src.IsPrefix() {
+		return fmt.Errorf("remote source must be an object")
+	}
+
+	if src.IsWildcard() {
+		return fmt.Errorf("remote source %q can not contain glob characters", src)
+	}
+
+	if dst.IsRemote() {
+		return fmt.Errorf("destination must be a local directory")
+	}
+
+	if c.Bool("use-index") && (c.IsSet("cse-key-file") || c.IsSet("cse-passphrase-file")) {
+		return fmt.Errorf(`"use-index" flag cannot be used with client-side encryption`)
+	}
+
+	if err := validateSSECustomerKey(c); err != nil {
+		return err
+	}
+
+	return validateClientEncryption(c)
+}
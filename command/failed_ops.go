@@ -0,0 +1,186 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// failedOpsFile is the file given with the failed-ops flag. The failed
+// operations are written to a temporary file, which replaces the file when
+// the program exits. Thus, the file can be the input of the "run" command
+// which retries the failures of its previous run.
+var failedOpsFile struct {
+	sync.Mutex
+	path string
+	file *os.File
+}
+
+// openFailedOps creates the temporary file of the failed operations.
+func openFailedOps(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	failedOpsFile.Lock()
+	defer failedOpsFile.Unlock()
+
+	failedOpsFile.path = path
+	failedOpsFile.file = file
+	return nil
+}
+
+// closeFailedOps closes the temporary file of the failed operations and
+// moves it to the path given with the failed-ops flag.
+func closeFailedOps() error {
+	failedOpsFile.Lock()
+	defer failedOpsFile.Unlock()
+
+	file := failedOpsFile.file
+	if file == nil {
+		return nil
+	}
+	failedOpsFile.file = nil
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), failedOpsFile.path)
+}
+
+// writeFailedOp writes the command to the file of the failed operations.
+func writeFailedOp(command string) error {
+	failedOpsFile.Lock()
+	defer failedOpsFile.Unlock()
+
+	if failedOpsFile.file == nil {
+		return nil
+	}
+	_, err := fmt.Fprintln(failedOpsFile.file, command)
+	return err
+}
+
+// failedOps records the failed operations of a command as commands which
+// can be executed with "run". The commands are generated with the flags of
+// the command, the same way sync generates its commands.
+type failedOps struct {
+	c    *cli.Context
+	once sync.Once
+}
+
+// newFailedOps creates a recorder of the failed operations of the command,
+// or returns nil if the failed-ops flag is not given.
+func newFailedOps(c *cli.Context) *failedOps {
+	failedOpsFile.Lock()
+	defer failedOpsFile.Unlock()
+
+	if failedOpsFile.file == nil {
+		return nil
+	}
+	return &failedOps{c: c}
+}
+
+// record records the operations of the errors, which are either
+// errorpkg.Error or aggregated errorpkg.Error errors. Cancelations and the
+// other errors are ignored.
+func (f *failedOps) record(err error) {
+	if f == nil || errorpkg.IsCancelation(err) {
+		return
+	}
+
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, err := range merr.Errors {
+			f.record(err)
+		}
+		return
+	}
+
+	cerr, ok := err.(*errorpkg.Error)
+	if !ok {
+		return
+	}
+
+	urls := []*url.URL{cerr.Src}
+	if cerr.Dst != nil {
+		urls = append(urls, cerr.Dst)
+	}
+	f.recordOp(cerr.Op, urls...)
+}
+
+// recordOp records the operation on the given urls.
+func (f *failedOps) recordOp(op string, urls ...*url.URL) {
+	if f == nil || AppCommand(op) == nil {
+		return
+	}
+
+	// the urls of the failed operations are of single objects, they are
+	// recorded in raw mode so that their glob characters are not expanded
+	// when the operations are retried.
+	var defaultFlags map[string]interface{}
+	if hasFlag(AppCommand(op), "raw") {
+		defaultFlags = map[string]interface{}{
+			"raw": true,
+		}
+	}
+
+	command, err := generateCommand(f.c, op, defaultFlags, urls...)
+	if err != nil {
+		printError(commandFromContext(f.c), f.c.Command.Name, err)
+		return
+	}
+
+	if err := writeFailedOp(command); err != nil {
+		printError(commandFromContext(f.c), f.c.Command.Name, err)
+	}
+}
+
+// hasFlag returns whether the command has the flag of the given name.
+func hasFlag(cmd *cli.Command, flagname string) bool {
+	for _, f := range cmd.Flags {
+		for _, name := range f.Names() {
+			if name == flagname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordCommand records the command itself, at most once. It is used if the
+// failed operation is not known, so that the command is retried as a whole.
+func (f *failedOps) recordCommand() {
+	if f == nil {
+		return
+	}
+
+	f.once.Do(func() {
+		command, err := generateCommand(f.c, f.c.Command.Name, nil, f.args()...)
+		if err == nil {
+			err = writeFailedOp(command)
+		}
+		if err != nil {
+			printError(commandFromContext(f.c), f.c.Command.Name, err)
+		}
+	})
+}
+
+// args returns the arguments of the command as urls.
+func (f *failedOps) args() []*url.URL {
+	var urls []*url.URL
+	for _, arg := range f.c.Args().Slice() {
+		u, err := url.New(arg, url.WithRaw(f.c.Bool("raw")))
+		if err != nil {
+			continue
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
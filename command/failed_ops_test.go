@@ -0,0 +1,65 @@
+package command
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+	"gotest.tools/v3/assert"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestFailedOpsRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed.txt")
+	assert.NilError(t, openFailedOps(path))
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "storage-class",
+			Value: "STANDARD_IA",
+		},
+	}
+	ctx := cli.NewContext(cli.NewApp(), flagSet(t, "cp", flags), nil)
+	assert.NilError(t, ctx.Set("storage-class", "STANDARD_IA"))
+
+	f := newFailedOps(ctx)
+	assert.Assert(t, f != nil)
+
+	failure := errors.New("failure")
+	f.record(&errorpkg.Error{
+		Op:  "cp",
+		Src: mustNewURL(t, "/dir/file1"),
+		Dst: mustNewURL(t, "s3://bucket/file1"),
+		Err: failure,
+	})
+	f.record(multierror.Append(
+		&errorpkg.Error{Op: "rm", Src: mustNewURL(t, "s3://bucket/file2"), Err: failure},
+		failure, // not an operation, ignored
+	))
+	f.recordOp("unknown", mustNewURL(t, "s3://bucket/file3"))
+
+	// glob characters of the keys are not expanded when retried.
+	src, err := url.New("s3://bucket/dir/file*?[1].txt", url.WithRaw(true))
+	assert.NilError(t, err)
+	dst, err := url.New("/dir/file*?[1].txt", url.WithRaw(true))
+	assert.NilError(t, err)
+	f.record(&errorpkg.Error{Op: "cp", Src: src, Dst: dst, Err: failure})
+
+	assert.NilError(t, closeFailedOps())
+
+	data, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	expected := `cp --raw='true' --storage-class='STANDARD_IA' "/dir/file1" "s3://bucket/file1"` + "\n" +
+		`rm --raw='true' "s3://bucket/file2"` + "\n" +
+		`cp --raw='true' --storage-class='STANDARD_IA' "s3://bucket/dir/file*?[1].txt" "/dir/file*?[1].txt"` + "\n"
+	assert.Equal(t, string(data), expected)
+
+	// recorders are not created without the failed-ops flag.
+	assert.Assert(t, newFailedOps(ctx) == nil)
+}
@@ -0,0 +1,171 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+// filterFlagNames are the names of the flags which select objects by their
+// attributes.
+var filterFlagNames = []string{
+	"older-than",
+	"newer-than",
+	"min-size",
+	"max-size",
+	"only-storage-class",
+}
+
+// NewFilterFlags returns the flags which select objects by their
+// modification times, sizes and storage classes.
+func NewFilterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "older-than",
+			Usage: "only select the objects which are modified before the given duration or date, e.g. 30d, 12h, 2026-01-02 or 2026-01-02T15:04:05Z",
+		},
+		&cli.StringFlag{
+			Name:  "newer-than",
+			Usage: "only select the objects which are modified after the given duration or date, e.g. 30d, 12h, 2026-01-02 or 2026-01-02T15:04:05Z",
+		},
+		&cli.StringFlag{
+			Name:  "min-size",
+			Usage: "only select the objects which are at least the given size, e.g. 512, 10K or 1.5GB",
+		},
+		&cli.StringFlag{
+			Name:  "max-size",
+			Usage: "only select the objects which are at most the given size, e.g. 512, 10K or 1.5GB",
+		},
+		&cli.StringSliceFlag{
+			Name:  "only-storage-class",
+			Usage: "only select the remote objects which are in the given storage class, e.g. --only-storage-class STANDARD_IA --only-storage-class GLACIER (--storage-class sets the storage class of the uploads and copies instead)",
+		},
+	}
+}
+
+// objectFilter selects objects by their modification times, sizes and
+// storage classes. The zero value selects all objects.
+type objectFilter struct {
+	olderThan      time.Time
+	newerThan      time.Time
+	minSize        int64
+	maxSize        int64
+	hasMaxSize     bool
+	storageClasses []string
+}
+
+// newObjectFilter creates objectFilter from the filter flags of cli.Context.
+// The durations are relative to the time the filter is created.
+func newObjectFilter(c *cli.Context) (objectFilter, error) {
+	var (
+		filter objectFilter
+		err    error
+		now    = time.Now()
+	)
+
+	if value := c.String("older-than"); value != "" {
+		filter.olderThan, err = parseFilterTime(value, now)
+		if err != nil {
+			return objectFilter{}, err
+		}
+	}
+
+	if value := c.String("newer-than"); value != "" {
+		filter.newerThan, err = parseFilterTime(value, now)
+		if err != nil {
+			return objectFilter{}, err
+		}
+	}
+
+	if value := c.String("min-size"); value != "" {
+		filter.minSize, err = strutil.ParseBytes(value)
+		if err != nil {
+			return objectFilter{}, err
+		}
+	}
+
+	if value := c.String("max-size"); value != "" {
+		filter.maxSize, err = strutil.ParseBytes(value)
+		if err != nil {
+			return objectFilter{}, err
+		}
+		filter.hasMaxSize = true
+
+		if filter.minSize > filter.maxSize {
+			return objectFilter{}, fmt.Errorf(`"min-size" can not be greater than "max-size"`)
+		}
+	}
+
+	filter.storageClasses = c.StringSlice("only-storage-class")
+	return filter, nil
+}
+
+// isFilterSet reports whether any of the filter flags is given.
+func isFilterSet(c *cli.Context) bool {
+	for _, name := range filterFlagNames {
+		if c.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelected reports whether the object passes the filter. Directories are
+// always selected. The objects without a modification time are not
+// selected by the time filters, and the objects whose storage classes are
+// not known, such as local files, are not filtered by storage class.
+func (f objectFilter) isSelected(object *storage.Object) bool {
+	if object.Type.IsDir() {
+		return true
+	}
+
+	if !f.olderThan.IsZero() || !f.newerThan.IsZero() {
+		if object.ModTime == nil {
+			return false
+		}
+		if !f.olderThan.IsZero() && !object.ModTime.Before(f.olderThan) {
+			return false
+		}
+		if !f.newerThan.IsZero() && !object.ModTime.After(f.newerThan) {
+			return false
+		}
+	}
+
+	if object.Size < f.minSize || (f.hasMaxSize && object.Size > f.maxSize) {
+		return false
+	}
+
+	if len(f.storageClasses) > 0 && object.StorageClass != "" {
+		for _, class := range f.storageClasses {
+			if strings.EqualFold(class, string(object.StorageClass)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseFilterTime parses the value of a time filter, which is either a
+// duration before now, e.g. "30d" or "12h", or a date in local time, e.g.
+// "2026-01-02", or a time in RFC3339 format.
+func parseFilterTime(value string, now time.Time) (time.Time, error) {
+	if duration, err := parseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: must be a duration, e.g. 30d, or a date, e.g. 2026-01-02", value)
+}
@@ -0,0 +1,130 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestParseFilterTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	testcases := []struct {
+		value     string
+		expected  time.Time
+		expectErr bool
+	}{
+		{value: "12h", expected: now.Add(-12 * time.Hour)},
+		{value: "30d", expected: now.Add(-30 * 24 * time.Hour)},
+		{value: "1d12h", expected: now.Add(-36 * time.Hour)},
+		{value: "2026-01-02T15:04:05Z", expected: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "2026-01-02", expected: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)},
+		{value: "-1h", expectErr: true},
+		{value: "yesterday", expectErr: true},
+		{value: "2026-13-01", expectErr: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseFilterTime(tc.value, now)
+			if tc.expectErr {
+				assert.ErrorContains(t, err, "invalid time")
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, got.Equal(tc.expected), "expected %v, got %v", tc.expected, got)
+		})
+	}
+}
+
+func TestObjectFilterIsSelected(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	newObject := func(key string, age time.Duration, size int64, class string) *storage.Object {
+		u, err := url.New("s3://bucket/" + key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-age)
+		return &storage.Object{
+			URL:          u,
+			ModTime:      &modTime,
+			Size:         size,
+			StorageClass: storage.StorageClass(class),
+		}
+	}
+
+	objects := []*storage.Object{
+		newObject("old-small", 48*time.Hour, 10, "STANDARD"),
+		newObject("old-large", 48*time.Hour, 1000, "GLACIER"),
+		newObject("new-small", time.Hour, 10, "STANDARD_IA"),
+		newObject("new-large", time.Hour, 1000, ""),
+	}
+
+	testcases := []struct {
+		name     string
+		filter   objectFilter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			filter:   objectFilter{},
+			expected: []string{"old-small", "old-large", "new-small", "new-large"},
+		},
+		{
+			name:     "older than",
+			filter:   objectFilter{olderThan: now.Add(-24 * time.Hour)},
+			expected: []string{"old-small", "old-large"},
+		},
+		{
+			name:     "newer than",
+			filter:   objectFilter{newerThan: now.Add(-24 * time.Hour)},
+			expected: []string{"new-small", "new-large"},
+		},
+		{
+			name:     "min size",
+			filter:   objectFilter{minSize: 100},
+			expected: []string{"old-large", "new-large"},
+		},
+		{
+			name:     "max size",
+			filter:   objectFilter{maxSize: 100, hasMaxSize: true},
+			expected: []string{"old-small", "new-small"},
+		},
+		{
+			name:     "older than and min size",
+			filter:   objectFilter{olderThan: now.Add(-24 * time.Hour), minSize: 100},
+			expected: []string{"old-large"},
+		},
+		{
+			name:     "storage class",
+			filter:   objectFilter{storageClasses: []string{"standard", "GLACIER"}},
+			expected: []string{"old-small", "old-large", "new-large"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, object := range objects {
+				if tc.filter.isSelected(object) {
+					got = append(got, object.URL.Path)
+				}
+			}
+			assert.DeepEqual(t, got, tc.expected)
+		})
+	}
+}
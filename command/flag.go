@@ -3,7 +3,9 @@ package command
 import (
 	"flag"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -45,6 +47,84 @@ func (e EnumValue) Get() interface{} {
 	return e
 }
 
+// NumWorkersValue is the value of a flag which is either a number of workers,
+// or "auto" to adjust the number of workers at runtime.
+type NumWorkersValue struct {
+	Default  int
+	selected string
+}
+
+func (n *NumWorkersValue) Set(value string) error {
+	if value != numWorkersAuto {
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expected a number or %q", numWorkersAuto)
+		}
+	}
+	n.selected = value
+	return nil
+}
+
+func (n NumWorkersValue) String() string {
+	if n.selected == "" {
+		return strconv.Itoa(n.Default)
+	}
+	return n.selected
+}
+
+func (n NumWorkersValue) Get() interface{} {
+	return n
+}
+
+// DurationValue is the value of a flag which is a duration. Days are
+// accepted in addition to the units of time.ParseDuration, e.g. "7d" or
+// "1d12h".
+type DurationValue struct {
+	Duration time.Duration
+	selected string
+}
+
+func (d *DurationValue) Set(value string) error {
+	duration, err := parseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	d.selected = value
+	return nil
+}
+
+func (d DurationValue) String() string {
+	return d.selected
+}
+
+func (d DurationValue) Get() interface{} {
+	return d
+}
+
+// parseDuration parses a duration which may start with a number of days.
+func parseDuration(value string) (time.Duration, error) {
+	var days int
+	rest := value
+	i := strings.Index(value, "d")
+	if i > 0 {
+		n, err := strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		days, rest = n, value[i+1:]
+	}
+
+	var duration time.Duration
+	if rest != "" || i <= 0 {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		duration = d
+	}
+	return time.Duration(days)*24*time.Hour + duration, nil
+}
+
 type MapValue map[string]string
 
 func (m MapValue) String() string {
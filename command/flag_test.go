@@ -0,0 +1,43 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		value       string
+		expected    time.Duration
+		expectedErr bool
+	}{
+		{value: "12h", expected: 12 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: "7d", expected: 7 * 24 * time.Hour},
+		{value: "1d12h", expected: 36 * time.Hour},
+		{value: "0d", expected: 0},
+		{value: "d", expectedErr: true},
+		{value: "xd", expectedErr: true},
+		{value: "7days", expectedErr: true},
+		{value: "", expectedErr: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDuration(tc.value)
+			if tc.expectedErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/ignore"
+	"github.com/peak/s5cmd/v2/storage"
+)
+
+// NewIgnoreFlags returns the flags which exclude objects by the patterns of
+// filter files and by regular expressions.
+func NewIgnoreFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "exclude-from",
+			Usage: "exclude objects matching the gitignore-style patterns of the given file, e.g. --exclude-from .gitignore",
+		},
+		&cli.StringSliceFlag{
+			Name:  "include-from",
+			Usage: "only include objects matching the gitignore-style patterns of the given file, e.g. --include-from patterns.txt",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude-regex",
+			Usage: "exclude objects whose relative paths match the given regular expression, e.g. --exclude-regex '^logs/.*\\.gz$'",
+		},
+		&cli.BoolFlag{
+			Name:  "use-ignore-files",
+			Usage: "exclude local files matching the patterns of the " + ignore.FileName + " files found while walking directories",
+		},
+	}
+}
+
+// ignoreFilter excludes objects by the patterns of filter files and by
+// regular expressions. The zero value excludes no objects.
+type ignoreFilter struct {
+	excludeRules   ignore.Rules
+	includeRules   ignore.Rules
+	excludeRegexes []*regexp.Regexp
+}
+
+// newIgnoreFilter creates ignoreFilter from the ignore flags of cli.Context.
+func newIgnoreFilter(c *cli.Context) (ignoreFilter, error) {
+	var filter ignoreFilter
+
+	for _, path := range c.StringSlice("exclude-from") {
+		rules, err := ignore.ReadFile(path)
+		if err != nil {
+			return ignoreFilter{}, err
+		}
+		filter.excludeRules = append(filter.excludeRules, rules...)
+	}
+
+	for _, path := range c.StringSlice("include-from") {
+		rules, err := ignore.ReadFile(path)
+		if err != nil {
+			return ignoreFilter{}, err
+		}
+		filter.includeRules = append(filter.includeRules, rules...)
+	}
+
+	for _, expr := range c.StringSlice("exclude-regex") {
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return ignoreFilter{}, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		filter.excludeRegexes = append(filter.excludeRegexes, regex)
+	}
+
+	return filter, nil
+}
+
+// isExcluded reports whether the object is excluded by the filter. The
+// patterns are matched against the slash separated path of the object
+// relative to the given prefix, as the wildcards of the exclude flag are.
+func (f ignoreFilter) isExcluded(object *storage.Object, prefix string) bool {
+	if len(f.excludeRules) == 0 && len(f.includeRules) == 0 && len(f.excludeRegexes) == 0 {
+		return false
+	}
+
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	path := strings.TrimPrefix(object.URL.Path, filepath.ToSlash(prefix))
+	path = strings.TrimSuffix(filepath.ToSlash(path), "/")
+
+	for _, regex := range f.excludeRegexes {
+		if regex.MatchString(path) {
+			return true
+		}
+	}
+
+	if f.excludeRules.Matches(path) {
+		return true
+	}
+
+	if len(f.includeRules) > 0 {
+		if object.Type.IsDir() {
+			return false
+		}
+		return !f.includeRules.Matches(path)
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/ignore"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestIgnoreFilterIsExcluded(t *testing.T) {
+	t.Parallel()
+
+	parse := func(rules string) ignore.Rules {
+		r, err := ignore.Parse(strings.NewReader(rules))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	keys := []string{"prefix/a.txt", "prefix/a.log", "prefix/logs/b.txt", "prefix/keep.log"}
+
+	testcases := []struct {
+		name     string
+		filter   ignoreFilter
+		expected []string
+	}{
+		{
+			name:     "no patterns",
+			filter:   ignoreFilter{},
+			expected: keys,
+		},
+		{
+			name:     "exclude rules",
+			filter:   ignoreFilter{excludeRules: parse("*.log\n!keep.log\n/logs/")},
+			expected: []string{"prefix/a.txt", "prefix/keep.log"},
+		},
+		{
+			name:     "include rules",
+			filter:   ignoreFilter{includeRules: parse("*.txt")},
+			expected: []string{"prefix/a.txt", "prefix/logs/b.txt"},
+		},
+		{
+			name:     "exclude regex",
+			filter:   ignoreFilter{excludeRegexes: []*regexp.Regexp{regexp.MustCompile(`^logs/|^a\.`)}},
+			expected: []string{"prefix/keep.log"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, key := range keys {
+				u, err := url.New("s3://bucket/" + key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tc.filter.isExcluded(&storage.Object{URL: u}, "prefix/") {
+					got = append(got, key)
+				}
+			}
+			assert.DeepEqual(t, got, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+var journalHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} command [command options] [arguments...]
+
+Commands:
+	{{range .VisibleCommands}}{{join .Names ", "}}{{"\t"}}{{.Usage}}
+	{{end}}
+Examples:
+	1. List the journals of interrupted uploads started with "cp --resume"
+		 > s5cmd {{.HelpName}} ls
+
+	2. Abort the interrupted uploads to a bucket and remove their journals
+		 > s5cmd {{.HelpName}} abort "s3://bucket/*"
+
+	3. Abort the interrupted uploads which are started more than a week ago
+		 > s5cmd {{.HelpName}} abort --older-than 7d
+`
+
+var journalAbortHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] [destination ...]
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Abort the interrupted upload of an object and remove its journal
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Abort the interrupted uploads to a bucket and remove their journals
+		 > s5cmd {{.HelpName}} "s3://bucket/*"
+
+	3. Abort the interrupted uploads which are started more than a week ago
+		 > s5cmd {{.HelpName}} --older-than 7d
+`
+
+func NewJournalCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:               "journal",
+		HelpName:           "journal",
+		Usage:              "manage journals of resumable uploads",
+		CustomHelpTemplate: journalHelpTemplate,
+		Subcommands: []*cli.Command{
+			{
+				Name:     "ls",
+				HelpName: "journal ls",
+				Usage:    "list journals of interrupted uploads",
+				Before: func(c *cli.Context) error {
+					err := checkNumberOfArguments(c, 0, 0)
+					if err != nil {
+						printError(commandFromContext(c), c.Command.Name, err)
+					}
+					return err
+				},
+				Action: func(c *cli.Context) (err error) {
+					defer stat.Collect(c.Command.FullName(), &err)()
+
+					return NewJournal(c).List()
+				},
+			},
+			{
+				Name:               "abort",
+				HelpName:           "journal abort",
+				Usage:              "abort interrupted uploads and remove their journals",
+				CustomHelpTemplate: journalAbortHelpTemplate,
+				Flags: []cli.Flag{
+					&cli.GenericFlag{
+						Name:  "older-than",
+						Value: &DurationValue{},
+						Usage: "only abort the uploads which are started before the given duration, e.g. 7d or 12h",
+					},
+				},
+				Before: func(c *cli.Context) error {
+					err := validateJournalAbortCommand(c)
+					if err != nil {
+						printError(commandFromContext(c), c.Command.Name, err)
+					}
+					return err
+				},
+				Action: func(c *cli.Context) (err error) {
+					defer stat.Collect(c.Command.FullName(), &err)()
+
+					return NewJournal(c).Abort(c.Context)
+				},
+			},
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, true)
+	return cmd
+}
+
+// Journal holds journal operation flags and states.
+type Journal struct {
+	destinations []string
+	op           string
+	fullCommand  string
+
+	// flags
+	olderThan time.Duration
+
+	storageOpts storage.Options
+}
+
+// NewJournal creates Journal from cli.Context.
+func NewJournal(c *cli.Context) Journal {
+	// only the abort command has the flag.
+	var olderThan time.Duration
+	if d, ok := c.Generic("older-than").(*DurationValue); ok {
+		olderThan = d.Duration
+	}
+
+	return Journal{
+		destinations: c.Args().Slice(),
+		op:           c.Command.Name,
+		fullCommand:  commandFromContext(c),
+		olderThan:    olderThan,
+		storageOpts:  NewStorageOpts(c),
+	}
+}
+
+// List prints the journals of the interrupted uploads.
+func (j Journal) List() error {
+	journals, err := j.journals()
+	if err != nil {
+		printError(j.fullCommand, j.op, err)
+		return err
+	}
+
+	for _, journal := range journals {
+		log.Info(newJournalMessage(journal))
+	}
+	return nil
+}
+
+// Abort aborts the interrupted uploads selected by the given destinations and
+// flags, and removes their journals.
+func (j Journal) Abort(ctx context.Context) error {
+	journals, err := j.journals()
+	if err != nil {
+		printError(j.fullCommand, j.op, err)
+		return err
+	}
+
+	patterns, err := createRegexFromWildcard(j.destinations)
+	if err != nil {
+		printError(j.fullCommand, j.op, err)
+		return err
+	}
+
+	var merr error
+	for _, journal := range journals {
+		if !j.shouldAbort(journal, patterns) {
+			continue
+		}
+
+		if err := j.abort(ctx, journal); err != nil {
+			printError(j.fullCommand, j.op, err)
+			merr = err
+		}
+	}
+	return merr
+}
+
+func (j Journal) abort(ctx context.Context, journal *storage.Journal) error {
+	dsturl, err := url.New(journal.Destination, url.WithRaw(true))
+	if err != nil {
+		return err
+	}
+
+	client, err := storage.NewRemoteClient(ctx, dsturl, j.storageOpts)
+	if err != nil {
+		return err
+	}
+
+	err = client.AbortMultipartUpload(ctx, dsturl, journal.UploadID)
+	if err != nil && !storage.IsNoSuchUploadError(err) {
+		return err
+	}
+
+	if err := journal.Remove(); err != nil {
+		return err
+	}
+
+	msg := log.InfoMessage{
+		Operation:   j.op,
+		Destination: dsturl,
+	}
+	log.Info(msg)
+	return nil
+}
+
+func (j Journal) shouldAbort(journal *storage.Journal, patterns []*regexp.Regexp) bool {
+	if j.olderThan > 0 && time.Since(journal.CreatedAt) < j.olderThan {
+		return false
+	}
+
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(journal.Destination) {
+			return true
+		}
+	}
+	return false
+}
+
+func (j Journal) journals() ([]*storage.Journal, error) {
+	dir, err := storage.DefaultJournalDir()
+	if err != nil {
+		return nil, err
+	}
+	return storage.ListJournals(dir)
+}
+
+func validateJournalAbortCommand(c *cli.Context) error {
+	if c.Args().Len() == 0 && !c.IsSet("older-than") {
+		return fmt.Errorf(`either a destination or "older-than" flag is required`)
+	}
+
+	for _, arg := range c.Args().Slice() {
+		dsturl, err := url.New(arg)
+		if err != nil {
+			return err
+		}
+		if !dsturl.IsRemote() {
+			return fmt.Errorf("destination must be a remote object: %v", arg)
+		}
+	}
+	return nil
+}
+
+// JournalMessage is a structure for logging journals of interrupted uploads.
+type JournalMessage struct {
+	Source        string    `json:"source"`
+	Destination   string    `json:"destination"`
+	UploadID      string    `json:"upload_id"`
+	Size          int64     `json:"size"`
+	UploadedParts int       `json:"uploaded_parts"`
+	Parts         int64     `json:"parts"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newJournalMessage(j *storage.Journal) JournalMessage {
+	var parts int64
+	if j.PartSize > 0 {
+		parts = (j.Size + j.PartSize - 1) / j.PartSize
+	}
+
+	return JournalMessage{
+		Source:        j.Source,
+		Destination:   j.Destination,
+		UploadID:      j.UploadID,
+		Size:          j.Size,
+		UploadedParts: len(j.Parts),
+		Parts:         parts,
+		CreatedAt:     j.CreatedAt,
+	}
+}
+
+// String returns the string representation of JournalMessage.
+func (m JournalMessage) String() string {
+	return fmt.Sprintf(
+		"%19s %12d %11s %s %s",
+		m.CreatedAt.Local().Format(dateFormat),
+		m.Size,
+		fmt.Sprintf("%d/%d", m.UploadedParts, m.Parts),
+		m.Source,
+		m.Destination,
+	)
+}
+
+// JSON returns the JSON representation of JournalMessage.
+func (m JournalMessage) JSON() string {
+	return strutil.JSON(m)
+}
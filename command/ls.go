@@ -58,6 +58,12 @@ Examples:
 	11. List all files with their fullpaths 
 		 > s5cmd {{.HelpName}} --show-fullpath "s3://bucket/*"
 
+	12. List all objects which are larger than 1GB and modified in the last day
+		 > s5cmd {{.HelpName}} --min-size 1G --newer-than 1d "s3://bucket/*"
+
+	13. List all objects except the ones matching the gitignore-style patterns of a file
+		 > s5cmd {{.HelpName}} --exclude-from .gitignore "s3://bucket/*"
+
 `
 
 func NewListCommand() *cli.Command {
@@ -66,7 +72,7 @@ func NewListCommand() *cli.Command {
 		HelpName:           "ls",
 		Usage:              "list buckets and objects",
 		CustomHelpTemplate: listHelpTemplate,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:    "etag",
 				Aliases: []string{"e"},
@@ -94,7 +100,7 @@ func NewListCommand() *cli.Command {
 				Name:  "show-fullpath",
 				Usage: "shows only the fullpath names of the object(s)",
 			},
-		},
+		}, append(NewFilterFlags(), NewIgnoreFlags()...)...),
 		Before: func(c *cli.Context) error {
 			err := validateLSCommand(c)
 			if err != nil {
@@ -120,6 +126,19 @@ func NewListCommand() *cli.Command {
 				printError(fullCommand, c.Command.Name, err)
 				return err
 			}
+
+			filter, err := newObjectFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			ignores, err := newIgnoreFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
 			return List{
 				src:         srcurl,
 				op:          c.Command.Name,
@@ -130,6 +149,8 @@ func NewListCommand() *cli.Command {
 				showStorageClass: c.Bool("storage-class"),
 				exclude:          c.StringSlice("exclude"),
 				showFullPath:     c.Bool("show-fullpath"),
+				filter:           filter,
+				ignores:          ignores,
 
 				storageOpts: NewStorageOpts(c),
 			}.Run(c.Context)
@@ -152,6 +173,8 @@ type List struct {
 	showStorageClass bool
 	showFullPath     bool
 	exclude          []string
+	filter           objectFilter
+	ignores          ignoreFilter
 
 	storageOpts storage.Options
 }
@@ -209,6 +232,14 @@ func (l List) Run(ctx context.Context) error {
 			continue
 		}
 
+		if l.ignores.isExcluded(object, l.src.Prefix) {
+			continue
+		}
+
+		if !l.filter.isSelected(object) {
+			continue
+		}
+
 		msg := ListMessage{
 			Object:           object,
 			showEtag:         l.showEtag,
@@ -337,5 +368,13 @@ func validateLSCommand(c *cli.Context) error {
 		return err
 	}
 
+	if _, err := newObjectFilter(c); err != nil {
+		return err
+	}
+
+	if _, err := newIgnoreFilter(c); err != nil {
+		return err
+	}
+
 	return nil
 }
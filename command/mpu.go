@@ -0,0 +1,315 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+var mpuHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} command [command options] [arguments...]
+
+Commands:
+	{{range .VisibleCommands}}{{join .Names ", "}}{{"\t"}}{{.Usage}}
+	{{end}}
+Examples:
+	1. List the incomplete multipart uploads in a bucket
+		 > s5cmd {{.HelpName}} ls s3://bucket
+
+	2. Abort the incomplete multipart uploads with a prefix which are started more than a week ago
+		 > s5cmd {{.HelpName}} abort --older-than 7d "s3://bucket/prefix/*"
+`
+
+var mpuLsHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. List the incomplete multipart uploads in a bucket
+		 > s5cmd {{.HelpName}} s3://bucket
+
+	2. List the incomplete multipart uploads with a prefix
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/
+
+	3. List the incomplete multipart uploads of the objects that matches a wildcard in JSON
+		 > s5cmd --json {{.HelpName}} "s3://bucket/*.gz"
+
+	4. List the incomplete multipart uploads which are started more than a day ago
+		 > s5cmd {{.HelpName}} --older-than 1d s3://bucket
+`
+
+var mpuAbortHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Abort the incomplete multipart uploads with a prefix
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/
+
+	2. Abort the incomplete multipart uploads of the objects that matches a wildcard
+		 > s5cmd {{.HelpName}} "s3://bucket/*.gz"
+
+	3. Abort the incomplete multipart uploads in a bucket which are started more than a week ago
+		 > s5cmd {{.HelpName}} --older-than 7d s3://bucket
+`
+
+func NewMPUCommand() *cli.Command {
+	newSubcommand := func(name, usage, helpTemplate string, action func(MPU, context.Context) error) *cli.Command {
+		return &cli.Command{
+			Name:     name,
+			HelpName: "mpu " + name,
+			Usage:    usage,
+			Flags: []cli.Flag{
+				&cli.GenericFlag{
+					Name:  "older-than",
+					Value: &DurationValue{},
+					Usage: "only select the uploads which are started before the given duration, e.g. 7d or 12h",
+				},
+			},
+			CustomHelpTemplate: helpTemplate,
+			Before: func(c *cli.Context) error {
+				err := validateMPUCommand(c)
+				if err != nil {
+					printError(commandFromContext(c), c.Command.Name, err)
+				}
+				return err
+			},
+			Action: func(c *cli.Context) (err error) {
+				defer stat.Collect(c.Command.FullName(), &err)()
+
+				mpu, err := NewMPU(c)
+				if err != nil {
+					return err
+				}
+				return action(mpu, c.Context)
+			},
+		}
+	}
+
+	cmd := &cli.Command{
+		Name:               "mpu",
+		HelpName:           "mpu",
+		Usage:              "manage incomplete multipart uploads",
+		CustomHelpTemplate: mpuHelpTemplate,
+		Subcommands: []*cli.Command{
+			newSubcommand("ls", "list incomplete multipart uploads", mpuLsHelpTemplate, MPU.List),
+			newSubcommand("abort", "abort incomplete multipart uploads", mpuAbortHelpTemplate, MPU.Abort),
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// MPU holds multipart upload operation flags and states.
+type MPU struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	olderThan time.Duration
+
+	storageOpts storage.Options
+}
+
+// NewMPU creates MPU from cli.Context.
+func NewMPU(c *cli.Context) (MPU, error) {
+	fullCommand := commandFromContext(c)
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return MPU{}, err
+	}
+
+	return MPU{
+		src:         src,
+		op:          c.Command.Name,
+		fullCommand: fullCommand,
+		olderThan:   c.Generic("older-than").(*DurationValue).Duration,
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// List prints the incomplete multipart uploads with their uploaded parts.
+func (m MPU) List(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, m.src, m.storageOpts)
+	if err != nil {
+		printError(m.fullCommand, m.op, err)
+		return err
+	}
+
+	var merror error
+	for upload := range client.ListMultipartUploads(ctx, m.src) {
+		if err := upload.Err; err != nil {
+			printError(m.fullCommand, m.op, err)
+			return err
+		}
+
+		if !m.shouldSelect(upload) {
+			continue
+		}
+
+		parts, size, err := client.ListParts(ctx, upload.URL, upload.UploadID)
+		if err != nil {
+			// the upload may be completed or aborted after it is listed.
+			if storage.IsNoSuchUploadError(err) {
+				continue
+			}
+			printError(m.fullCommand, m.op, err)
+			merror = multierror.Append(merror, err)
+			continue
+		}
+
+		log.Info(MultipartUploadMessage{
+			URL:          upload.URL,
+			UploadID:     upload.UploadID,
+			Initiated:    upload.Initiated,
+			StorageClass: upload.StorageClass,
+			Parts:        parts,
+			Size:         size,
+		})
+	}
+	return merror
+}
+
+// Abort aborts the incomplete multipart uploads, and deletes their uploaded
+// parts.
+func (m MPU) Abort(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, m.src, m.storageOpts)
+	if err != nil {
+		printError(m.fullCommand, m.op, err)
+		return err
+	}
+
+	var (
+		merrorWaiter  error
+		merrorUploads error
+	)
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(m.fullCommand, m.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	for upload := range client.ListMultipartUploads(ctx, m.src) {
+		if errorpkg.IsCancelation(upload.Err) {
+			continue
+		}
+
+		if err := upload.Err; err != nil {
+			merrorUploads = multierror.Append(merrorUploads, err)
+			printError(m.fullCommand, m.op, err)
+			continue
+		}
+
+		if !m.shouldSelect(upload) {
+			continue
+		}
+
+		parallel.Run(m.prepareAbortTask(ctx, client, upload), waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorUploads).ErrorOrNil()
+}
+
+func (m MPU) prepareAbortTask(ctx context.Context, client *storage.S3, upload *storage.MultipartUpload) func() error {
+	return func() error {
+		err := client.AbortMultipartUpload(ctx, upload.URL, upload.UploadID)
+		if err != nil && !storage.IsNoSuchUploadError(err) {
+			return &errorpkg.Error{
+				Op:  m.op,
+				Dst: upload.URL,
+				Err: err,
+			}
+		}
+
+		log.Info(log.InfoMessage{
+			Operation:   m.op,
+			Destination: upload.URL,
+		})
+		return nil
+	}
+}
+
+func (m MPU) shouldSelect(upload *storage.MultipartUpload) bool {
+	return m.olderThan == 0 || time.Since(upload.Initiated) >= m.olderThan
+}
+
+func validateMPUCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote url")
+	}
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote url")
+	}
+
+	return nil
+}
+
+// MultipartUploadMessage is a structure for logging incomplete multipart
+// uploads.
+type MultipartUploadMessage struct {
+	URL          *url.URL             `json:"key"`
+	UploadID     string               `json:"upload_id"`
+	Initiated    time.Time            `json:"initiated"`
+	StorageClass storage.StorageClass `json:"storage_class,omitempty"`
+	Parts        int                  `json:"parts"`
+	Size         int64                `json:"size"`
+}
+
+// String returns the string representation of MultipartUploadMessage.
+func (m MultipartUploadMessage) String() string {
+	return fmt.Sprintf(
+		"%19s %6d %12d %s %s",
+		m.Initiated.Local().Format(dateFormat),
+		m.Parts,
+		m.Size,
+		m.URL,
+		m.UploadID,
+	)
+}
+
+// JSON returns the JSON representation of MultipartUploadMessage.
+func (m MultipartUploadMessage) JSON() string {
+	return strutil.JSON(m)
+}
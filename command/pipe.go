@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"os"
 	"path/filepath"
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/peak/s5cmd/v2/encryption"
 	errorpkg "github.com/peak/s5cmd/v2/error"
 	"github.com/peak/s5cmd/v2/log"
 	"github.com/peak/s5cmd/v2/log/stat"
@@ -35,6 +37,10 @@ Examples:
 		> curl https://github.com/peak/s5cmd/ | s5cmd {{.HelpName}} s3://bucket/s5cmd.html
 	04. Compress an object and stream it to a bucket
 		> tar -cf - file.bin | s5cmd {{.HelpName}} s3://bucket/file.bin.tar
+	05. Set tags of an object
+		 > cat "flowers.png" | s5cmd {{.HelpName}} --tag "project=s5cmd" s3://bucket/prefix/flowers.png
+	06. Compress stdin with gzip and stream it to an object with the .gz suffix
+		 > cat access.log | s5cmd {{.HelpName}} --compress gzip --compress-suffix s3://bucket/logs/access.log
 `
 
 func NewPipeCommandFlags() []cli.Flag {
@@ -59,6 +65,10 @@ func NewPipeCommandFlags() []cli.Flag {
 			Name:  "metadata",
 			Usage: "set arbitrary metadata for the object",
 		},
+		&MapFlag{
+			Name:  "tag",
+			Usage: "set tags for the object, e.g. --tag 'project=s5cmd' --tag 'retention=30d'",
+		},
 		&cli.StringFlag{
 			Name:  "sse",
 			Usage: "perform server side encryption of the data at its destination, e.g. aws:kms",
@@ -101,7 +111,9 @@ func NewPipeCommandFlags() []cli.Flag {
 			Usage:   "do not overwrite destination if already exists",
 		},
 	}
-	return pipeFlags
+	pipeFlags = append(pipeFlags, NewSSECustomerKeyFlags(false)...)
+	pipeFlags = append(pipeFlags, NewCompressFlags()...)
+	return append(pipeFlags, NewClientEncryptionFlags()...)
 }
 
 func NewPipeCommand() *cli.Command {
@@ -153,6 +165,16 @@ type Pipe struct {
 	contentEncoding    string
 	contentDisposition string
 	metadata           map[string]string
+	tags               map[string]string
+	sseCustomerKey     string
+
+	// cseKey is the key of client-side encryption.
+	cseKey *encryption.Key
+
+	// compression of the object, and whether its suffix is appended to the
+	// key of the object.
+	compression    *compression
+	compressSuffix bool
 
 	// s3 options
 	concurrency int
@@ -177,6 +199,31 @@ func NewPipe(c *cli.Context, deleteSource bool) (*Pipe, error) {
 		return nil, err
 	}
 
+	tags, ok := c.Value("tag").(MapValue)
+	if !ok {
+		err := errors.New("tag flag is not a map")
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	sseCustomerKey, err := loadSSECustomerKey(c, "sse-c-key")
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	cseKey, err := clientEncryptionKey(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	compression, err := lookupCompression(c.String("compress"))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
 	return &Pipe{
 		dst:          dst,
 		op:           c.Command.Name,
@@ -196,6 +243,11 @@ func NewPipe(c *cli.Context, deleteSource bool) (*Pipe, error) {
 		contentEncoding:    c.String("content-encoding"),
 		contentDisposition: c.String("content-disposition"),
 		metadata:           metadata,
+		tags:               tags,
+		sseCustomerKey:     sseCustomerKey,
+		cseKey:             cseKey,
+		compression:        compression,
+		compressSuffix:     c.Bool("compress-suffix"),
 		// s3 options
 		storageOpts: NewStorageOpts(c),
 	}, nil
@@ -207,16 +259,25 @@ func (c Pipe) Run(ctx context.Context) error {
 		return fmt.Errorf("target %q must be an object", c.dst)
 	}
 
-	err := c.shouldOverride(ctx, c.dst)
+	dsturl := c.dst
+	if c.compression != nil && c.compressSuffix {
+		var err error
+		dsturl, err = withCompressionSuffix(c.compression, c.dst)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.shouldOverride(ctx, dsturl)
 	if err != nil {
 		if errorpkg.IsWarning(err) {
-			printDebug(c.op, err, nil, c.dst)
+			printDebug(c.op, err, nil, dsturl)
 			return nil
 		}
 		return err
 	}
 
-	client, err := storage.NewRemoteClient(ctx, c.dst, c.storageOpts)
+	client, err := storage.NewRemoteStorage(ctx, dsturl, c.storageOpts)
 	if err != nil {
 		return err
 	}
@@ -231,15 +292,33 @@ func (c Pipe) Run(ctx context.Context) error {
 		ContentDisposition: c.contentDisposition,
 		EncryptionMethod:   c.encryptionMethod,
 		EncryptionKeyID:    c.encryptionKeyID,
+		SSECustomerKey:     c.sseCustomerKey,
+		Tags:               c.tags,
 	}
 
+	// the content type is of the uncompressed content.
 	if c.contentType != "" {
 		metadata.ContentType = c.contentType
 	} else {
 		metadata.ContentType = guessContentTypeByExtension(c.dst)
 	}
 
-	err = client.Put(ctx, &stdin{file: os.Stdin}, c.dst, metadata, c.concurrency, c.partSize)
+	var body io.Reader = &stdin{file: os.Stdin}
+	if c.compression != nil {
+		compressed := compressReader(c.compression, body)
+		defer compressed.Close()
+
+		body = compressed
+		metadata.ContentEncoding = c.compression.encoding
+	}
+
+	reader, userDefined, err := encryptReader(c.cseKey, body, metadata.UserDefined)
+	if err != nil {
+		return err
+	}
+	metadata.UserDefined = userDefined
+
+	err = client.Put(ctx, reader, dsturl, metadata, c.concurrency, c.partSize)
 	if err != nil {
 		return err
 	}
@@ -247,7 +326,7 @@ func (c Pipe) Run(ctx context.Context) error {
 	msg := log.InfoMessage{
 		Operation:   c.op,
 		Source:      nil,
-		Destination: c.dst,
+		Destination: dsturl,
 		Object: &storage.Object{
 			StorageClass: c.storageClass,
 		},
@@ -313,7 +392,15 @@ func validatePipeCommand(c *cli.Context) error {
 		return fmt.Errorf("target %q can not contain glob characters", dst)
 	}
 
-	return nil
+	if err := validateSSECustomerKey(c); err != nil {
+		return err
+	}
+
+	if err := validateCompression(c); err != nil {
+		return err
+	}
+
+	return validateClientEncryption(c)
 }
 
 func guessContentTypeByExtension(dsturl *url.URL) string {
@@ -44,6 +44,14 @@ func NewPresignCommand() *cli.Command {
 				Name:  "version-id",
 				Usage: "use the specified version of an object",
 			},
+			&cli.StringFlag{
+				Name:  "sse-c-key",
+				Usage: "customer provided 256-bit key (raw, hex or base64) of the object encrypted on the server side, which must be sent with the presigned request",
+			},
+			&cli.StringFlag{
+				Name:  "sse-c-key-file",
+				Usage: "read the customer provided key of server side encryption from the given file",
+			},
 		},
 		CustomHelpTemplate: presignHelpTemplate,
 		Before: func(c *cli.Context) error {
@@ -130,5 +138,5 @@ func validatePresignCommand(c *cli.Context) error {
 		return err
 	}
 
-	return nil
+	return validateSSECustomerKey(c)
 }
@@ -0,0 +1,185 @@
+package command
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/peak/s5cmd/v2/storage"
+)
+
+// renameVariables are the variables of the rename templates. The argument
+// of a variable is given after a colon, e.g. {mtime:2006/01/02}.
+var renameVariables = map[string]func(name, arg string, object *storage.Object) (string, error){
+	"path": func(name, _ string, _ *storage.Object) (string, error) {
+		return name, nil
+	},
+	"dir": func(name, _ string, _ *storage.Object) (string, error) {
+		dir := path.Dir(name)
+		if dir == "." {
+			return "", nil
+		}
+		return dir, nil
+	},
+	"basename": func(name, _ string, _ *storage.Object) (string, error) {
+		return path.Base(name), nil
+	},
+	"stem": func(name, _ string, _ *storage.Object) (string, error) {
+		base := path.Base(name)
+		return strings.TrimSuffix(base, path.Ext(base)), nil
+	},
+	"ext": func(name, _ string, _ *storage.Object) (string, error) {
+		return path.Ext(name), nil
+	},
+	"mtime": func(_, arg string, object *storage.Object) (string, error) {
+		if object.ModTime == nil {
+			return "", fmt.Errorf("modification time of %q is not known", object.URL)
+		}
+		if arg == "" {
+			arg = "2006-01-02"
+		}
+		return object.ModTime.UTC().Format(arg), nil
+	},
+	"etag": func(_, _ string, object *storage.Object) (string, error) {
+		if object.Etag == "" {
+			return "", fmt.Errorf("etag of %q is not known", object.URL)
+		}
+		return strings.Trim(object.Etag, `"`), nil
+	},
+}
+
+// renameVariableRegex matches the variables of the rename templates.
+var renameVariableRegex = regexp.MustCompile(`\{([a-z]+)(?::([^}]*))?\}`)
+
+// templateSegment is either a literal text, which may refer to the capture
+// groups of the rename pattern, or a variable of a rename template.
+type templateSegment struct {
+	literal  string
+	variable string
+	arg      string
+}
+
+// renamer rewrites the names of the source objects under the destination.
+type renamer struct {
+	// regex is nil if the template is applied to the whole name.
+	regex    *regexp.Regexp
+	template []templateSegment
+}
+
+// newRenamer parses the value of the rename flag, which is either a regular
+// expression and a replacement template separated by "->", e.g.
+// '(\d{4})-(\d{2})-(.*) -> $1/$2/$3', or a single template which replaces
+// the whole name, e.g. '{mtime:2006/01/02}/{basename}'. It returns nil if
+// the value is empty.
+func newRenamer(value string) (*renamer, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var r renamer
+
+	template := value
+	if pattern, replacement, ok := strings.Cut(value, "->"); ok {
+		pattern, template = strings.TrimSpace(pattern), strings.TrimSpace(replacement)
+
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rename pattern %q: %w", pattern, err)
+		}
+		r.regex = regex
+	}
+
+	if template == "" {
+		return nil, fmt.Errorf("rename template can not be empty")
+	}
+
+	last := 0
+	for _, loc := range renameVariableRegex.FindAllStringSubmatchIndex(template, -1) {
+		name := template[loc[2]:loc[3]]
+		if _, ok := renameVariables[name]; !ok {
+			return nil, fmt.Errorf("unknown rename variable %q", template[loc[0]:loc[1]])
+		}
+
+		var arg string
+		if loc[4] >= 0 {
+			arg = template[loc[4]:loc[5]]
+		}
+
+		r.template = append(r.template,
+			templateSegment{literal: template[last:loc[0]]},
+			templateSegment{variable: name, arg: arg},
+		)
+		last = loc[1]
+	}
+	r.template = append(r.template, templateSegment{literal: template[last:]})
+
+	return &r, nil
+}
+
+// rename returns the new name of the object whose name under the destination
+// is the given slash separated name. The name is not changed if the pattern
+// does not match it. Every match of the pattern is replaced by the template.
+// The new name is cleaned, so that it can not refer to the parents of the
+// destination.
+func (r *renamer) rename(name string, object *storage.Object) (string, error) {
+	var newname string
+	if r.regex == nil {
+		var err error
+		newname, err = r.expand(name, nil, object)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		matches := r.regex.FindAllStringSubmatchIndex(name, -1)
+		if len(matches) == 0 {
+			return name, nil
+		}
+
+		var (
+			sb   strings.Builder
+			last int
+		)
+		for _, match := range matches {
+			replacement, err := r.expand(name, match, object)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(name[last:match[0]])
+			sb.WriteString(replacement)
+			last = match[1]
+		}
+		sb.WriteString(name[last:])
+		newname = sb.String()
+	}
+
+	newname = strings.TrimPrefix(path.Clean("/"+newname), "/")
+	if newname == "" {
+		return "", fmt.Errorf("%q is renamed to an empty name", name)
+	}
+	return newname, nil
+}
+
+// expand expands the template for the given match of the pattern. The
+// values of the variables are not expanded, so that they can contain "$".
+func (r *renamer) expand(name string, match []int, object *storage.Object) (string, error) {
+	var sb strings.Builder
+	for _, segment := range r.template {
+		if segment.variable == "" {
+			if r.regex == nil {
+				sb.WriteString(segment.literal)
+			} else {
+				sb.Write(r.regex.ExpandString(nil, segment.literal, name, match))
+			}
+			continue
+		}
+
+		value, err := renameVariables[segment.variable](name, segment.arg, object)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(value)
+	}
+
+	return sb.String(), nil
+}
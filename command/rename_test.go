@@ -0,0 +1,130 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestRenamerRename(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.New("s3://bucket/2024-05-report.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 5, 17, 23, 0, 0, 0, time.FixedZone("", -3*60*60))
+	object := &storage.Object{URL: u, ModTime: &modTime, Etag: `"abc123"`}
+
+	testcases := []struct {
+		name     string
+		value    string
+		objname  string
+		expected string
+	}{
+		{
+			name:     "capture groups",
+			value:    `(\d{4})-(\d{2})-(.*) -> $1/$2/$3`,
+			objname:  "2024-05-report.csv",
+			expected: "2024/05/report.csv",
+		},
+		{
+			name:     "partial match",
+			value:    `(\d{4})-(\d{2})-->${1}/${2}/`,
+			objname:  "logs/2024-05-report.csv",
+			expected: "logs/2024/05/report.csv",
+		},
+		{
+			name:     "no match",
+			value:    `^archive/(.*) -> $1`,
+			objname:  "2024-05-report.csv",
+			expected: "2024-05-report.csv",
+		},
+		{
+			name:     "every match",
+			value:    `_ -> -`,
+			objname:  "a_b_c.txt",
+			expected: "a-b-c.txt",
+		},
+		{
+			name:     "template",
+			value:    `{mtime:2006/01/02}/{stem}-{etag}{ext}`,
+			objname:  "dir/2024-05-report.csv",
+			expected: "2024/05/18/2024-05-report-abc123.csv",
+		},
+		{
+			name:     "default time layout",
+			value:    `{mtime}/{basename}`,
+			objname:  "dir/report.csv",
+			expected: "2024-05-18/report.csv",
+		},
+		{
+			name:     "pattern and template",
+			value:    `^(.*)\.csv$ -> {dir}/csv/$1.csv`,
+			objname:  "dir/report.csv",
+			expected: "dir/csv/dir/report.csv",
+		},
+		{
+			name:     "parent directories",
+			value:    `../../{path}`,
+			objname:  "dir/report.csv",
+			expected: "dir/report.csv",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newRenamer(tc.value)
+			assert.NilError(t, err)
+
+			got, err := r.rename(tc.objname, object)
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.expected)
+		})
+	}
+}
+
+func TestNewRenamerErrors(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		value    string
+		expected string
+	}{
+		{value: `( -> $1`, expected: `invalid rename pattern "("`},
+		{value: `(.*) -> `, expected: "rename template can not be empty"},
+		{value: `{size}/{basename}`, expected: `unknown rename variable "{size}"`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newRenamer(tc.value)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRenamerRenameUnknownValues(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.New("dir/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := newRenamer("{etag}")
+	assert.NilError(t, err)
+
+	_, err = r.rename("file.txt", &storage.Object{URL: u})
+	assert.ErrorContains(t, err, `etag of "dir/file.txt" is not known`)
+}
@@ -0,0 +1,484 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+const (
+	defaultRestoreDays = 1
+	defaultRestoreTier = "Standard"
+)
+
+// restorePollInterval is the interval of the requests to check whether the
+// restores of the objects are completed.
+var restorePollInterval = time.Minute
+
+var restoreHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Restore an archived object for a day
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Restore all archived objects with a prefix for a week with bulk retrieval
+		 > s5cmd {{.HelpName}} --days 7 --tier Bulk "s3://bucket/prefix/*"
+
+	3. Restore all archived objects that matches a wildcard and wait until they are restored
+		 > s5cmd {{.HelpName}} --wait "s3://bucket/*/obj*.gz"
+
+	4. Restore all archived objects with a prefix and wait at most 12 hours until they are restored
+		 > s5cmd {{.HelpName}} --wait --timeout 12h "s3://bucket/prefix/*"
+
+	5. Restore the specific version of an archived object
+		 > s5cmd {{.HelpName}} --version-id VERSION_ID s3://bucket/prefix/object.gz
+`
+
+// NewRestoreFlags returns the flags of the restores of archived objects.
+func NewRestoreFlags(prefix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name:  prefix + "days",
+			Value: defaultRestoreDays,
+			Usage: "number of days to keep the restored copies of the objects",
+		},
+		&cli.StringFlag{
+			Name:  prefix + "tier",
+			Value: defaultRestoreTier,
+			Usage: "retrieval tier of the restores ('Standard','Bulk','Expedited')",
+		},
+		&cli.GenericFlag{
+			Name:  prefix + "timeout",
+			Value: &DurationValue{},
+			Usage: "fail the objects which are not restored in the given duration, e.g. 12h or 2d (default: no limit)",
+		},
+	}
+}
+
+// restoreTimeout returns the duration of the timeout flag with the given
+// prefix.
+func restoreTimeout(c *cli.Context, prefix string) time.Duration {
+	if d, ok := c.Generic(prefix + "timeout").(*DurationValue); ok {
+		return d.Duration
+	}
+	return 0
+}
+
+func NewRestoreCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "restore",
+		HelpName: "restore",
+		Usage:    "restore archived objects",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "raw",
+				Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
+			},
+			&cli.StringFlag{
+				Name:  "version-id",
+				Usage: "use the specified version of an object",
+			},
+			&cli.BoolFlag{
+				Name:  "wait",
+				Usage: "wait until the restores of the objects are completed",
+			},
+		}, NewRestoreFlags("")...),
+		CustomHelpTemplate: restoreHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateRestoreCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			fullCommand := commandFromContext(c)
+
+			src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+				url.WithRaw(c.Bool("raw")))
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			return Restore{
+				src:         src,
+				op:          c.Command.Name,
+				fullCommand: fullCommand,
+				days:        c.Int64("days"),
+				tier:        c.String("tier"),
+				wait:        c.Bool("wait"),
+				timeout:     restoreTimeout(c, ""),
+				storageOpts: NewStorageOpts(c),
+			}.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// Restore holds restore operation flags and states.
+type Restore struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	days    int64
+	tier    string
+	wait    bool
+	timeout time.Duration
+
+	storageOpts storage.Options
+}
+
+// Run restores the archived source objects.
+func (r Restore) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, r.src, r.storageOpts)
+	if err != nil {
+		printError(r.fullCommand, r.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, r.src)
+	if err != nil {
+		printError(r.fullCommand, r.op, err)
+		return err
+	}
+
+	var (
+		merrorWaiter  error
+		merrorObjects error
+	)
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(r.fullCommand, r.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	// the objects are waited outside of the worker pool.
+	var restores *restoreWaiter
+	if r.wait && !r.storageOpts.DryRun {
+		restores = newRestoreWaiter(ctx, r.op, r.timeout, waiter)
+	}
+
+	for object := range objch {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(r.fullCommand, r.op, err)
+			continue
+		}
+
+		// the storage class of the objects is only known if they are
+		// listed.
+		if object.StorageClass != "" && !object.StorageClass.IsArchived() {
+			continue
+		}
+
+		if restores != nil {
+			restores.expect()
+		}
+		parallel.Run(r.prepareTask(ctx, client, object.URL, restores), waiter)
+	}
+
+	if restores != nil {
+		restores.Wait()
+	}
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
+}
+
+func (r Restore) prepareTask(
+	ctx context.Context,
+	client *storage.S3,
+	srcurl *url.URL,
+	restores *restoreWaiter,
+) func() error {
+	return func() error {
+		logRestore := func() error {
+			log.Info(log.InfoMessage{
+				Operation: r.op,
+				Source:    srcurl,
+			})
+			return nil
+		}
+
+		err := client.Restore(ctx, srcurl, r.days, r.tier)
+		if err == nil && restores != nil {
+			restores.add(client, srcurl, nil, logRestore)
+			return nil
+		}
+		if restores != nil {
+			restores.done()
+		}
+
+		if err != nil {
+			return &errorpkg.Error{
+				Op:  r.op,
+				Src: srcurl,
+				Err: err,
+			}
+		}
+		return logRestore()
+	}
+}
+
+// restoreStatter reads the restore status of the archived objects.
+type restoreStatter interface {
+	Stat(ctx context.Context, u *url.URL) (*storage.Object, error)
+}
+
+// isRestored reports whether the restore of the archived object is completed.
+func isRestored(ctx context.Context, client restoreStatter, srcurl *url.URL) (bool, error) {
+	obj, err := client.Stat(ctx, srcurl)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case obj.Restore == storage.RestoreCompleted, !obj.StorageClass.IsArchived():
+		return true, nil
+	case obj.Restore != storage.RestoreOngoing:
+		return false, fmt.Errorf("object '%v' is not being restored", srcurl)
+	}
+	return false, nil
+}
+
+// restoreIfNeeded restores the object if it is archived. It reports whether
+// the object is being restored.
+func restoreIfNeeded(ctx context.Context, client *storage.S3, srcurl *url.URL, days int64, tier string) (bool, error) {
+	obj, err := client.Stat(ctx, srcurl)
+	if err != nil {
+		return false, err
+	}
+
+	if !obj.StorageClass.IsArchived() || obj.Restore == storage.RestoreCompleted {
+		return false, nil
+	}
+
+	if obj.Restore != storage.RestoreOngoing {
+		if err := client.Restore(ctx, srcurl, days, tier); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// pendingRestore is an object waiting to be restored.
+type pendingRestore struct {
+	client restoreStatter
+	src    *url.URL
+	dst    *url.URL
+	// task is run once the object is restored.
+	task parallel.Task
+	// deadline is the time the restore fails if it is not completed, it is
+	// zero if there is no limit.
+	deadline time.Time
+}
+
+// restoreWaiter polls the objects which are being restored outside of the
+// worker pool, so that restores which can take hours do not occupy the
+// workers. The task of each object is run in the worker pool once it is
+// restored.
+type restoreWaiter struct {
+	op      string
+	timeout time.Duration
+	waiter  *parallel.Waiter
+
+	mu      sync.Mutex
+	pending []*pendingRestore
+
+	// wg counts the objects which are expected to be added to the waiter,
+	// until their tasks are scheduled.
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// newRestoreWaiter creates a restoreWaiter which schedules the tasks of the
+// restored objects to the given waiter, and starts polling.
+func newRestoreWaiter(ctx context.Context, op string, timeout time.Duration, waiter *parallel.Waiter) *restoreWaiter {
+	w := &restoreWaiter{
+		op:      op,
+		timeout: timeout,
+		waiter:  waiter,
+		stop:    make(chan struct{}),
+	}
+	go w.poll(ctx)
+	return w
+}
+
+// expect declares an object which may be added to the waiter. Each expected
+// object must be either added, or marked as done.
+func (w *restoreWaiter) expect() {
+	w.wg.Add(1)
+}
+
+// done marks an expected object which is not added to the waiter.
+func (w *restoreWaiter) done() {
+	w.wg.Done()
+}
+
+// add adds an expected object which is being restored. Its task is run once
+// it is restored.
+func (w *restoreWaiter) add(client restoreStatter, src, dst *url.URL, task parallel.Task) {
+	p := &pendingRestore{
+		client: client,
+		src:    src,
+		dst:    dst,
+		task:   task,
+	}
+	if w.timeout > 0 {
+		p.deadline = time.Now().Add(w.timeout)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p)
+}
+
+// Wait waits until the tasks of all expected objects are scheduled, and stops
+// polling.
+func (w *restoreWaiter) Wait() {
+	w.wg.Wait()
+	close(w.stop)
+}
+
+func (w *restoreWaiter) poll(ctx context.Context) {
+	ticker := time.NewTicker(restorePollInterval)
+	defer ticker.Stop()
+
+	ctxDone := ctx.Done()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ctxDone:
+			// the pending objects are failed below, the ones added
+			// afterwards are failed on the next tick.
+			ctxDone = nil
+		case <-ticker.C:
+		}
+		w.check(ctx)
+	}
+}
+
+// check schedules the tasks of the restored objects, and fails the objects
+// which can not be restored.
+func (w *restoreWaiter) check(ctx context.Context) {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	var waiting []*pendingRestore
+	for _, p := range pending {
+		err := ctx.Err()
+		restored := false
+		if err == nil {
+			restored, err = isRestored(ctx, p.client, p.src)
+		}
+		if err == nil && !restored && !p.deadline.IsZero() && time.Now().After(p.deadline) {
+			err = fmt.Errorf("object '%v' is not restored in %v", p.src, w.timeout)
+		}
+
+		switch {
+		case err != nil:
+			w.schedule(p, func() error {
+				return &errorpkg.Error{
+					Op:  w.op,
+					Src: p.src,
+					Dst: p.dst,
+					Err: err,
+				}
+			})
+		case restored:
+			w.schedule(p, p.task)
+		default:
+			waiting = append(waiting, p)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, waiting...)
+}
+
+// schedule runs the task of the object in the worker pool.
+func (w *restoreWaiter) schedule(p *pendingRestore, task parallel.Task) {
+	parallel.Run(task, w.waiter)
+	w.wg.Done()
+}
+
+func validateRestoreTier(tier string) error {
+	switch tier {
+	case "Standard", "Bulk", "Expedited":
+		return nil
+	}
+	return fmt.Errorf("invalid retrieval tier %q: must be one of 'Standard', 'Bulk' or 'Expedited'", tier)
+}
+
+func validateRestoreCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote object url")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+		url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() || src.IsPrefix() {
+		return fmt.Errorf("remote source must be an object")
+	}
+
+	if c.Int64("days") < 1 {
+		return fmt.Errorf("days must be a positive number")
+	}
+
+	if err := validateRestoreTier(c.String("tier")); err != nil {
+		return err
+	}
+
+	if c.IsSet("timeout") && !c.Bool("wait") {
+		return fmt.Errorf(`"timeout" flag requires "wait" flag`)
+	}
+
+	return checkVersioningWithGoogleEndpoint(c)
+}
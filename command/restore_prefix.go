@@ -0,0 +1,370 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+var restorePrefixHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Restore the objects with a prefix to their versions at a point in time
+		 > s5cmd {{.HelpName}} --at 2026-09-01T00:00:00Z "s3://bucket/prefix/*"
+
+	2. Restore the objects with a prefix to their versions at a point in time, and delete the objects which did not exist at that time
+		 > s5cmd {{.HelpName}} --at 2026-09-01T00:00:00Z --delete "s3://bucket/prefix/*"
+
+	3. Copy the versions of the objects with a prefix at a point in time to another bucket
+		 > s5cmd {{.HelpName}} --at 2026-09-01T00:00:00Z --to s3://other/prefix/ "s3://bucket/prefix/*"
+
+	4. Print the plan of a restore without changing any objects
+		 > s5cmd --dry-run {{.HelpName}} --at 2026-09-01T00:00:00Z --delete "s3://bucket/prefix/*"
+`
+
+func NewRestorePrefixCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "restore-prefix",
+		HelpName: "restore-prefix",
+		Usage:    "restore objects to their versions at a point in time",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "at",
+				Usage: "the point in time to restore the objects to (uses RFC3339 format), e.g. --at 2026-09-01T00:00:00Z",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "copy the versions of the objects under the given remote prefix instead of restoring them in place",
+			},
+			&cli.BoolFlag{
+				Name:  "delete",
+				Usage: "delete the objects which did not exist at the given point in time",
+			},
+			&cli.IntFlag{
+				Name:    "concurrency",
+				Aliases: []string{"c"},
+				Value:   defaultCopyConcurrency,
+				Usage:   "number of concurrent parts copied for the objects larger than 5GiB",
+			},
+			&cli.IntFlag{
+				Name:    "part-size",
+				Aliases: []string{"p"},
+				Value:   defaultPartSize,
+				Usage:   "size of each part copied for the objects larger than 5GiB, in MiB",
+			},
+		},
+		CustomHelpTemplate: restorePrefixHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateRestorePrefixCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			restorePrefix, err := NewRestorePrefix(c)
+			if err != nil {
+				return err
+			}
+			return restorePrefix.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// RestorePrefix holds restore-prefix operation flags and states.
+type RestorePrefix struct {
+	src         *url.URL
+	dst         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	at          time.Time
+	delete      bool
+	concurrency int
+	partSize    int64
+
+	storageOpts storage.Options
+}
+
+// NewRestorePrefix creates RestorePrefix from cli.Context.
+func NewRestorePrefix(c *cli.Context) (*RestorePrefix, error) {
+	fullCommand := commandFromContext(c)
+
+	src, err := url.New(c.Args().Get(0), url.WithAllVersions(true))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	// the time is validated before.
+	at, _ := time.Parse(time.RFC3339, c.String("at"))
+
+	var dst *url.URL
+	if to := c.String("to"); to != "" {
+		dst, err = url.New(to)
+		if err != nil {
+			printError(fullCommand, c.Command.Name, err)
+			return nil, err
+		}
+	}
+
+	return &RestorePrefix{
+		src:         src,
+		dst:         dst,
+		op:          c.Command.Name,
+		fullCommand: fullCommand,
+
+		at:          at,
+		delete:      c.Bool("delete"),
+		concurrency: c.Int("concurrency"),
+		partSize:    c.Int64("part-size") * megabytes,
+
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// versionsAt holds the versions of an object which are selected to restore
+// it to a point in time.
+type versionsAt struct {
+	// latest is the current version of the object.
+	latest *storage.Object
+	// target is the newest version of the object at the point in time. It
+	// is nil if the object did not exist at that time.
+	target *storage.Object
+}
+
+// Run restores the source objects to their versions at the given point in
+// time.
+func (r RestorePrefix) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, r.src, r.storageOpts)
+	if err != nil {
+		printError(r.fullCommand, r.op, err)
+		return err
+	}
+
+	// all versions of an object must be seen to select the version to
+	// restore, hence the versions are collected before any object is
+	// restored.
+	objects := map[string]*versionsAt{}
+	var merrorObjects error
+	for object := range client.List(ctx, r.src, false) {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(r.fullCommand, r.op, err)
+			continue
+		}
+
+		versions, ok := objects[object.URL.Path]
+		if !ok {
+			versions = &versionsAt{}
+			objects[object.URL.Path] = versions
+		}
+		versions.add(object, r.at)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var merrorWaiter error
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(r.fullCommand, r.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	for _, key := range keys {
+		if task := r.prepareTask(ctx, client, objects[key]); task != nil {
+			parallel.Run(task, waiter)
+		}
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
+}
+
+// add adds the version of the object if it is the latest version, or the
+// newest version at the given point in time seen so far.
+func (v *versionsAt) add(object *storage.Object, at time.Time) {
+	if v.latest == nil || object.ModTime.After(*v.latest.ModTime) {
+		v.latest = object
+	}
+
+	if object.ModTime.After(at) {
+		return
+	}
+	if v.target == nil || object.ModTime.After(*v.target.ModTime) {
+		v.target = object
+	}
+}
+
+// exists reports whether the object existed at the point in time.
+func (v *versionsAt) exists() bool {
+	return v.target != nil && !v.target.DeleteMarker
+}
+
+func (r RestorePrefix) prepareTask(ctx context.Context, client *storage.S3, versions *versionsAt) func() error {
+	dsturl := r.destination(versions.latest.URL)
+
+	if !versions.exists() {
+		// the object is already deleted in place.
+		if !r.delete || (r.dst == nil && versions.latest.DeleteMarker) {
+			return nil
+		}
+
+		return func() error {
+			if err := client.Delete(ctx, dsturl); err != nil {
+				return &errorpkg.Error{
+					Op:  "rm",
+					Src: dsturl,
+					Err: err,
+				}
+			}
+
+			log.Info(log.InfoMessage{
+				Operation: "rm",
+				Source:    dsturl,
+			})
+			return nil
+		}
+	}
+
+	// the object is already at its version at the point in time.
+	if r.dst == nil && versions.target == versions.latest {
+		return nil
+	}
+
+	srcurl := versions.target.URL
+	metadata := storage.Metadata{SourceSize: versions.target.Size}
+	return func() error {
+		err := client.Copy(ctx, srcurl, dsturl, metadata, r.concurrency, r.partSize)
+		if err != nil {
+			return &errorpkg.Error{
+				Op:  r.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+
+		log.Info(RestorePrefixMessage{
+			Operation:   r.op,
+			Source:      srcurl,
+			VersionID:   srcurl.VersionID,
+			Destination: dsturl,
+		})
+		return nil
+	}
+}
+
+// destination returns the url that the version of the object is restored to.
+func (r RestorePrefix) destination(objurl *url.URL) *url.URL {
+	dsturl := objurl.Clone()
+	dsturl.VersionID = ""
+	if r.dst == nil {
+		return dsturl
+	}
+
+	return r.dst.Join(objurl.Relative())
+}
+
+func validateRestorePrefixCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote prefix")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithAllVersions(true))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote prefix")
+	}
+
+	if !c.IsSet("at") {
+		return fmt.Errorf(`"at" flag is required`)
+	}
+
+	if _, err := time.Parse(time.RFC3339, c.String("at")); err != nil {
+		return fmt.Errorf("invalid time %q: must be in RFC3339 format", c.String("at"))
+	}
+
+	if to := c.String("to"); to != "" {
+		dst, err := url.New(to)
+		if err != nil {
+			return err
+		}
+
+		if !dst.IsRemote() {
+			return fmt.Errorf("destination must be a remote prefix")
+		}
+
+		if dst.IsWildcard() {
+			return fmt.Errorf("destination %q can not contain glob characters", dst)
+		}
+
+		if !dst.IsBucket() && !dst.IsPrefix() {
+			return fmt.Errorf("destination must be a bucket or a prefix")
+		}
+	}
+
+	return checkVersioningWithGoogleEndpoint(c)
+}
+
+// RestorePrefixMessage is a structure for logging the restores of objects to
+// their versions at a point in time.
+type RestorePrefixMessage struct {
+	Operation   string   `json:"operation"`
+	Source      *url.URL `json:"source"`
+	VersionID   string   `json:"version_id"`
+	Destination *url.URL `json:"destination"`
+}
+
+// String returns the string representation of RestorePrefixMessage.
+func (m RestorePrefixMessage) String() string {
+	return fmt.Sprintf("%v %v %v %v", m.Operation, m.Source, m.VersionID, m.Destination)
+}
+
+// JSON returns the JSON representation of RestorePrefixMessage.
+func (m RestorePrefixMessage) JSON() string {
+	return strutil.JSON(m)
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestVersionsAt(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	version := func(id string, modTime time.Time, deleteMarker bool) *storage.Object {
+		u, err := url.New("s3://bucket/key", url.WithVersion(id))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return &storage.Object{URL: u, ModTime: &modTime, DeleteMarker: deleteMarker}
+	}
+
+	testcases := []struct {
+		name           string
+		versions       []*storage.Object
+		expectedLatest string
+		expectedTarget string
+		expectedExists bool
+	}{
+		{
+			name: "overwritten after the time",
+			versions: []*storage.Object{
+				version("3", at.Add(time.Hour), false),
+				version("2", at.Add(-time.Hour), false),
+				version("1", at.Add(-2*time.Hour), false),
+			},
+			expectedLatest: "3",
+			expectedTarget: "2",
+			expectedExists: true,
+		},
+		{
+			name: "version at the time",
+			versions: []*storage.Object{
+				version("2", at, false),
+				version("1", at.Add(-time.Hour), false),
+			},
+			expectedLatest: "2",
+			expectedTarget: "2",
+			expectedExists: true,
+		},
+		{
+			name: "deleted after the time",
+			versions: []*storage.Object{
+				version("1", at.Add(-time.Hour), false),
+				version("2", at.Add(time.Hour), true),
+			},
+			expectedLatest: "2",
+			expectedTarget: "1",
+			expectedExists: true,
+		},
+		{
+			name: "deleted before the time",
+			versions: []*storage.Object{
+				version("1", at.Add(-2*time.Hour), false),
+				version("2", at.Add(-time.Hour), true),
+				version("3", at.Add(time.Hour), false),
+			},
+			expectedLatest: "3",
+			expectedTarget: "2",
+			expectedExists: false,
+		},
+		{
+			name: "created after the time",
+			versions: []*storage.Object{
+				version("1", at.Add(time.Hour), false),
+			},
+			expectedLatest: "1",
+			expectedExists: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			versions := &versionsAt{}
+			for _, v := range tc.versions {
+				versions.add(v, at)
+			}
+
+			assert.Equal(t, versions.latest.URL.VersionID, tc.expectedLatest)
+			if tc.expectedTarget == "" {
+				assert.Assert(t, versions.target == nil)
+			} else {
+				assert.Equal(t, versions.target.URL.VersionID, tc.expectedTarget)
+			}
+			assert.Equal(t, versions.exists(), tc.expectedExists)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// restoreStatterFunc returns the restore status of the objects.
+type restoreStatterFunc func(u *url.URL) (*storage.Object, error)
+
+func (f restoreStatterFunc) Stat(_ context.Context, u *url.URL) (*storage.Object, error) {
+	return f(u)
+}
+
+func TestRestoreWaiter(t *testing.T) {
+	parallel.Init(2)
+	defer parallel.Close()
+
+	defer func(interval time.Duration) { restorePollInterval = interval }(restorePollInterval)
+	restorePollInterval = 10 * time.Millisecond
+
+	var (
+		mu    sync.Mutex
+		polls = map[string]int{}
+		ran   []string
+	)
+
+	// "restored" is restored on its second poll, "archived" is never
+	// restored, and "failed" is not being restored.
+	client := restoreStatterFunc(func(u *url.URL) (*storage.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		polls[u.Path]++
+		obj := &storage.Object{URL: u, StorageClass: "GLACIER", Restore: storage.RestoreOngoing}
+		switch {
+		case u.Path == "restored" && polls[u.Path] > 1:
+			obj.Restore = storage.RestoreCompleted
+		case u.Path == "failed":
+			obj.Restore = ""
+		}
+		return obj, nil
+	})
+
+	waiter := parallel.NewWaiter()
+	var errs []error
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			errs = append(errs, err)
+		}
+	}()
+
+	w := newRestoreWaiter(context.Background(), "restore", 100*time.Millisecond, waiter)
+	for _, key := range []string{"restored", "archived", "failed", "skipped"} {
+		key := key
+		w.expect()
+		if key == "skipped" {
+			w.done()
+			continue
+		}
+
+		w.add(client, mustNewURL(t, "s3://bucket/"+key), nil, func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			ran = append(ran, key)
+			return nil
+		})
+	}
+
+	w.Wait()
+	waiter.Wait()
+	<-errDoneCh
+
+	assert.DeepEqual(t, ran, []string{"restored"})
+
+	var messages []string
+	for _, err := range errs {
+		var operr *errorpkg.Error
+		assert.Assert(t, errors.As(err, &operr))
+		messages = append(messages, operr.Err.Error())
+	}
+	sort.Strings(messages)
+	assert.DeepEqual(t, messages, []string{
+		"object 's3://bucket/archived' is not restored in 100ms",
+		"object 's3://bucket/failed' is not being restored",
+	})
+}
@@ -54,6 +54,12 @@ Examples:
    
 	10. Delete all versions of all objects in the bucket
 		 > s5cmd {{.HelpName}} --all-versions "s3://bucket/*"
+
+	11. Delete all matching objects which are older than 30 days
+		 > s5cmd {{.HelpName}} --older-than 30d "s3://bucket/logs/*"
+
+	12. Delete all matching objects except the ones whose keys match a regular expression
+		 > s5cmd {{.HelpName}} --exclude-regex '^logs/[0-9]{4}/' "s3://bucket/*"
 `
 
 func NewDeleteCommand() *cli.Command {
@@ -61,7 +67,7 @@ func NewDeleteCommand() *cli.Command {
 		Name:     "rm",
 		HelpName: "rm",
 		Usage:    "remove objects",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:  "raw",
 				Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
@@ -82,7 +88,7 @@ func NewDeleteCommand() *cli.Command {
 				Name:  "version-id",
 				Usage: "use the specified version of an object",
 			},
-		},
+		}, append(NewFilterFlags(), NewIgnoreFlags()...)...),
 		CustomHelpTemplate: deleteHelpTemplate,
 		Before: func(c *cli.Context) error {
 			err := validateRMCommand(c)
@@ -114,10 +120,23 @@ func NewDeleteCommand() *cli.Command {
 				return err
 			}
 
+			filter, err := newObjectFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			ignores, err := newIgnoreFilter(c)
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
 			return Delete{
 				src:         srcUrls,
 				op:          c.Command.Name,
 				fullCommand: fullCommand,
+				failedOps:   newFailedOps(c),
 
 				// flags
 				exclude: c.StringSlice("exclude"),
@@ -126,6 +145,8 @@ func NewDeleteCommand() *cli.Command {
 				// patterns
 				excludePatterns: excludePatterns,
 				includePatterns: includePatterns,
+				filter:          filter,
+				ignores:         ignores,
 
 				storageOpts: NewStorageOpts(c),
 			}.Run(c.Context)
@@ -149,9 +170,13 @@ type Delete struct {
 	// patterns
 	excludePatterns []*regexp.Regexp
 	includePatterns []*regexp.Regexp
+	filter          objectFilter
+	ignores         ignoreFilter
 
 	// storage options
 	storageOpts storage.Options
+
+	failedOps *failedOps
 }
 
 // Run remove given sources.
@@ -196,6 +221,14 @@ func (d Delete) Run(ctx context.Context) error {
 				continue
 			}
 
+			if d.ignores.isExcluded(object, srcurl.Prefix) {
+				continue
+			}
+
+			if !d.filter.isSelected(object) {
+				continue
+			}
+
 			urlch <- object.URL
 		}
 	}()
@@ -210,6 +243,14 @@ func (d Delete) Run(ctx context.Context) error {
 
 			merrorResult = multierror.Append(merrorResult, obj.Err)
 			printError(d.fullCommand, d.op, obj.Err)
+
+			// the objects of a failed batch are not known, in which case
+			// the command is retried as a whole.
+			if obj.URL != nil {
+				d.failedOps.recordOp(d.op, obj.URL)
+			} else {
+				d.failedOps.recordCommand()
+			}
 			continue
 		}
 
@@ -297,5 +338,13 @@ func validateRMCommand(c *cli.Context) error {
 		}
 	}
 
+	if _, err := newObjectFilter(c); err != nil {
+		return err
+	}
+
+	if _, err := newIgnoreFilter(c); err != nil {
+		return err
+	}
+
 	return nil
 }
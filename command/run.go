@@ -14,6 +14,7 @@ import (
 	"github.com/kballard/go-shellquote"
 	"github.com/urfave/cli/v2"
 
+	"github.com/peak/s5cmd/v2/log"
 	"github.com/peak/s5cmd/v2/parallel"
 )
 
@@ -32,13 +33,26 @@ Examples:
 
 	2. Read commands from standard input and execute in parallel.
 		 > cat commands.txt | s5cmd {{.HelpName}}
+
+	3. Run the commands declared in "commands.txt" file, and skip the ones completed in an earlier run
+		 > s5cmd {{.HelpName}} --checkpoint commands.checkpoint commands.txt
 `
 
+func NewRunCommandFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "checkpoint",
+			Usage: "record the successfully completed lines to the file, and skip them when the batch is run again",
+		},
+	}
+}
+
 func NewRunCommand() *cli.Command {
 	return &cli.Command{
 		Name:               "run",
 		HelpName:           "run",
 		Usage:              "run commands in batch",
+		Flags:              NewRunCommandFlags(),
 		CustomHelpTemplate: runHelpTemplate,
 		Before: func(c *cli.Context) error {
 			err := validateRunCommand(c)
@@ -60,7 +74,9 @@ func NewRunCommand() *cli.Command {
 				reader = f
 			}
 
-			return NewRun(c, reader).Run(c.Context)
+			run := NewRun(c, reader)
+			run.checkpoint = c.String("checkpoint")
+			return run.Run(c.Context)
 		},
 	}
 }
@@ -71,17 +87,31 @@ type Run struct {
 
 	// flags
 	numWorkers int
+	checkpoint string
+	dryRun     bool
 }
 
 func NewRun(c *cli.Context, r io.Reader) Run {
 	return Run{
 		c:          c,
 		reader:     r,
-		numWorkers: c.Int("numworkers"),
+		numWorkers: numWorkers(c),
+		dryRun:     c.Bool("dry-run"),
 	}
 }
 
 func (r Run) Run(ctx context.Context) error {
+	var checkpoint *runCheckpoint
+	if r.checkpoint != "" {
+		var err error
+		checkpoint, err = openRunCheckpoint(r.checkpoint)
+		if err != nil {
+			printError(commandFromContext(r.c), r.c.Command.Name, err)
+			return err
+		}
+		defer checkpoint.Close()
+	}
+
 	pm := parallel.New(r.numWorkers)
 	defer pm.Close()
 
@@ -127,6 +157,13 @@ func (r Run) Run(ctx context.Context) error {
 			continue
 		}
 
+		if checkpoint != nil && checkpoint.completed(lineno, line) {
+			msg := log.DebugMessage{Err: fmt.Sprintf("skipping line %v, it is completed in an earlier run", lineno)}
+			log.Debug(msg)
+			continue
+		}
+
+		lineno, line := lineno, line
 		fn := func() error {
 			subcmd := fields[0]
 
@@ -144,7 +181,19 @@ func (r Run) Run(ctx context.Context) error {
 			}
 
 			ctx := cli.NewContext(app, flagset, r.c)
-			return cmd.Run(ctx)
+			if err := cmd.Run(ctx); err != nil {
+				return err
+			}
+
+			// lines are recorded as they complete, so that the
+			// checkpoint is up to date even if the batch is
+			// interrupted.
+			if checkpoint != nil && !r.dryRun {
+				if err := checkpoint.record(lineno, line); err != nil {
+					printError(commandFromContext(r.c), r.c.Command.Name, err)
+				}
+			}
+			return nil
 		}
 
 		pm.Run(fn, waiter)
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// runCheckpoint records the lines of a run batch which are completed
+// successfully, so that they are skipped when the batch is run again. Lines
+// are appended to the checkpoint file as they complete, in any order, as
+// "<line number> <hash of the line>". The hash makes sure that a line is not
+// skipped if the batch is edited between runs. Incomplete records, such as
+// the last one written before a crash, are ignored.
+type runCheckpoint struct {
+	mu   sync.Mutex
+	file *os.File
+
+	// hashes are the hashes of the completed lines, indexed by their line
+	// numbers. Zero means that the line is not completed.
+	hashes []uint32
+}
+
+// openRunCheckpoint loads the checkpoint in the given path, and opens it to
+// record the lines completed from now on.
+func openRunCheckpoint(path string) (*runCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &runCheckpoint{file: file}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineno, hash, ok := parseRunCheckpointRecord(scanner.Text())
+		if ok {
+			checkpoint.set(lineno, hash)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// an incomplete record is terminated, so that the next one is written
+	// on its own line.
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if offset > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, offset-1); err != nil {
+			file.Close()
+			return nil, err
+		}
+		if last[0] != '\n' {
+			if _, err := file.Write([]byte("\n")); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return checkpoint, nil
+}
+
+// parseRunCheckpointRecord parses a record of the checkpoint file.
+func parseRunCheckpointRecord(record string) (int, uint32, bool) {
+	fields := strings.Fields(record)
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+
+	lineno, err := strconv.Atoi(fields[0])
+	if err != nil || lineno < 0 {
+		return 0, 0, false
+	}
+
+	hash, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil || hash == 0 {
+		return 0, 0, false
+	}
+
+	return lineno, uint32(hash), true
+}
+
+// hashRunLine returns the hash of the line, which is never zero.
+func hashRunLine(line string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(line))
+	if sum := h.Sum32(); sum != 0 {
+		return sum
+	}
+	return 1
+}
+
+func (r *runCheckpoint) set(lineno int, hash uint32) {
+	if lineno >= len(r.hashes) {
+		size := 2 * len(r.hashes)
+		if size <= lineno {
+			size = lineno + 1
+		}
+		hashes := make([]uint32, size)
+		copy(hashes, r.hashes)
+		r.hashes = hashes
+	}
+	r.hashes[lineno] = hash
+}
+
+// completed reports whether the line is completed in an earlier run.
+func (r *runCheckpoint) completed(lineno int, line string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return lineno < len(r.hashes) && r.hashes[lineno] == hashRunLine(line)
+}
+
+// record records the line as completed. It is safe to be called
+// concurrently.
+func (r *runCheckpoint) record(lineno int, line string) error {
+	hash := hashRunLine(line)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(lineno, hash)
+	_, err := fmt.Fprintf(r.file, "%d %x\n", lineno, hash)
+	return err
+}
+
+// Close closes the checkpoint file.
+func (r *runCheckpoint) Close() error {
+	return r.file.Close()
+}
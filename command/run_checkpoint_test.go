@@ -0,0 +1,65 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	checkpoint, err := openRunCheckpoint(path)
+	assert.NilError(t, err)
+
+	lines := []string{"cp a b", "cp c d", "cp e f", "cp g h"}
+
+	// record lines concurrently, and out of order.
+	var wg sync.WaitGroup
+	for _, lineno := range []int{3, 0, 2} {
+		wg.Add(1)
+		go func(lineno int) {
+			defer wg.Done()
+			assert.NilError(t, checkpoint.record(lineno, lines[lineno]))
+		}(lineno)
+	}
+	wg.Wait()
+	assert.NilError(t, checkpoint.Close())
+
+	// simulate a record which is partially written before a crash.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NilError(t, err)
+	_, err = f.WriteString("1")
+	assert.NilError(t, err)
+	assert.NilError(t, f.Close())
+
+	checkpoint, err = openRunCheckpoint(path)
+	assert.NilError(t, err)
+	defer checkpoint.Close()
+
+	assert.Assert(t, checkpoint.completed(0, lines[0]))
+	assert.Assert(t, !checkpoint.completed(1, lines[1]))
+	assert.Assert(t, checkpoint.completed(2, lines[2]))
+	assert.Assert(t, checkpoint.completed(3, lines[3]))
+	assert.Assert(t, !checkpoint.completed(4, "cp i j"))
+
+	// a line which is edited after it is completed is not skipped.
+	assert.Assert(t, !checkpoint.completed(0, "cp a c"))
+
+	// the partial record is terminated before the next record.
+	assert.NilError(t, checkpoint.record(1, lines[1]))
+	data, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	var count int
+	for _, record := range strings.Split(string(data), "\n") {
+		if _, _, ok := parseRunCheckpointRecord(record); ok {
+			count++
+		}
+	}
+	assert.Equal(t, count, 4)
+}
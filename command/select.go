@@ -71,6 +71,12 @@ func buildSelect(c *cli.Context, inputFormat string, inputStructure *string) (cm
 		outputFormat = inputFormat
 	}
 
+	filter, err := newObjectFilter(c)
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
 	cmd = &Select{
 		src:         src,
 		op:          c.Command.Name,
@@ -83,6 +89,7 @@ func buildSelect(c *cli.Context, inputFormat string, inputStructure *string) (cm
 		exclude:               c.StringSlice("exclude"),
 		forceGlacierTransfer:  c.Bool("force-glacier-transfer"),
 		ignoreGlacierWarnings: c.Bool("ignore-glacier-warnings"),
+		filter:                filter,
 
 		storageOpts: NewStorageOpts(c),
 	}
@@ -130,6 +137,8 @@ func NewSelectCommand() *cli.Command {
 			Usage: "use the specified version of the object",
 		},
 	}
+	sharedFlags = append(sharedFlags, NewSSECustomerKeyFlags(false)...)
+	sharedFlags = append(sharedFlags, NewFilterFlags()...)
 
 	cmd := &cli.Command{
 		Name:     "select",
@@ -282,6 +291,7 @@ type Select struct {
 	exclude               []string
 	forceGlacierTransfer  bool
 	ignoreGlacierWarnings bool
+	filter                objectFilter
 
 	// s3 options
 	storageOpts storage.Options
@@ -373,6 +383,10 @@ func (s Select) Run(ctx context.Context) error {
 			continue
 		}
 
+		if !s.filter.isSelected(object) {
+			continue
+		}
+
 		task := s.prepareTask(ctx, client, object.URL, resultCh)
 		parallel.Run(task, waiter)
 
@@ -434,5 +448,9 @@ func validateSelectCommand(c *cli.Context) error {
 		return fmt.Errorf("query must be non-empty")
 	}
 
-	return nil
+	if _, err := newObjectFilter(c); err != nil {
+		return err
+	}
+
+	return validateSSECustomerKey(c)
 }
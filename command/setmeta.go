@@ -0,0 +1,419 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+var setmetaHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Set the cache control header of all objects with a prefix
+		 > s5cmd {{.HelpName}} --cache-control "public, max-age=345600" "s3://bucket/prefix/*"
+
+	2. Set the content type of all objects that matches a wildcard
+		 > s5cmd {{.HelpName}} --content-type text/css "s3://bucket/*.css"
+
+	3. Add a user metadata key to an object, keeping its other keys
+		 > s5cmd {{.HelpName}} --metadata "project=s5cmd" s3://bucket/prefix/object.gz
+
+	4. Remove user metadata keys from an object
+		 > s5cmd {{.HelpName}} --remove-metadata file-owner --remove-metadata file-group s3://bucket/prefix/object.gz
+
+	5. Replace all user metadata of an object
+		 > s5cmd {{.HelpName}} --replace-metadata --metadata "project=s5cmd" s3://bucket/prefix/object.gz
+
+	6. Remove the content disposition header of an object
+		 > s5cmd {{.HelpName}} --content-disposition "" s3://bucket/prefix/object.gz
+
+	7. Change the storage class and the ACL of all objects with a prefix
+		 > s5cmd {{.HelpName}} --storage-class STANDARD_IA --acl public-read "s3://bucket/prefix/*"
+`
+
+// setmetaHeaderFlags are the flags of the headers that setmeta command can
+// set. Headers are removed if their flags are given empty values.
+var setmetaHeaderFlags = []string{
+	"content-type",
+	"content-encoding",
+	"content-disposition",
+	"cache-control",
+	"expires",
+}
+
+func NewSetmetaCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "setmeta",
+		HelpName: "setmeta",
+		Usage:    "update the metadata of objects in place",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "raw",
+				Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
+			},
+			&MapFlag{
+				Name:  "metadata",
+				Usage: "set user metadata keys of the objects, keeping the other keys, e.g. --metadata 'foo=bar' --metadata 'fizz=buzz'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "remove-metadata",
+				Usage: "remove the given user metadata key of the objects",
+			},
+			&cli.BoolFlag{
+				Name:  "replace-metadata",
+				Usage: "replace all user metadata of the objects with the ones given with --metadata flag",
+			},
+			&cli.StringFlag{
+				Name:  "content-type",
+				Usage: "set content type header of the objects, e.g. --content-type text/plain",
+			},
+			&cli.StringFlag{
+				Name:  "content-encoding",
+				Usage: "set content encoding header of the objects, e.g. --content-encoding gzip",
+			},
+			&cli.StringFlag{
+				Name:  "content-disposition",
+				Usage: "set content disposition header of the objects, e.g. --content-disposition 'attachment; filename=\"filename.jpg\"'",
+			},
+			&cli.StringFlag{
+				Name:  "cache-control",
+				Usage: "set cache control header of the objects, e.g. --cache-control 'public, max-age=345600'",
+			},
+			&cli.StringFlag{
+				Name:  "expires",
+				Usage: "set expires header of the objects (uses RFC3339 format), e.g. --expires '2024-10-01T20:30:00Z'",
+			},
+			&cli.StringFlag{
+				Name:  "storage-class",
+				Usage: "set storage class of the objects ('STANDARD','REDUCED_REDUNDANCY','GLACIER','STANDARD_IA','ONEZONE_IA','INTELLIGENT_TIERING','DEEP_ARCHIVE')",
+			},
+			&cli.StringFlag{
+				Name:  "acl",
+				Usage: "set acl of the objects, e.g. --acl 'public-read'",
+			},
+			&cli.IntFlag{
+				Name:    "concurrency",
+				Aliases: []string{"c"},
+				Value:   defaultCopyConcurrency,
+				Usage:   "number of concurrent parts copied for the objects larger than 5GiB",
+			},
+			&cli.IntFlag{
+				Name:    "part-size",
+				Aliases: []string{"p"},
+				Value:   defaultPartSize,
+				Usage:   "size of each part copied for the objects larger than 5GiB, in MiB",
+			},
+		}, NewSSECustomerKeyFlags(false)...),
+		CustomHelpTemplate: setmetaHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateSetmetaCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			setmeta, err := NewSetmeta(c)
+			if err != nil {
+				return err
+			}
+			return setmeta.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// Setmeta holds setmeta operation flags and states.
+type Setmeta struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	metadata        map[string]string
+	removeMetadata  []string
+	replaceMetadata bool
+	headers         map[string]string
+	storageClass    string
+	acl             string
+	concurrency     int
+	partSize        int64
+	sseCustomerKey  string
+
+	storageOpts storage.Options
+}
+
+// NewSetmeta creates Setmeta from cli.Context.
+func NewSetmeta(c *cli.Context) (*Setmeta, error) {
+	fullCommand := commandFromContext(c)
+
+	src, err := url.New(c.Args().Get(0), url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	sseCustomerKey, err := loadSSECustomerKey(c, "sse-c-key")
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	metadata, _ := c.Value("metadata").(MapValue)
+
+	// only the given headers are changed.
+	headers := map[string]string{}
+	for _, name := range setmetaHeaderFlags {
+		if c.IsSet(name) {
+			headers[name] = c.String(name)
+		}
+	}
+
+	return &Setmeta{
+		src:         src,
+		op:          c.Command.Name,
+		fullCommand: fullCommand,
+
+		metadata:        metadata,
+		removeMetadata:  c.StringSlice("remove-metadata"),
+		replaceMetadata: c.Bool("replace-metadata"),
+		headers:         headers,
+		storageClass:    c.String("storage-class"),
+		acl:             c.String("acl"),
+		concurrency:     c.Int("concurrency"),
+		partSize:        c.Int64("part-size") * megabytes,
+		sseCustomerKey:  sseCustomerKey,
+
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// Run updates the metadata of the source objects by copying them onto
+// themselves.
+func (s Setmeta) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteStorage(ctx, s.src, s.storageOpts)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, s.src)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	var (
+		merrorWaiter  error
+		merrorObjects error
+	)
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(s.fullCommand, s.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	for object := range objch {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(s.fullCommand, s.op, err)
+			continue
+		}
+
+		parallel.Run(s.prepareTask(ctx, client, object.URL), waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
+}
+
+func (s Setmeta) prepareTask(ctx context.Context, client storage.Storage, objurl *url.URL) func() error {
+	return func() error {
+		err := s.setmeta(ctx, client, objurl)
+		if err != nil {
+			return &errorpkg.Error{
+				Op:  s.op,
+				Src: objurl,
+				Err: err,
+			}
+		}
+
+		log.Info(log.InfoMessage{
+			Operation: s.op,
+			Source:    objurl,
+		})
+		return nil
+	}
+}
+
+func (s Setmeta) setmeta(ctx context.Context, client storage.Storage, objurl *url.URL) error {
+	// the metadata of the object is replaced on the copy, the current
+	// metadata is fetched to keep what is not changed.
+	obj, err := client.Stat(ctx, objurl)
+	if err != nil {
+		return err
+	}
+
+	// the copy resets the ACL of the object to private unless an ACL is
+	// given, the current grants are set back after the copy.
+	s3Client, ok := client.(*storage.S3)
+	var acl *storage.ACL
+	if ok && s.acl == "" {
+		acl, err = s3Client.GetACL(ctx, objurl)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Copy(ctx, objurl, objurl, s.updateMetadata(obj), s.concurrency, s.partSize)
+	if err != nil {
+		return err
+	}
+
+	if acl != nil && !acl.IsPrivate() {
+		return s3Client.SetACL(ctx, objurl, acl)
+	}
+	return nil
+}
+
+// updateMetadata returns the metadata of the object with the changes given
+// with the flags applied.
+func (s Setmeta) updateMetadata(obj *storage.Object) storage.Metadata {
+	metadata := storage.Metadata{
+		MetadataDirective:  "REPLACE",
+		SourceSize:         obj.Size,
+		ContentType:        obj.ContentType,
+		ContentEncoding:    obj.ContentEncoding,
+		ContentDisposition: obj.ContentDisposition,
+		CacheControl:       obj.CacheControl,
+		StorageClass:       string(obj.StorageClass),
+		ACL:                s.acl,
+
+		SSECustomerKey:           s.sseCustomerKey,
+		SSECopySourceCustomerKey: s.sseCustomerKey,
+
+		UserDefined: map[string]string{},
+	}
+
+	if t, err := http.ParseTime(obj.Expires); err == nil {
+		metadata.Expires = t.Format(time.RFC3339)
+	}
+
+	// the encryption of the object is kept, unless it is encrypted with a
+	// customer provided key which is given with the flags instead.
+	if obj.EncryptionMethod != "SSE-C" {
+		metadata.EncryptionMethod = obj.EncryptionMethod
+		metadata.EncryptionKeyID = obj.EncryptionKeyID
+	}
+
+	if s.storageClass != "" {
+		metadata.StorageClass = s.storageClass
+	}
+
+	for name, value := range s.headers {
+		switch name {
+		case "content-type":
+			metadata.ContentType = value
+		case "content-encoding":
+			metadata.ContentEncoding = value
+		case "content-disposition":
+			metadata.ContentDisposition = value
+		case "cache-control":
+			metadata.CacheControl = value
+		case "expires":
+			metadata.Expires = value
+		}
+	}
+
+	// metadata keys are case insensitive, and they are stored in lower case.
+	if !s.replaceMetadata {
+		for k, v := range obj.UserDefined {
+			metadata.UserDefined[strings.ToLower(k)] = v
+		}
+	}
+	for _, k := range s.removeMetadata {
+		delete(metadata.UserDefined, strings.ToLower(k))
+	}
+	for k, v := range s.metadata {
+		metadata.UserDefined[strings.ToLower(k)] = v
+	}
+
+	return metadata
+}
+
+func validateSetmetaCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote object url")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() || src.IsPrefix() {
+		return fmt.Errorf("remote source must be an object")
+	}
+
+	changeFlags := append([]string{
+		"metadata",
+		"remove-metadata",
+		"replace-metadata",
+		"storage-class",
+		"acl",
+	}, setmetaHeaderFlags...)
+
+	var changed bool
+	for _, name := range changeFlags {
+		changed = changed || c.IsSet(name)
+	}
+	if !changed {
+		return fmt.Errorf("at least one of the metadata flags must be given")
+	}
+
+	if c.IsSet("expires") && c.String("expires") != "" {
+		if _, err := time.Parse(time.RFC3339, c.String("expires")); err != nil {
+			return fmt.Errorf("invalid expires %q: must be in RFC3339 format", c.String("expires"))
+		}
+	}
+
+	return validateSSECustomerKey(c)
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+)
+
+func TestSetmetaUpdateMetadata(t *testing.T) {
+	t.Parallel()
+
+	obj := &storage.Object{
+		ContentType:        "text/plain",
+		ContentDisposition: "inline",
+		CacheControl:       "no-cache",
+		Expires:            "Wed, 21 Oct 2015 07:28:00 GMT",
+		StorageClass:       storage.StorageClass("STANDARD_IA"),
+		EncryptionMethod:   "aws:kms",
+		EncryptionKeyID:    "key-id",
+		UserDefined: map[string]string{
+			"file-mtime": "1600000000",
+			"file-owner": "1000",
+		},
+	}
+
+	testcases := []struct {
+		name     string
+		setmeta  Setmeta
+		expected storage.Metadata
+	}{
+		{
+			name: "merge metadata",
+			setmeta: Setmeta{
+				metadata:       map[string]string{"Project": "s5cmd"},
+				removeMetadata: []string{"file-owner"},
+				headers: map[string]string{
+					"content-type":        "text/css",
+					"content-disposition": "",
+				},
+			},
+			expected: storage.Metadata{
+				MetadataDirective: "REPLACE",
+				ContentType:       "text/css",
+				CacheControl:      "no-cache",
+				Expires:           "2015-10-21T07:28:00Z",
+				StorageClass:      "STANDARD_IA",
+				EncryptionMethod:  "aws:kms",
+				EncryptionKeyID:   "key-id",
+				UserDefined: map[string]string{
+					"file-mtime": "1600000000",
+					"project":    "s5cmd",
+				},
+			},
+		},
+		{
+			name: "replace metadata",
+			setmeta: Setmeta{
+				metadata:        map[string]string{"project": "s5cmd"},
+				replaceMetadata: true,
+				storageClass:    "GLACIER",
+				acl:             "public-read",
+			},
+			expected: storage.Metadata{
+				MetadataDirective:  "REPLACE",
+				ContentType:        "text/plain",
+				ContentDisposition: "inline",
+				CacheControl:       "no-cache",
+				Expires:            "2015-10-21T07:28:00Z",
+				StorageClass:       "GLACIER",
+				ACL:                "public-read",
+				EncryptionMethod:   "aws:kms",
+				EncryptionKeyID:    "key-id",
+				UserDefined: map[string]string{
+					"project": "s5cmd",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.DeepEqual(t, tc.setmeta.updateMetadata(obj), tc.expected)
+		})
+	}
+}
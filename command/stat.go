@@ -0,0 +1,278 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+var statHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Print the metadata of an object
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Print the metadata of all objects with a prefix in JSON
+		 > s5cmd --json {{.HelpName}} "s3://bucket/prefix/*"
+
+	3. Print the metadata of the specific version of an object
+		 > s5cmd {{.HelpName}} --version-id VERSION_ID s3://bucket/prefix/object.gz
+
+	4. Print the metadata of an object encrypted with a customer provided key
+		 > s5cmd {{.HelpName}} --sse-c-key-file key.bin s3://bucket/prefix/object.gz
+`
+
+func NewStatCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "stat",
+		HelpName: "stat",
+		Usage:    "print the metadata of objects",
+		Aliases:  []string{"head"},
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "raw",
+				Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
+			},
+			&cli.StringFlag{
+				Name:  "version-id",
+				Usage: "use the specified version of an object",
+			},
+		}, NewSSECustomerKeyFlags(false)...),
+		CustomHelpTemplate: statHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateStatCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			fullCommand := commandFromContext(c)
+
+			src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+				url.WithRaw(c.Bool("raw")))
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			return Stat{
+				src:         src,
+				op:          c.Command.Name,
+				fullCommand: fullCommand,
+				storageOpts: NewStorageOpts(c),
+			}.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// Stat holds stat operation flags and states.
+type Stat struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	storageOpts storage.Options
+}
+
+// Run prints the metadata of the source objects.
+func (s Stat) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteStorage(ctx, s.src, s.storageOpts)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, s.src)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	var (
+		merrorWaiter  error
+		merrorObjects error
+	)
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(s.fullCommand, s.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	for object := range objch {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(s.fullCommand, s.op, err)
+			continue
+		}
+
+		parallel.Run(s.prepareTask(ctx, client, object.URL), waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
+}
+
+func (s Stat) prepareTask(ctx context.Context, client storage.Storage, objurl *url.URL) func() error {
+	return func() error {
+		// listed objects lack most of the headers, the object is fetched
+		// again for the full metadata.
+		obj, err := client.Stat(ctx, objurl)
+		if err != nil {
+			return &errorpkg.Error{
+				Op:  s.op,
+				Src: objurl,
+				Err: err,
+			}
+		}
+
+		log.Info(StatMessage{Object: obj})
+		return nil
+	}
+}
+
+func validateStatCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote object url")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+		url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() || src.IsPrefix() {
+		return fmt.Errorf("remote source must be an object")
+	}
+
+	if err := checkVersioningWithGoogleEndpoint(c); err != nil {
+		return err
+	}
+
+	return validateSSECustomerKey(c)
+}
+
+// StatMessage is a structure for logging the metadata of objects.
+type StatMessage struct {
+	Object *storage.Object
+}
+
+// String returns the string representation of StatMessage.
+func (s StatMessage) String() string {
+	obj := s.Object
+
+	type field struct {
+		name  string
+		value string
+	}
+
+	fields := []field{
+		{"URL", obj.URL.String()},
+		{"Size", fmt.Sprintf("%v (%v bytes)", strutil.HumanizeBytes(obj.Size), obj.Size)},
+	}
+	if obj.ModTime != nil {
+		fields = append(fields, field{"Last-Modified", obj.ModTime.Format(dateFormat)})
+	}
+
+	versionID := obj.VersionID
+	if obj.URL.VersionID != "" {
+		versionID = obj.URL.VersionID
+	}
+
+	for _, f := range []field{
+		{"ETag", obj.Etag},
+		{"Storage-Class", string(obj.StorageClass)},
+		{"Version-ID", versionID},
+		{"Restore", string(obj.Restore)},
+		{"Content-Type", obj.ContentType},
+		{"Content-Encoding", obj.ContentEncoding},
+		{"Content-Disposition", obj.ContentDisposition},
+		{"Cache-Control", obj.CacheControl},
+		{"Expires", obj.Expires},
+		{"Encryption", obj.EncryptionMethod},
+		{"Encryption-Key-ID", obj.EncryptionKeyID},
+	} {
+		if f.value != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	keys := make([]string, 0, len(obj.UserDefined))
+	for k := range obj.UserDefined {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, field{"Metadata." + k, obj.UserDefined[k]})
+	}
+
+	var width int
+	for _, f := range fields {
+		if len(f.name) > width {
+			width = len(f.name)
+		}
+	}
+
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		lines = append(lines, fmt.Sprintf("%-*v %v", width+1, f.name+":", f.value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON returns the JSON representation of StatMessage. Unlike the JSON
+// representation of the objects, it includes their user metadata.
+func (s StatMessage) JSON() string {
+	obj := *s.Object
+	if obj.URL != nil && obj.URL.VersionID != "" {
+		obj.VersionID = obj.URL.VersionID
+	}
+
+	return strutil.JSON(struct {
+		storage.Object
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}{
+		Object:   obj,
+		Metadata: obj.UserDefined,
+	})
+}
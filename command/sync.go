@@ -14,6 +14,7 @@ import (
 	"github.com/lanrat/extsort"
 	"github.com/urfave/cli/v2"
 
+	"github.com/peak/s5cmd/v2/encryption"
 	errorpkg "github.com/peak/s5cmd/v2/error"
 	"github.com/peak/s5cmd/v2/log"
 	"github.com/peak/s5cmd/v2/log/stat"
@@ -69,6 +70,18 @@ Examples:
 	
 	11. Sync all files to S3 bucket but include the only ones with txt and gz extension
 		 > s5cmd {{.HelpName}} --include "*.txt" --include "*.gz" dir/ s3://bucket
+
+	12. Sync local folder to S3 bucket but use content checksums as only comparison criteria.
+		 > s5cmd {{.HelpName}} --checksum folder/ s3://bucket/
+
+	13. Sync local folder and S3 bucket in both directions, including deletions since the previous sync
+		 > s5cmd {{.HelpName}} --bidirectional folder/ s3://bucket/
+
+	14. Sync local folder and S3 bucket in both directions, keeping both versions of the objects changed on both sides
+		 > s5cmd {{.HelpName}} --bidirectional --conflict keep-both folder/ s3://bucket/
+
+	15. Sync local folder to S3 bucket using the destination listing recorded by the previous sync to the given file
+		 > s5cmd {{.HelpName}} --state-file ~/.cache/s5cmd/bucket.jsonl folder/ s3://bucket/
 `
 
 func NewSyncCommandFlags() []cli.Flag {
@@ -81,10 +94,35 @@ func NewSyncCommandFlags() []cli.Flag {
 			Name:  "size-only",
 			Usage: "make size of object only criteria to decide whether an object should be synced",
 		},
+		&cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "make checksum of object only criteria to decide whether an object should be synced",
+		},
 		&cli.BoolFlag{
 			Name:  "exit-on-error",
 			Usage: "stops the sync process if an error is received",
 		},
+		&cli.BoolFlag{
+			Name:  "bidirectional",
+			Usage: "propagate new, changed and deleted objects in both directions since the previous sync",
+		},
+		&cli.GenericFlag{
+			Name: "conflict",
+			Value: &EnumValue{
+				Enum:    []string{conflictNewerWins, conflictKeepBoth, conflictSkip},
+				Default: conflictSkip,
+			},
+			Usage: "what to do with objects changed on both sides in bidirectional sync: (newer-wins, keep-both, skip)",
+		},
+		&cli.StringFlag{
+			Name:  "conflict-suffix",
+			Value: "-conflict",
+			Usage: "suffix added to the names of destination versions kept by keep-both conflict policy",
+		},
+		&cli.StringFlag{
+			Name:  "state-file",
+			Usage: "file to record the destination listing to be used by the next sync instead of listing the destination, or the state of bidirectional sync",
+		},
 	}
 	sharedFlags := NewSharedFlags()
 	return append(syncFlags, sharedFlags...)
@@ -98,8 +136,7 @@ func NewSyncCommand() *cli.Command {
 		Flags:              NewSyncCommandFlags(),
 		CustomHelpTemplate: syncHelpTemplate,
 		Before: func(c *cli.Context) error {
-			// sync command share same validation method as copy command
-			err := validateCopyCommand(c)
+			err := validateSyncCommand(c)
 			if err != nil {
 				printError(commandFromContext(c), c.Command.Name, err)
 			}
@@ -130,9 +167,16 @@ type Sync struct {
 	// flags
 	delete            bool
 	sizeOnly          bool
+	checksum          bool
 	exitOnError       bool
+	bidirectional     bool
+	conflict          string
+	conflictSuffix    string
+	stateFile         string
 	preserveTimestamp bool
 	preserveOwnership bool
+	clientEncryption  bool
+	filter            objectFilter
 
 	// s3 options
 	storageOpts storage.Options
@@ -140,13 +184,21 @@ type Sync struct {
 	followSymlinks bool
 	storageClass   storage.StorageClass
 	raw            bool
+	numWorkers     int
+	partSize       int64
 
 	srcRegion string
 	dstRegion string
+
+	// index is the destination listing recorded by the previous sync.
+	index *syncIndex
 }
 
 // NewSync creates Sync from cli.Context
 func NewSync(c *cli.Context) Sync {
+	// the filter flags are validated before.
+	filter, _ := newObjectFilter(c)
+
 	return Sync{
 		src:         c.Args().Get(0),
 		dst:         c.Args().Get(1),
@@ -156,14 +208,23 @@ func NewSync(c *cli.Context) Sync {
 		// flags
 		delete:            c.Bool("delete"),
 		sizeOnly:          c.Bool("size-only"),
+		checksum:          c.Bool("checksum"),
 		exitOnError:       c.Bool("exit-on-error"),
+		bidirectional:     c.Bool("bidirectional"),
+		conflict:          c.String("conflict"),
+		conflictSuffix:    c.String("conflict-suffix"),
+		stateFile:         c.String("state-file"),
 		preserveTimestamp: c.Bool("preserve-timestamp"),
 		preserveOwnership: c.Bool("preserve-ownership"),
+		clientEncryption:  isClientEncryptionSet(c),
+		filter:            filter,
 
 		// flags
 		followSymlinks: !c.Bool("no-follow-symlinks"),
 		storageClass:   storage.StorageClass(c.String("storage-class")),
 		raw:            c.Bool("raw"),
+		numWorkers:     numWorkers(c),
+		partSize:       c.Int64("part-size") * megabytes,
 		// region settings
 		srcRegion:   c.String("source-region"),
 		dstRegion:   c.String("destination-region"),
@@ -184,8 +245,20 @@ func (s Sync) Run(c *cli.Context) error {
 		return err
 	}
 
+	if !s.bidirectional && s.stateFile != "" {
+		s.index, err = s.openIndex()
+		if err != nil {
+			printError(s.fullCommand, s.op, err)
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(c.Context)
 
+	if s.bidirectional {
+		return s.runBidirectional(c, ctx, cancel, srcurl, dsturl)
+	}
+
 	sourceObjects, destObjects, err := s.getSourceAndDestinationObjects(ctx, cancel, srcurl, dsturl)
 	if err != nil {
 		printError(s.fullCommand, s.op, err)
@@ -232,23 +305,49 @@ func (s Sync) Run(c *cli.Context) error {
 		}
 	}()
 
-	strategy := NewStrategy(s.sizeOnly) // create comparison strategy.
-	pipeReader, pipeWriter := io.Pipe() // create a reader, writer pipe to pass commands to run
+	strategy := NewStrategy(s.sizeOnly, s.checksum, s.partSize) // create comparison strategy.
+	pipeReader, pipeWriter := io.Pipe()                         // create a reader, writer pipe to pass commands to run
 
 	// Create commands in background.
 	go s.planRun(c, onlySource, onlyDest, commonObjects, dsturl, strategy, pipeWriter, isBatch)
 
 	err = NewRun(c, pipeReader).Run(ctx)
+
+	if s.index != nil && !s.storageOpts.DryRun {
+		if ierr := s.updateIndex(ctx, dsturl); ierr != nil {
+			printError(s.fullCommand, s.op, ierr)
+			err = multierror.Append(err, ierr)
+		}
+	}
 	return multierror.Append(err, merrorWaiter).ErrorOrNil()
 }
 
+// openIndex returns the destination listing recorded by the previous sync.
+func (s Sync) openIndex() (*syncIndex, error) {
+	base, err := destinationListingURL(s.dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSyncIndex(s.stateFile, base), nil
+}
+
+// updateIndex records the destination listing for the next sync.
+func (s Sync) updateIndex(ctx context.Context, dsturl *url.URL) error {
+	client, err := storage.NewClient(ctx, dsturl, s.storageOpts)
+	if err != nil {
+		return err
+	}
+	return s.index.Update(ctx, client, s.numWorkers)
+}
+
 // compareObjects compares source and destination objects. It assumes that
 // sourceObjects and destObjects channels are already sorted in ascending order.
 // Returns objects those in only source, only destination
 // and both.
-func compareObjects(sourceObjects, destObjects chan *storage.Object) (chan *url.URL, chan *url.URL, chan *ObjectPair) {
+func compareObjects(sourceObjects, destObjects chan *storage.Object) (chan *storage.Object, chan *url.URL, chan *ObjectPair) {
 	var (
-		srcOnly   = make(chan *url.URL, extsortChannelBufferSize)
+		srcOnly   = make(chan *storage.Object, extsortChannelBufferSize)
 		dstOnly   = make(chan *url.URL, extsortChannelBufferSize)
 		commonObj = make(chan *ObjectPair, extsortChannelBufferSize)
 		srcName   string
@@ -273,7 +372,7 @@ func compareObjects(sourceObjects, destObjects chan *storage.Object) (chan *url.
 
 			if srcOk && dstOk {
 				if srcName < dstName {
-					srcOnly <- src.URL
+					srcOnly <- src
 					src, srcOk = <-sourceObjects
 				} else if srcName == dstName { // if there is a match.
 					commonObj <- &ObjectPair{src: src, dst: dst}
@@ -284,7 +383,7 @@ func compareObjects(sourceObjects, destObjects chan *storage.Object) (chan *url.
 					dst, dstOk = <-destObjects
 				}
 			} else if srcOk {
-				srcOnly <- src.URL
+				srcOnly <- src
 				src, srcOk = <-sourceObjects
 			} else if dstOk {
 				dstOnly <- dst.URL
@@ -312,15 +411,7 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 		return nil, nil, err
 	}
 
-	// add * to end of destination string, to get all objects recursively.
-	var destinationURLPath string
-	if strings.HasSuffix(s.dst, "/") {
-		destinationURLPath = s.dst + "*"
-	} else {
-		destinationURLPath = s.dst + "/*"
-	}
-
-	destObjectsURL, err := url.New(destinationURLPath)
+	destObjectsURL, err := destinationListingURL(s.dst)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -361,6 +452,7 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 				if s.shouldSkipObject(st, true) {
 					continue
 				}
+				s.setPlaintextSize(st)
 				filteredSrcObjectChannel <- *st
 			}
 		}()
@@ -386,6 +478,27 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 		}()
 	}()
 
+	// get destination objects from the index of the previous sync, if it is
+	// still consistent with the destination.
+	if s.index != nil {
+		if s.index.valid(ctx, destClient) {
+			go func() {
+				defer close(destObjects)
+				if err := s.index.objects(ctx, destObjects); err != nil {
+					printError(s.fullCommand, s.op, err)
+					cancel()
+				}
+			}()
+			return sourceObjects, destObjects, nil
+		}
+
+		if !s.storageOpts.DryRun {
+			if err := s.index.record(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
 	// get destination objects.
 	go func() {
 		defer close(destObjects)
@@ -408,6 +521,7 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 				if s.shouldSkipObject(dt, false) {
 					continue
 				}
+				s.setPlaintextSize(dt)
 				filteredDstObjectChannel <- *dt
 			}
 		}()
@@ -422,6 +536,9 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 
 		for destObject := range dstOutputChan {
 			o := destObject.(storage.Object)
+			if s.index != nil {
+				s.index.add(&o)
+			}
 			destObjects <- &o
 		}
 
@@ -436,10 +553,21 @@ func (s Sync) getSourceAndDestinationObjects(ctx context.Context, cancel context
 	return sourceObjects, destObjects, nil
 }
 
+// destinationListingURL returns the url to list all objects under the given
+// destination recursively.
+func destinationListingURL(dst string) (*url.URL, error) {
+	// add * to end of destination string, to get all objects recursively.
+	if strings.HasSuffix(dst, "/") {
+		return url.New(dst + "*")
+	}
+	return url.New(dst + "/*")
+}
+
 // planRun prepares the commands and writes them to writer 'w'.
 func (s Sync) planRun(
 	c *cli.Context,
-	onlySource, onlyDest chan *url.URL,
+	onlySource chan *storage.Object,
+	onlyDest chan *url.URL,
 	common chan *ObjectPair,
 	dsturl *url.URL,
 	strategy SyncStrategy,
@@ -471,13 +599,24 @@ func (s Sync) planRun(
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		for srcurl := range onlySource {
+		for srcObject := range onlySource {
+			// the source objects are filtered after they are compared with
+			// the destination objects, so that the destination objects of
+			// the filtered out ones are not deleted.
+			if !s.filter.isSelected(srcObject) {
+				continue
+			}
+
+			srcurl := srcObject.URL
 			curDestURL := generateDestinationURL(srcurl, dsturl, isBatch)
 			command, err := generateCommand(c, "cp", defaultFlags, srcurl, curDestURL)
 			if err != nil {
 				printDebug(s.op, err, srcurl, curDestURL)
 				continue
 			}
+			if s.index != nil {
+				s.index.touch(srcurl.Relative(), curDestURL)
+			}
 			fmt.Fprintln(w, command)
 		}
 	}()
@@ -486,21 +625,53 @@ func (s Sync) planRun(
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+
+		// comparing checksums requires hashing local files, which is
+		// expensive enough to be done in parallel. A separate manager is used
+		// to not to compete with the copy tasks for the global workers.
+		var (
+			pm     *parallel.Manager
+			waiter *parallel.Waiter
+		)
+		if s.checksum {
+			pm = parallel.New(s.numWorkers)
+			defer pm.Close()
+
+			waiter = parallel.NewWaiter()
+			defer waiter.Wait()
+		}
+
 		for commonObject := range common {
 			sourceObject, destObject := commonObject.src, commonObject.dst
-			curSourceURL, curDestURL := sourceObject.URL, destObject.URL
-			err := strategy.ShouldSync(sourceObject, destObject) // check if object should be copied.
-			if err != nil {
-				printDebug(s.op, err, curSourceURL, curDestURL)
+			if !s.filter.isSelected(sourceObject) {
 				continue
 			}
 
-			command, err := generateCommand(c, "cp", defaultFlags, curSourceURL, curDestURL)
-			if err != nil {
-				printDebug(s.op, err, curSourceURL, curDestURL)
+			task := func() error {
+				curSourceURL, curDestURL := sourceObject.URL, destObject.URL
+				err := strategy.ShouldSync(sourceObject, destObject) // check if object should be copied.
+				if err != nil {
+					printDebug(s.op, err, curSourceURL, curDestURL)
+					return nil
+				}
+
+				command, err := generateCommand(c, "cp", defaultFlags, curSourceURL, curDestURL)
+				if err != nil {
+					printDebug(s.op, err, curSourceURL, curDestURL)
+					return nil
+				}
+				if s.index != nil {
+					s.index.touch(curDestURL.Relative(), curDestURL)
+				}
+				fmt.Fprintln(w, command)
+				return nil
+			}
+
+			if pm != nil {
+				pm.Run(task, waiter)
 				continue
 			}
-			fmt.Fprintln(w, command)
+			_ = task()
 		}
 	}()
 
@@ -521,7 +692,12 @@ func (s Sync) planRun(
 				return
 			}
 
-			removeFlags := defaultFlags
+			// copy the default flags since they are read by the other
+			// goroutines concurrently.
+			removeFlags := map[string]interface{}{}
+			for flagname, flagvalue := range defaultFlags {
+				removeFlags[flagname] = flagvalue
+			}
 			delete(removeFlags, "preserve-timestamp")
 			delete(removeFlags, "preserve-ownership")
 			command, err := generateCommand(c, "rm", removeFlags, dstURLs...)
@@ -529,6 +705,11 @@ func (s Sync) planRun(
 				printDebug(s.op, err, dstURLs...)
 				return
 			}
+			if s.index != nil {
+				for _, d := range dstURLs {
+					s.index.touch(d.Relative(), d)
+				}
+			}
 			fmt.Fprintln(w, command)
 		} else {
 			// we only need  to consume them from the channel so that rest of the objects
@@ -572,6 +753,10 @@ func (s Sync) shouldSkipObject(object *storage.Object, verbose bool) bool {
 	}
 
 	if err := object.Err; err != nil {
+		// either side of a bidirectional sync can be empty.
+		if s.bidirectional && err == storage.ErrNoObjectFound {
+			return true
+		}
 		if verbose {
 			printError(s.fullCommand, s.op, err)
 		}
@@ -588,6 +773,42 @@ func (s Sync) shouldSkipObject(object *storage.Object, verbose bool) bool {
 	return false
 }
 
+// setPlaintextSize sets the size of a remote object to the size of its
+// plaintext if objects are encrypted on the client side, so that it is
+// comparable to the size of a local file. Listings do not include the user
+// metadata, thus all remote objects are assumed to be encrypted.
+func (s Sync) setPlaintextSize(object *storage.Object) {
+	if !s.clientEncryption || !object.URL.IsRemote() || object.Type.IsDir() {
+		return
+	}
+	if size, ok := encryption.PlaintextSize(object.Size); ok {
+		object.Size = size
+	}
+}
+
+func validateSyncCommand(c *cli.Context) error {
+	if c.Bool("size-only") && c.Bool("checksum") {
+		return fmt.Errorf(`"size-only" and "checksum" flags cannot be used together`)
+	}
+
+	if c.Bool("checksum") && isClientEncryptionSet(c) {
+		return fmt.Errorf(`"checksum" flag cannot be used with client-side encryption`)
+	}
+
+	if c.Bool("bidirectional") {
+		if err := validateBidirectionalSync(c); err != nil {
+			return err
+		}
+
+		if isFilterSet(c) {
+			return fmt.Errorf(`"bidirectional" flag cannot be used with the filter flags`)
+		}
+	}
+
+	// sync command share same validation method as copy command
+	return validateCopyCommand(c)
+}
+
 // shouldStopSync determines whether a sync process should be stopped or not.
 func (s Sync) shouldStopSync(err error) bool {
 	if err == storage.ErrNoObjectFound {
@@ -0,0 +1,610 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// Conflict policies of bidirectional sync, which decide what to do when an
+// object is changed on both sides since the previous sync.
+const (
+	conflictNewerWins = "newer-wins"
+	conflictKeepBoth  = "keep-both"
+	conflictSkip      = "skip"
+)
+
+// syncAction is the operation planned for an object in a bidirectional sync.
+type syncAction int
+
+const (
+	syncNone syncAction = iota
+	syncToDestination
+	syncToSource
+	syncDeleteDestination
+	syncDeleteSource
+	syncKeepBoth
+	syncConflict
+)
+
+// SyncSnapshot is the state of both sides of a bidirectional sync after it
+// completes. The next sync compares the objects against it to find out which
+// side an object is created, changed or deleted on.
+type SyncSnapshot struct {
+	Source      string                       `json:"source"`
+	Destination string                       `json:"destination"`
+	Entries     map[string]SyncSnapshotEntry `json:"entries"`
+
+	path string
+}
+
+// SyncSnapshotEntry is the state of an object on both sides.
+type SyncSnapshotEntry struct {
+	Source      SyncObjectState `json:"source"`
+	Destination SyncObjectState `json:"destination"`
+}
+
+// SyncObjectState is the state of an object on one side.
+type SyncObjectState struct {
+	Size    int64     `json:"size"`
+	ETag    string    `json:"etag,omitempty"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// defaultSyncSnapshotPath returns the path of the snapshot of the
+// bidirectional sync between the given roots, which is under the user's cache
+// directory.
+func defaultSyncSnapshotPath(srcRoot, dstRoot *url.URL) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(syncRootName(srcRoot) + "\x00" + syncRootName(dstRoot)))
+	return filepath.Join(dir, "s5cmd", "sync", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// syncRootName returns the name of a sync root which doesn't depend on the
+// working directory.
+func syncRootName(u *url.URL) string {
+	if u.IsRemote() {
+		return u.Absolute()
+	}
+
+	abs, err := filepath.Abs(u.Absolute())
+	if err != nil {
+		return u.Absolute()
+	}
+	return abs
+}
+
+// loadSyncSnapshot reads the snapshot in the given path. A non-existent
+// snapshot is empty, as if the roots have never been synced.
+func loadSyncSnapshot(path string, srcRoot, dstRoot *url.URL) (*SyncSnapshot, error) {
+	snapshot := &SyncSnapshot{
+		Source:      syncRootName(srcRoot),
+		Destination: syncRootName(dstRoot),
+		Entries:     map[string]SyncSnapshotEntry{},
+		path:        path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid sync snapshot %q: %w", path, err)
+	}
+	if snapshot.Entries == nil {
+		snapshot.Entries = map[string]SyncSnapshotEntry{}
+	}
+	return snapshot, nil
+}
+
+// lockSyncSnapshot creates the lock file of the snapshot in the given path,
+// which keeps the overlapping syncs of the same roots from overwriting the
+// entries recorded by each other. The returned function removes the lock
+// file.
+func lockSyncSnapshot(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	lockPath := path + ".lock"
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("another sync of the same source and destination is running, remove %q if it is not", lockPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(lockPath)
+		return nil, err
+	}
+
+	return func() { os.Remove(lockPath) }, nil
+}
+
+// Save writes the snapshot to its file atomically.
+func (s *SyncSnapshot) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// newSyncObjectState returns the state of the given object, or nil if the
+// object doesn't exist.
+func newSyncObjectState(obj *storage.Object) *SyncObjectState {
+	if obj == nil {
+		return nil
+	}
+
+	state := &SyncObjectState{
+		Size: obj.Size,
+		ETag: obj.Etag,
+	}
+	if obj.ModTime != nil {
+		state.ModTime = obj.ModTime.UTC()
+	}
+	return state
+}
+
+// changed reports whether the object is changed since the given state was
+// recorded. ETags are compared when both are known, since they are not
+// affected by the modification time being reset, e.g. by a restore.
+func (st *SyncObjectState) changed(prev *SyncObjectState) bool {
+	if st == nil || prev == nil {
+		return st != prev
+	}
+
+	if st.Size != prev.Size {
+		return true
+	}
+	if st.ETag != "" && prev.ETag != "" {
+		return st.ETag != prev.ETag
+	}
+	return !st.ModTime.Equal(prev.ModTime)
+}
+
+// bidirectionalAction decides the operation for an object, given its state
+// on both sides and its state after the previous sync.
+func (s Sync) bidirectionalAction(src, dst *storage.Object, prev *SyncSnapshotEntry) syncAction {
+	srcState, dstState := newSyncObjectState(src), newSyncObjectState(dst)
+
+	var srcChanged, dstChanged bool
+	if prev == nil {
+		srcChanged, dstChanged = src != nil, dst != nil
+	} else {
+		srcChanged = srcState.changed(&prev.Source)
+		dstChanged = dstState.changed(&prev.Destination)
+	}
+
+	switch {
+	case !srcChanged && !dstChanged:
+		return syncNone
+	case srcChanged && !dstChanged:
+		if src == nil {
+			return syncDeleteDestination
+		}
+		return syncToDestination
+	case !srcChanged && dstChanged:
+		if dst == nil {
+			return syncDeleteSource
+		}
+		return syncToSource
+	}
+
+	// changed on both sides.
+	switch {
+	case src == nil && dst == nil:
+		return syncNone
+	case src != nil && dst != nil && s.contentsMatch(src, dst):
+		return syncNone
+	case s.conflict == conflictSkip:
+		return syncConflict
+	case src == nil:
+		// deleted on the source, changed on the destination. Keep the
+		// changes instead of the deletion.
+		return syncToSource
+	case dst == nil:
+		return syncToDestination
+	case s.conflict == conflictKeepBoth:
+		return syncKeepBoth
+	}
+
+	// newer wins, the source wins the ties.
+	if dst.ModTime != nil && src.ModTime != nil && dst.ModTime.After(*src.ModTime) {
+		return syncToSource
+	}
+	return syncToDestination
+}
+
+// contentsMatch reports whether the objects have the same content.
+func (s Sync) contentsMatch(src, dst *storage.Object) bool {
+	strategy := &ChecksumStrategy{partSize: s.partSize}
+	return strategy.ShouldSync(src, dst) == errorpkg.ErrObjectChecksumsMatch
+}
+
+// conflictName returns the name of the copy of a conflicting object, which is
+// the key with the given suffix inserted before its extension.
+func conflictName(key, suffix string) string {
+	ext := path.Ext(key)
+	if ext == "" || ext == path.Base(key) {
+		return key + suffix
+	}
+	return strings.TrimSuffix(key, ext) + suffix + ext
+}
+
+// bidirectionalPlan is the result of planning a bidirectional sync.
+type bidirectionalPlan struct {
+	// deferred are the commands which can only be run after the planned
+	// commands are completed, since they overwrite objects that the planned
+	// commands read.
+	deferred []string
+	// conflicts are the keys which are left as they are due to conflicts.
+	conflicts map[string]struct{}
+	err       error
+}
+
+// runBidirectional syncs the objects under the source and destination roots
+// in both directions, and records their state for the next sync.
+func (s Sync) runBidirectional(c *cli.Context, ctx context.Context, cancel context.CancelFunc, srcurl, dsturl *url.URL) error {
+	srcRoot, dstRoot, err := syncRoots(s.src, s.dst, s.raw)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	snapshotPath := s.stateFile
+	if snapshotPath == "" {
+		snapshotPath, err = defaultSyncSnapshotPath(srcRoot, dstRoot)
+		if err != nil {
+			printError(s.fullCommand, s.op, err)
+			return err
+		}
+	}
+
+	// the snapshot is locked until it is updated, otherwise the entries
+	// recorded by an overlapping sync would be lost.
+	unlock, err := lockSyncSnapshot(snapshotPath)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+	defer unlock()
+
+	snapshot, err := loadSyncSnapshot(snapshotPath, srcRoot, dstRoot)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	sourceObjects, destObjects, err := s.getSourceAndDestinationObjects(ctx, cancel, srcurl, dsturl)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	var (
+		plan   bidirectionalPlan
+		planCh = make(chan struct{})
+	)
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		defer close(planCh)
+		plan = s.planBidirectionalRun(c, joinObjects(sourceObjects, destObjects), snapshot, srcRoot, dstRoot, pipeWriter)
+	}()
+
+	err = NewRun(c, pipeReader).Run(ctx)
+	<-planCh
+
+	if err == nil && len(plan.deferred) > 0 {
+		err = NewRun(c, strings.NewReader(strings.Join(plan.deferred, "\n"))).Run(ctx)
+	}
+
+	// the snapshot is updated only if all changes are propagated. Otherwise
+	// the changes which are not propagated would be recorded as if they were.
+	if err == nil && !s.storageOpts.DryRun {
+		if serr := s.updateSnapshot(ctx, cancel, snapshot, plan.conflicts, srcurl, dsturl); serr != nil {
+			printError(s.fullCommand, s.op, serr)
+			err = serr
+		}
+	}
+
+	return multierror.Append(err, plan.err).ErrorOrNil()
+}
+
+// planBidirectionalRun prepares the commands and writes them to writer 'w'.
+func (s Sync) planBidirectionalRun(
+	c *cli.Context,
+	objects chan *ObjectPair,
+	snapshot *SyncSnapshot,
+	srcRoot, dstRoot *url.URL,
+	w io.WriteCloser,
+) bidirectionalPlan {
+	defer w.Close()
+
+	defaultFlags := map[string]interface{}{
+		"raw": true,
+	}
+	if s.preserveOwnership {
+		defaultFlags["preserve-ownership"] = s.preserveOwnership
+	}
+	if s.preserveTimestamp {
+		defaultFlags["preserve-timestamp"] = s.preserveTimestamp
+	}
+
+	plan := bidirectionalPlan{
+		conflicts: map[string]struct{}{},
+	}
+
+	copyCommand := func(src, dst *url.URL) string {
+		command, err := generateCommand(c, "cp", defaultFlags, src, dst)
+		if err != nil {
+			printDebug(s.op, err, src, dst)
+			return ""
+		}
+		return command
+	}
+
+	emit := func(command string) {
+		if command != "" {
+			fmt.Fprintln(w, command)
+		}
+	}
+
+	var srcDeletes, dstDeletes []*url.URL
+	for pair := range objects {
+		src, dst := pair.src, pair.dst
+
+		key := syncKey(pair)
+		srcurl, dsturl := srcRoot.Join(key), dstRoot.Join(key)
+
+		var prev *SyncSnapshotEntry
+		if entry, ok := snapshot.Entries[key]; ok {
+			prev = &entry
+		}
+
+		switch s.bidirectionalAction(src, dst, prev) {
+		case syncToDestination:
+			emit(copyCommand(src.URL, dsturl))
+		case syncToSource:
+			emit(copyCommand(dst.URL, srcurl))
+		case syncDeleteDestination:
+			dstDeletes = append(dstDeletes, dst.URL)
+		case syncDeleteSource:
+			srcDeletes = append(srcDeletes, src.URL)
+		case syncKeepBoth:
+			// the source version is kept with the original name and the
+			// destination version is kept with the suffix on both sides.
+			name := conflictName(key, s.conflictSuffix)
+			emit(copyCommand(dst.URL, srcRoot.Join(name)))
+			emit(copyCommand(dst.URL, dstRoot.Join(name)))
+			if command := copyCommand(src.URL, dsturl); command != "" {
+				plan.deferred = append(plan.deferred, command)
+			}
+		case syncConflict:
+			plan.conflicts[key] = struct{}{}
+			err := &errorpkg.Error{
+				Op:  s.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: errorpkg.ErrObjectConflict,
+			}
+			printError(s.fullCommand, s.op, err)
+			plan.err = multierror.Append(plan.err, err)
+		}
+	}
+
+	removeFlags := map[string]interface{}{
+		"raw": true,
+	}
+	for _, urls := range [][]*url.URL{srcDeletes, dstDeletes} {
+		if len(urls) == 0 {
+			continue
+		}
+
+		command, err := generateCommand(c, "rm", removeFlags, urls...)
+		if err != nil {
+			printDebug(s.op, err, urls...)
+			continue
+		}
+		emit(command)
+	}
+
+	return plan
+}
+
+// updateSnapshot lists both sides after a sync and records the objects which
+// exist on both sides. Conflicting objects keep their previous state, so that
+// they are reported again until they are resolved.
+func (s Sync) updateSnapshot(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	snapshot *SyncSnapshot,
+	conflicts map[string]struct{},
+	srcurl, dsturl *url.URL,
+) error {
+	sourceObjects, destObjects, err := s.getSourceAndDestinationObjects(ctx, cancel, srcurl, dsturl)
+	if err != nil {
+		return err
+	}
+
+	entries := map[string]SyncSnapshotEntry{}
+	for key := range conflicts {
+		if entry, ok := snapshot.Entries[key]; ok {
+			entries[key] = entry
+		}
+	}
+
+	for pair := range joinObjects(sourceObjects, destObjects) {
+		key := syncKey(pair)
+		if _, ok := conflicts[key]; ok {
+			continue
+		}
+
+		// objects which are created on both sides during the sync are not
+		// in sync, leave them to the next sync to be detected as conflicts.
+		if pair.src == nil || pair.dst == nil || pair.src.Size != pair.dst.Size {
+			continue
+		}
+
+		entries[key] = SyncSnapshotEntry{
+			Source:      *newSyncObjectState(pair.src),
+			Destination: *newSyncObjectState(pair.dst),
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	snapshot.Entries = entries
+	return snapshot.Save()
+}
+
+// syncKey returns the path of the object in the pair relative to the root of
+// its side.
+func syncKey(pair *ObjectPair) string {
+	if pair.src != nil {
+		return filepath.ToSlash(pair.src.URL.Relative())
+	}
+	return filepath.ToSlash(pair.dst.URL.Relative())
+}
+
+// joinObjects joins source and destination objects by their relative paths.
+// It assumes that sourceObjects and destObjects channels are already sorted in
+// ascending order. Either side of a pair is nil if the object exists only on
+// the other side. Directory objects are skipped.
+func joinObjects(sourceObjects, destObjects chan *storage.Object) chan *ObjectPair {
+	pairs := make(chan *ObjectPair, extsortChannelBufferSize)
+
+	next := func(ch chan *storage.Object) (*storage.Object, bool) {
+		for obj := range ch {
+			if !obj.Type.IsDir() {
+				return obj, true
+			}
+		}
+		return nil, false
+	}
+
+	go func() {
+		defer close(pairs)
+
+		src, srcOk := next(sourceObjects)
+		dst, dstOk := next(destObjects)
+
+		for srcOk || dstOk {
+			var srcName, dstName string
+			if srcOk {
+				srcName = filepath.ToSlash(src.URL.Relative())
+			}
+			if dstOk {
+				dstName = filepath.ToSlash(dst.URL.Relative())
+			}
+
+			switch {
+			case srcOk && dstOk && srcName == dstName:
+				pairs <- &ObjectPair{src: src, dst: dst}
+				src, srcOk = next(sourceObjects)
+				dst, dstOk = next(destObjects)
+			case srcOk && (!dstOk || srcName < dstName):
+				pairs <- &ObjectPair{src: src}
+				src, srcOk = next(sourceObjects)
+			default:
+				pairs <- &ObjectPair{dst: dst}
+				dst, dstOk = next(destObjects)
+			}
+		}
+	}()
+
+	return pairs
+}
+
+// syncRoots returns the roots of both sides of a bidirectional sync, which
+// the relative paths of the objects are joined to.
+func syncRoots(src, dst string, raw bool) (*url.URL, *url.URL, error) {
+	srcRoot, err := url.New(strings.TrimSuffix(src, "*"), url.WithRaw(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !strings.HasSuffix(dst, "/") {
+		dst += "/"
+	}
+	dstRoot, err := url.New(dst, url.WithRaw(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return srcRoot, dstRoot, nil
+}
+
+func validateBidirectionalSync(c *cli.Context) error {
+	for _, flag := range []string{"delete", "size-only", "checksum"} {
+		if c.Bool(flag) {
+			return fmt.Errorf(`"bidirectional" and %q flags cannot be used together`, flag)
+		}
+	}
+
+	src := c.Args().Get(0)
+	srcurl, err := url.New(src, url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if srcurl.IsRemote() {
+		prefix := strings.TrimSuffix(src, "*")
+		if !strings.HasSuffix(src, "/*") || strings.ContainsAny(prefix, "*?") {
+			return fmt.Errorf(`source %q must be a prefix ending with "/*" for bidirectional sync`, src)
+		}
+		return nil
+	}
+
+	if srcurl.IsWildcard() {
+		return fmt.Errorf("source %q must be a directory for bidirectional sync", src)
+	}
+
+	fi, err := os.Stat(srcurl.Absolute())
+	if err == nil && !fi.IsDir() {
+		return fmt.Errorf("source %q must be a directory for bidirectional sync", src)
+	}
+	return nil
+}
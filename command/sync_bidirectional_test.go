@@ -0,0 +1,188 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestSync_BidirectionalAction(t *testing.T) {
+	ft := time.Now().UTC()
+
+	object := func(rawurl, etag string, size int64, modTime time.Time) *storage.Object {
+		u, err := url.New(rawurl)
+		assert.NilError(t, err)
+		return &storage.Object{URL: u, Etag: etag, Size: size, ModTime: &modTime}
+	}
+	state := func(etag string, size int64, modTime time.Time) SyncObjectState {
+		return SyncObjectState{ETag: etag, Size: size, ModTime: modTime}
+	}
+
+	synced := &SyncSnapshotEntry{
+		Source:      state("a", 10, ft),
+		Destination: state("a", 10, ft),
+	}
+
+	testcases := []struct {
+		name     string
+		src      *storage.Object
+		dst      *storage.Object
+		prev     *SyncSnapshotEntry
+		conflict string
+		expected syncAction
+	}{
+		{
+			name:     "new on source",
+			src:      object("s3://src/key", "a", 10, ft),
+			expected: syncToDestination,
+		},
+		{
+			name:     "new on destination",
+			dst:      object("s3://dst/key", "a", 10, ft),
+			expected: syncToSource,
+		},
+		{
+			name:     "new on both sides with the same content",
+			src:      object("s3://src/key", "a", 10, ft),
+			dst:      object("s3://dst/key", "a", 10, ft),
+			expected: syncNone,
+		},
+		{
+			name:     "new on both sides with different content",
+			src:      object("s3://src/key", "a", 10, ft),
+			dst:      object("s3://dst/key", "b", 10, ft),
+			conflict: conflictSkip,
+			expected: syncConflict,
+		},
+		{
+			name:     "not changed",
+			src:      object("s3://src/key", "a", 10, ft),
+			dst:      object("s3://dst/key", "a", 10, ft),
+			prev:     synced,
+			expected: syncNone,
+		},
+		{
+			name:     "changed on source",
+			src:      object("s3://src/key", "b", 10, ft),
+			dst:      object("s3://dst/key", "a", 10, ft),
+			prev:     synced,
+			expected: syncToDestination,
+		},
+		{
+			name:     "changed on destination",
+			src:      object("s3://src/key", "a", 10, ft),
+			dst:      object("s3://dst/key", "b", 12, ft),
+			prev:     synced,
+			expected: syncToSource,
+		},
+		{
+			name:     "deleted on source",
+			dst:      object("s3://dst/key", "a", 10, ft),
+			prev:     synced,
+			expected: syncDeleteDestination,
+		},
+		{
+			name:     "deleted on destination",
+			src:      object("s3://src/key", "a", 10, ft),
+			prev:     synced,
+			expected: syncDeleteSource,
+		},
+		{
+			name:     "deleted on both sides",
+			prev:     synced,
+			expected: syncNone,
+		},
+		{
+			name:     "changed on both sides, skip",
+			src:      object("s3://src/key", "b", 10, ft),
+			dst:      object("s3://dst/key", "c", 10, ft.Add(time.Minute)),
+			prev:     synced,
+			conflict: conflictSkip,
+			expected: syncConflict,
+		},
+		{
+			name:     "changed on both sides, newer wins",
+			src:      object("s3://src/key", "b", 10, ft),
+			dst:      object("s3://dst/key", "c", 10, ft.Add(time.Minute)),
+			prev:     synced,
+			conflict: conflictNewerWins,
+			expected: syncToSource,
+		},
+		{
+			name:     "changed on both sides, keep both",
+			src:      object("s3://src/key", "b", 10, ft),
+			dst:      object("s3://dst/key", "c", 10, ft.Add(time.Minute)),
+			prev:     synced,
+			conflict: conflictKeepBoth,
+			expected: syncKeepBoth,
+		},
+		{
+			name:     "changed on both sides to the same content",
+			src:      object("s3://src/key", "b", 10, ft),
+			dst:      object("s3://dst/key", "b", 10, ft.Add(time.Minute)),
+			prev:     synced,
+			conflict: conflictSkip,
+			expected: syncNone,
+		},
+		{
+			name:     "deleted on source, changed on destination, keep both",
+			dst:      object("s3://dst/key", "c", 10, ft),
+			prev:     synced,
+			conflict: conflictKeepBoth,
+			expected: syncToSource,
+		},
+		{
+			name:     "changed on source, deleted on destination, skip",
+			src:      object("s3://src/key", "b", 10, ft),
+			prev:     synced,
+			conflict: conflictSkip,
+			expected: syncConflict,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s := Sync{conflict: tc.conflict}
+			assert.Equal(t, s.bidirectionalAction(tc.src, tc.dst, tc.prev), tc.expected)
+		})
+	}
+}
+
+func TestConflictName(t *testing.T) {
+	testcases := []struct {
+		key      string
+		expected string
+	}{
+		{key: "file.txt", expected: "file-conflict.txt"},
+		{key: "dir/file.tar.gz", expected: "dir/file.tar-conflict.gz"},
+		{key: "dir/file", expected: "dir/file-conflict"},
+		{key: "dir/.hidden", expected: "dir/.hidden-conflict"},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, conflictName(tc.key, "-conflict"), tc.expected)
+	}
+}
+
+func TestLockSyncSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync", "snapshot.json")
+
+	unlock, err := lockSyncSnapshot(path)
+	assert.NilError(t, err)
+
+	// an overlapping sync of the same roots fails.
+	_, err = lockSyncSnapshot(path)
+	assert.ErrorContains(t, err, "another sync of the same source and destination is running")
+
+	unlock()
+
+	unlock, err = lockSyncSnapshot(path)
+	assert.NilError(t, err)
+	unlock()
+}
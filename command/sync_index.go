@@ -0,0 +1,478 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+const (
+	syncIndexVersion = 1
+
+	// syncIndexSampleSize is the number of objects in the index which are
+	// compared with the destination to validate the index.
+	syncIndexSampleSize = 16
+)
+
+// syncIndexHeader is the first line of a sync index file.
+type syncIndexHeader struct {
+	Version     int       `json:"version"`
+	Destination string    `json:"destination"`
+	Count       int64     `json:"count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// syncIndexEntry is an object in the destination listing. Entries follow the
+// header line, sorted by their keys.
+type syncIndexEntry struct {
+	Key string `json:"key"`
+	SyncObjectState
+}
+
+// syncIndex is the last known listing of the destination of a sync. It is
+// used instead of listing the destination as long as it is consistent with
+// the destination, and it is updated with the objects the sync changes.
+type syncIndex struct {
+	path string
+	// base is the url which the destination is listed with. Keys are relative
+	// to it.
+	base *url.URL
+
+	// listing is the path of the file the destination listing is recorded to
+	// when the index cannot be used.
+	listing string
+	writer  *syncIndexWriter
+
+	mu      sync.Mutex
+	touched map[string]*url.URL
+}
+
+func newSyncIndex(path string, base *url.URL) *syncIndex {
+	return &syncIndex{
+		path:    path,
+		base:    base,
+		touched: map[string]*url.URL{},
+	}
+}
+
+// valid reports whether the index can be used instead of listing the
+// destination. The index is valid if it belongs to the destination and a
+// sample of its objects is the same as the destination objects. Objects
+// changed by other tools are not detected unless they are sampled, thus the
+// index is meant to be used for destinations which are only changed by sync.
+func (x *syncIndex) valid(ctx context.Context, client storage.Storage) bool {
+	header, samples, err := x.samples()
+	if errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	if err != nil {
+		printDebug("sync", fmt.Errorf("ignoring sync index %q: %w", x.path, err))
+		return false
+	}
+
+	if header.Version != syncIndexVersion || header.Destination != syncRootName(x.base) {
+		printDebug("sync", fmt.Errorf("ignoring sync index %q of another destination", x.path))
+		return false
+	}
+
+	for _, entry := range samples {
+		dsturl := x.url(entry.Key)
+		obj, err := lookupObject(ctx, client, dsturl)
+		if err != nil {
+			printDebug("sync", fmt.Errorf("sync index is stale: %w", err), dsturl)
+			return false
+		}
+
+		if newSyncObjectState(obj).changed(&entry.SyncObjectState) {
+			printDebug("sync", fmt.Errorf("sync index is stale: object is changed"), dsturl)
+			return false
+		}
+	}
+	return true
+}
+
+// samples returns the header and evenly spaced entries of the index.
+func (x *syncIndex) samples() (*syncIndexHeader, []syncIndexEntry, error) {
+	r, err := openSyncIndexReader(x.path, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	step := r.header.Count/syncIndexSampleSize + 1
+
+	var (
+		samples []syncIndexEntry
+		last    *syncIndexEntry
+	)
+	for i := int64(0); ; i++ {
+		entry, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		last = entry
+		if i%step == 0 {
+			samples = append(samples, *entry)
+			last = nil
+		}
+	}
+	if last != nil {
+		samples = append(samples, *last)
+	}
+	return r.header, samples, nil
+}
+
+// objects sends the objects in the index to the given channel, in the order
+// of their keys.
+func (x *syncIndex) objects(ctx context.Context, objch chan *storage.Object) error {
+	r, err := openSyncIndexReader(x.path, true)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		entry, err := r.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case objch <- x.object(entry):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// record starts recording the destination listing, which becomes the index
+// after the sync.
+func (x *syncIndex) record() error {
+	x.listing = x.path + ".listing"
+
+	w, err := createSyncIndexWriter(x.listing)
+	if err != nil {
+		return err
+	}
+	x.writer = w
+	return nil
+}
+
+// add records an object of the destination listing.
+func (x *syncIndex) add(obj *storage.Object) {
+	if x.writer == nil || obj.Type.IsDir() {
+		return
+	}
+
+	entry := syncIndexEntry{Key: obj.URL.Relative(), SyncObjectState: *newSyncObjectState(obj)}
+	if err := x.writer.write(&entry); err != nil {
+		printDebug("sync", err, obj.URL)
+	}
+}
+
+// touch records the key of a destination object which is copied or deleted by
+// the sync.
+func (x *syncIndex) touch(key string, dsturl *url.URL) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.touched[key] = dsturl
+}
+
+// Update writes the index after the sync. The objects touched by the sync are
+// looked up on the destination, whether their operations succeeded or not.
+func (x *syncIndex) Update(ctx context.Context, client storage.Storage, numWorkers int) error {
+	base := x.path
+	if x.writer != nil {
+		if err := x.writer.Close(); err != nil {
+			return err
+		}
+		base = x.listing
+		defer os.Remove(x.listing)
+	}
+
+	updates := x.lookup(ctx, client, numWorkers)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r, err := openSyncIndexReader(base, x.listing == "")
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := createSyncIndexWriter(x.path + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(x.path + ".tmp")
+
+	if err := x.merge(r, w, updates); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	header := syncIndexHeader{
+		Version:     syncIndexVersion,
+		Destination: syncRootName(x.base),
+		Count:       w.count,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	return w.finalize(x.path, header)
+}
+
+// merge writes the entries read from r, replacing them with the given
+// updates. Both are sorted by their keys. Nil updates delete the entries.
+func (x *syncIndex) merge(r *syncIndexReader, w *syncIndexWriter, updates map[string]*syncIndexEntry) error {
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entry, err := r.next()
+	for {
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		eof := err == io.EOF
+		if eof && len(keys) == 0 {
+			return nil
+		}
+
+		if len(keys) == 0 || (!eof && entry.Key < keys[0]) {
+			if err := w.write(entry); err != nil {
+				return err
+			}
+			entry, err = r.next()
+			continue
+		}
+
+		key := keys[0]
+		keys = keys[1:]
+		if update := updates[key]; update != nil {
+			if err := w.write(update); err != nil {
+				return err
+			}
+		}
+		if !eof && entry.Key == key {
+			entry, err = r.next()
+		}
+	}
+}
+
+// lookup returns the current state of the touched objects on the
+// destination. Deleted objects have nil entries.
+func (x *syncIndex) lookup(ctx context.Context, client storage.Storage, numWorkers int) map[string]*syncIndexEntry {
+	var (
+		mu      sync.Mutex
+		updates = map[string]*syncIndexEntry{}
+	)
+
+	pm := parallel.New(numWorkers)
+	defer pm.Close()
+
+	waiter := parallel.NewWaiter()
+	for key, dsturl := range x.touched {
+		key, dsturl := key, dsturl
+		pm.Run(func() error {
+			// objects which cannot be found are deleted from the index. The
+			// ones which cannot be looked up due to other errors are deleted
+			// too, so that they are listed by the next sync.
+			var entry *syncIndexEntry
+			obj, err := lookupObject(ctx, client, dsturl)
+			if err == nil {
+				entry = &syncIndexEntry{Key: key, SyncObjectState: *newSyncObjectState(obj)}
+			}
+
+			mu.Lock()
+			updates[key] = entry
+			mu.Unlock()
+			return nil
+		}, waiter)
+	}
+	waiter.Wait()
+
+	return updates
+}
+
+// lookupObject returns the object in the given url as it is listed, since the
+// metadata returned by listing and stat operations may differ, e.g. S3 returns
+// modification times in milliseconds for listings but in seconds otherwise.
+func lookupObject(ctx context.Context, client storage.Storage, u *url.URL) (*storage.Object, error) {
+	// stop listing once the object is found.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range client.List(ctx, u, false) {
+		if obj.Err != nil {
+			if obj.Err == storage.ErrNoObjectFound {
+				break
+			}
+			return nil, obj.Err
+		}
+		if obj.URL.Absolute() == u.Absolute() {
+			return obj, nil
+		}
+	}
+	return nil, &storage.ErrGivenObjectNotFound{ObjectAbsPath: u.Absolute()}
+}
+
+// url returns the url of the destination object with the given key.
+func (x *syncIndex) url(key string) *url.URL {
+	u := x.base.Clone()
+	if u.IsRemote() {
+		u.Path = x.base.Prefix + filepath.ToSlash(key)
+	} else {
+		u.Path = filepath.Join(filepath.Dir(x.base.Absolute()), key)
+	}
+	u.SetRelative(x.base)
+	return u
+}
+
+// object returns the destination object of the given entry.
+func (x *syncIndex) object(entry *syncIndexEntry) *storage.Object {
+	modTime := entry.ModTime
+	return &storage.Object{
+		URL:     x.url(entry.Key),
+		Etag:    entry.ETag,
+		ModTime: &modTime,
+		Size:    entry.Size,
+	}
+}
+
+// syncIndexReader reads the entries of an index file, or of a file which has
+// the entries only.
+type syncIndexReader struct {
+	f      *os.File
+	dec    *json.Decoder
+	header *syncIndexHeader
+}
+
+func openSyncIndexReader(path string, hasHeader bool) (*syncIndexReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &syncIndexReader{
+		f:      f,
+		dec:    json.NewDecoder(bufio.NewReader(f)),
+		header: &syncIndexHeader{},
+	}
+	if hasHeader {
+		if err := r.dec.Decode(r.header); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *syncIndexReader) next() (*syncIndexEntry, error) {
+	var entry syncIndexEntry
+	if err := r.dec.Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *syncIndexReader) Close() error {
+	return r.f.Close()
+}
+
+// syncIndexWriter writes the entries of an index to a file. The header is
+// written when the index is finalized, since the number of entries is not
+// known until then.
+type syncIndexWriter struct {
+	f     *os.File
+	w     *bufio.Writer
+	enc   *json.Encoder
+	count int64
+}
+
+func createSyncIndexWriter(path string) (*syncIndexWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	return &syncIndexWriter{f: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (w *syncIndexWriter) write(entry *syncIndexEntry) error {
+	w.count++
+	return w.enc.Encode(entry)
+}
+
+func (w *syncIndexWriter) Close() error {
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// finalize writes the index to the given path atomically, with the given
+// header followed by the entries written so far. The writer must be closed.
+func (w *syncIndexWriter) finalize(path string, header syncIndexHeader) error {
+	body, err := os.Open(w.f.Name())
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.Create(path + ".new")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	bw := bufio.NewWriter(tmp)
+	if err := json.NewEncoder(bw).Encode(header); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(bw, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
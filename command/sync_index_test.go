@@ -0,0 +1,97 @@
+package command
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestSyncIndexMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	entry := func(key string, size int64) *syncIndexEntry {
+		return &syncIndexEntry{Key: key, SyncObjectState: SyncObjectState{Size: size}}
+	}
+
+	// write the previous index.
+	w, err := createSyncIndexWriter(filepath.Join(dir, "index.body"))
+	assert.NilError(t, err)
+	for _, e := range []*syncIndexEntry{entry("a", 1), entry("c", 1), entry("d", 1), entry("f", 1)} {
+		assert.NilError(t, w.write(e))
+	}
+	assert.NilError(t, w.Close())
+	assert.NilError(t, w.finalize(filepath.Join(dir, "index"), syncIndexHeader{Version: syncIndexVersion, Count: w.count}))
+
+	base, err := url.New("s3://bucket/*")
+	assert.NilError(t, err)
+	x := newSyncIndex(filepath.Join(dir, "index"), base)
+
+	r, err := openSyncIndexReader(x.path, true)
+	assert.NilError(t, err)
+	defer r.Close()
+	assert.Equal(t, r.header.Count, int64(4))
+
+	out, err := createSyncIndexWriter(filepath.Join(dir, "merged"))
+	assert.NilError(t, err)
+
+	updates := map[string]*syncIndexEntry{
+		"0": entry("0", 2), // added before all
+		"b": entry("b", 2), // added in between
+		"c": entry("c", 2), // changed
+		"d": nil,           // deleted
+		"g": entry("g", 2), // added after all
+		"h": nil,           // deleted, but not in the index
+	}
+	assert.NilError(t, x.merge(r, out, updates))
+	assert.NilError(t, out.Close())
+
+	merged, err := openSyncIndexReader(filepath.Join(dir, "merged"), false)
+	assert.NilError(t, err)
+	defer merged.Close()
+
+	var got []syncIndexEntry
+	for {
+		e, err := merged.next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+		got = append(got, *e)
+	}
+
+	expected := []syncIndexEntry{
+		*entry("0", 2),
+		*entry("a", 1),
+		*entry("b", 2),
+		*entry("c", 2),
+		*entry("f", 1),
+		*entry("g", 2),
+	}
+	assert.DeepEqual(t, got, expected)
+	assert.Equal(t, out.count, int64(len(expected)))
+}
+
+func TestSyncIndexURL(t *testing.T) {
+	testcases := []struct {
+		base     string
+		key      string
+		expected string
+	}{
+		{base: "s3://bucket/*", key: "a/b.txt", expected: "s3://bucket/a/b.txt"},
+		{base: "s3://bucket/prefix/*", key: "b.txt", expected: "s3://bucket/prefix/b.txt"},
+		{base: "dir/*", key: "a/b.txt", expected: "dir/a/b.txt"},
+	}
+
+	for _, tc := range testcases {
+		base, err := url.New(tc.base)
+		assert.NilError(t, err)
+
+		u := newSyncIndex("", base).url(tc.key)
+		assert.Equal(t, u.String(), tc.expected)
+		assert.Equal(t, filepath.ToSlash(u.Relative()), tc.key)
+	}
+}
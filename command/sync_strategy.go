@@ -1,6 +1,14 @@
 package command
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
 	errorpkg "github.com/peak/s5cmd/v2/error"
 	"github.com/peak/s5cmd/v2/storage"
 )
@@ -11,7 +19,10 @@ type SyncStrategy interface {
 	ShouldSync(srcObject, dstObject *storage.Object) error
 }
 
-func NewStrategy(sizeOnly bool) SyncStrategy {
+func NewStrategy(sizeOnly, checksum bool, partSize int64) SyncStrategy {
+	if checksum {
+		return &ChecksumStrategy{partSize: partSize}
+	}
 	if sizeOnly {
 		return &SizeOnlyStrategy{}
 	} else {
@@ -50,3 +61,143 @@ func (sm *SizeAndModificationStrategy) ShouldSync(srcObj, dstObj *storage.Object
 
 	return errorpkg.ErrObjectIsNewerAndSizesMatch
 }
+
+// ChecksumStrategy determines to sync based on objects' content hashes,
+// regardless of their modification times. Local files are hashed the same
+// way S3 computes the ETag of the remote object they are compared to: the MD5
+// of the content for single-part uploads and the MD5 of the concatenated part
+// MD5s for multipart uploads.
+//
+// Objects are synced when the hashes cannot be compared, e.g. both sides are
+// remote and one of them is a multipart upload while the other is not.
+type ChecksumStrategy struct {
+	// partSize is the part size tried first to recompute the ETag of a
+	// multipart upload, before the one derived from the part count.
+	partSize int64
+}
+
+func (cs *ChecksumStrategy) ShouldSync(srcObj, dstObj *storage.Object) error {
+	if srcObj.Size != dstObj.Size {
+		return nil
+	}
+
+	var match bool
+	switch srcIsRemote, dstIsRemote := srcObj.URL.IsRemote(), dstObj.URL.IsRemote(); {
+	case srcIsRemote && dstIsRemote:
+		match = srcObj.Etag != "" && srcObj.Etag == dstObj.Etag
+	case dstIsRemote:
+		match = cs.fileMatchesETag(srcObj.URL.Absolute(), dstObj.Etag, srcObj.Size)
+	case srcIsRemote:
+		match = cs.fileMatchesETag(dstObj.URL.Absolute(), srcObj.Etag, dstObj.Size)
+	}
+
+	if !match {
+		return nil
+	}
+	return errorpkg.ErrObjectChecksumsMatch
+}
+
+// fileMatchesETag reports whether the content of the file in the given path
+// produces the given remote ETag. Files that cannot be read never match.
+func (cs *ChecksumStrategy) fileMatchesETag(path, etag string, size int64) bool {
+	if etag == "" {
+		return false
+	}
+
+	parts, err := etagPartCount(etag)
+	if err != nil {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if parts == 0 {
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return false
+		}
+		return hex.EncodeToString(h.Sum(nil)) == etag
+	}
+
+	// the part size of a multipart upload is not known. Try the configured
+	// part size first since the object is likely uploaded with it, then the
+	// part size derived from the part count.
+	for _, partSize := range []int64{cs.partSize, multipartPartSize(size, parts)} {
+		if partSize <= 0 || (size+partSize-1)/partSize != int64(parts) {
+			continue
+		}
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+
+		sum, err := multipartETag(f, partSize)
+		if err != nil {
+			return false
+		}
+		if sum == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// multipartETag computes the ETag that S3 assigns to an object uploaded in
+// parts of the given size: the MD5 of the concatenated part MD5s, followed by
+// the number of parts.
+func multipartETag(r io.Reader, partSize int64) (string, error) {
+	var (
+		sums  []byte
+		parts int
+	)
+	for {
+		h := md5.New()
+		n, err := io.CopyN(h, r, partSize)
+		if n > 0 {
+			sums = h.Sum(sums)
+			parts++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), parts), nil
+}
+
+// multipartPartSize finds the part size that splits an object of the given
+// size into the given number of parts. Uploaders use MiB-aligned part sizes,
+// so the smallest MiB-aligned part size is preferred if it yields the same
+// number of parts.
+func multipartPartSize(size int64, parts int) int64 {
+	partSize := (size + int64(parts) - 1) / int64(parts)
+
+	aligned := (partSize + megabytes - 1) / megabytes * megabytes
+	if (size+aligned-1)/aligned == int64(parts) {
+		return aligned
+	}
+	return partSize
+}
+
+// etagPartCount returns the number of parts of a multipart ETag, or 0 if the
+// ETag belongs to an object uploaded in a single part.
+func etagPartCount(etag string) (int, error) {
+	_, count, ok := strings.Cut(etag, "-")
+	if !ok {
+		return 0, nil
+	}
+
+	parts, err := strconv.Atoi(count)
+	if err != nil || parts < 1 {
+		return 0, fmt.Errorf("invalid multipart etag %q", etag)
+	}
+	return parts, nil
+}
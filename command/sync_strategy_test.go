@@ -1,11 +1,19 @@
 package command
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"testing"
 	"time"
 
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+
 	errorpkg "github.com/peak/s5cmd/v2/error"
 	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
 )
 
 func TestSizeAndModificationStrategy_ShouldSync(t *testing.T) {
@@ -147,3 +155,159 @@ func TestSizeOnlyStrategy_ShouldSync(t *testing.T) {
 		})
 	}
 }
+
+func TestChecksumStrategy_ShouldSync(t *testing.T) {
+	t.Parallel()
+
+	ft := time.Now()
+	timePtr := func(tt time.Time) *time.Time {
+		return &tt
+	}
+
+	content := "this is a test file"
+	file := fs.NewFile(t, "", fs.WithContent(content))
+	defer file.Remove()
+
+	newURL := func(s string) *url.URL {
+		u, err := url.New(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	md5sum := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	multipartSum := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		sum = md5.Sum(sum[:])
+		return hex.EncodeToString(sum[:]) + "-1"
+	}
+
+	size := int64(len(content))
+
+	testcases := []struct {
+		name     string
+		src      *storage.Object
+		dst      *storage.Object
+		expected error
+	}{
+		{
+			name:     "sizes are different",
+			src:      &storage.Object{URL: newURL(file.Path()), ModTime: timePtr(ft), Size: size},
+			dst:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft), Size: 5, Etag: md5sum(content)},
+			expected: nil,
+		},
+		{
+			name:     "local file matches remote etag, source is older",
+			src:      &storage.Object{URL: newURL(file.Path()), ModTime: timePtr(ft), Size: size},
+			dst:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft.Add(time.Minute)), Size: size, Etag: md5sum(content)},
+			expected: errorpkg.ErrObjectChecksumsMatch,
+		},
+		{
+			name:     "local file does not match remote etag, source is older",
+			src:      &storage.Object{URL: newURL(file.Path()), ModTime: timePtr(ft), Size: size},
+			dst:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft.Add(time.Minute)), Size: size, Etag: md5sum("this is a TEST file")},
+			expected: nil,
+		},
+		{
+			name:     "remote etag matches local file",
+			src:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft.Add(time.Minute)), Size: size, Etag: md5sum(content)},
+			dst:      &storage.Object{URL: newURL(file.Path()), ModTime: timePtr(ft), Size: size},
+			expected: errorpkg.ErrObjectChecksumsMatch,
+		},
+		{
+			name:     "remote etags match",
+			src:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft.Add(time.Minute)), Size: size, Etag: md5sum(content)},
+			dst:      &storage.Object{URL: newURL("s3://bucket2/key"), ModTime: timePtr(ft), Size: size, Etag: md5sum(content)},
+			expected: errorpkg.ErrObjectChecksumsMatch,
+		},
+		{
+			name:     "single part and multipart remote etags cannot be compared",
+			src:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft), Size: size, Etag: md5sum(content)},
+			dst:      &storage.Object{URL: newURL("s3://bucket2/key"), ModTime: timePtr(ft), Size: size, Etag: md5sum(content) + "-2"},
+			expected: nil,
+		},
+		{
+			name:     "local file matches multipart remote etag",
+			src:      &storage.Object{URL: newURL(file.Path()), ModTime: timePtr(ft), Size: size},
+			dst:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft), Size: size, Etag: multipartSum(content)},
+			expected: errorpkg.ErrObjectChecksumsMatch,
+		},
+		{
+			name:     "local file does not exist",
+			src:      &storage.Object{URL: newURL(file.Path() + "-missing"), ModTime: timePtr(ft), Size: size},
+			dst:      &storage.Object{URL: newURL("s3://bucket/key"), ModTime: timePtr(ft), Size: size, Etag: md5sum(content)},
+			expected: nil,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			strategy := &ChecksumStrategy{partSize: defaultPartSize * megabytes}
+			if got := strategy.ShouldSync(tc.src, tc.dst); got != tc.expected {
+				t.Fatalf("expected: %q(%T), got: %q(%T)", tc.expected, tc.expected, got, got)
+			}
+		})
+	}
+}
+
+func TestMultipartETag(t *testing.T) {
+	t.Parallel()
+
+	const partSize = 5 * megabytes
+	content := bytes.Repeat([]byte("s5cmd"), 3*megabytes) // 15 MiB
+
+	var sums []byte
+	for i := 0; i < len(content); i += partSize {
+		sum := md5.Sum(content[i : i+partSize])
+		sums = append(sums, sum[:]...)
+	}
+	sum := md5.Sum(sums)
+	expected := fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), 3)
+
+	got, err := multipartETag(bytes.NewReader(content), multipartPartSize(int64(len(content)), 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestMultipartPartSize(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		size     int64
+		parts    int
+		expected int64
+	}{
+		{
+			name:     "last part is smaller",
+			size:     110 * megabytes,
+			parts:    3,
+			expected: 37 * megabytes,
+		},
+		{
+			name:     "all parts are equal",
+			size:     100 * megabytes,
+			parts:    2,
+			expected: 50 * megabytes,
+		},
+		{
+			name:     "single part",
+			size:     1024,
+			parts:    1,
+			expected: megabytes,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, multipartPartSize(tc.size, tc.parts))
+		})
+	}
+}
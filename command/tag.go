@@ -0,0 +1,318 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/parallel"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+var tagHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} command [command options] [arguments...]
+
+Commands:
+	{{range .VisibleCommands}}{{join .Names ", "}}{{"\t"}}{{.Usage}}
+	{{end}}
+Examples:
+	1. Print the tags of an object
+		 > s5cmd {{.HelpName}} get s3://bucket/prefix/object.gz
+
+	2. Replace the tags of all objects with a prefix
+		 > s5cmd {{.HelpName}} set --tag "project=s5cmd" --tag "retention=30d" "s3://bucket/prefix/*"
+
+	3. Remove the tags of all objects that matches a wildcard
+		 > s5cmd {{.HelpName}} delete "s3://bucket/*/obj*.gz"
+`
+
+var tagGetHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Print the tags of an object
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Print the tags of the specific version of an object
+		 > s5cmd {{.HelpName}} --version-id VERSION_ID s3://bucket/prefix/object.gz
+
+	3. Print the tags of all objects with a prefix
+		 > s5cmd {{.HelpName}} "s3://bucket/prefix/*"
+`
+
+var tagSetHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Replace the tags of an object
+		 > s5cmd {{.HelpName}} --tag "project=s5cmd" --tag "retention=30d" s3://bucket/prefix/object.gz
+
+	2. Replace the tags of the specific version of an object
+		 > s5cmd {{.HelpName}} --tag "project=s5cmd" --version-id VERSION_ID s3://bucket/prefix/object.gz
+
+	3. Replace the tags of all objects with a prefix
+		 > s5cmd {{.HelpName}} --tag "project=s5cmd" "s3://bucket/prefix/*"
+`
+
+var tagDeleteHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Remove the tags of an object
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Remove the tags of all objects that matches a wildcard
+		 > s5cmd {{.HelpName}} "s3://bucket/*/obj*.gz"
+`
+
+func NewTagCommand() *cli.Command {
+	newSubcommand := func(name, usage, helpTemplate string, flags ...cli.Flag) *cli.Command {
+		return &cli.Command{
+			Name:     name,
+			HelpName: "tag " + name,
+			Usage:    usage,
+			Flags: append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:  "raw",
+					Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
+				},
+				&cli.StringFlag{
+					Name:  "version-id",
+					Usage: "use the specified version of an object",
+				},
+			}, flags...),
+			CustomHelpTemplate: helpTemplate,
+			Before: func(c *cli.Context) error {
+				err := validateTagCommand(c)
+				if err != nil {
+					printError(commandFromContext(c), c.Command.Name, err)
+				}
+				return err
+			},
+			Action: func(c *cli.Context) (err error) {
+				defer stat.Collect(c.Command.FullName(), &err)()
+
+				tag, err := NewTag(c)
+				if err != nil {
+					return err
+				}
+				return tag.Run(c.Context)
+			},
+		}
+	}
+
+	cmd := &cli.Command{
+		Name:               "tag",
+		HelpName:           "tag",
+		Usage:              "manage object tags",
+		CustomHelpTemplate: tagHelpTemplate,
+		Subcommands: []*cli.Command{
+			newSubcommand("get", "print the tags of objects", tagGetHelpTemplate),
+			newSubcommand("set", "replace the tags of objects", tagSetHelpTemplate,
+				&MapFlag{
+					Name:  "tag",
+					Usage: "set the tag of the objects, e.g. --tag 'project=s5cmd' --tag 'retention=30d'",
+				},
+			),
+			newSubcommand("delete", "remove the tags of objects", tagDeleteHelpTemplate),
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// Tag holds tag operation flags and states.
+type Tag struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// tags are the tags to set
+	tags map[string]string
+
+	storageOpts storage.Options
+}
+
+// NewTag creates Tag from cli.Context.
+func NewTag(c *cli.Context) (*Tag, error) {
+	fullCommand := commandFromContext(c)
+
+	src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+		url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		printError(fullCommand, c.Command.Name, err)
+		return nil, err
+	}
+
+	var tags map[string]string
+	if c.Command.Name == "set" {
+		tags, _ = c.Value("tag").(MapValue)
+	}
+
+	return &Tag{
+		src:         src,
+		op:          c.Command.Name,
+		fullCommand: fullCommand,
+		tags:        tags,
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// Run gets, sets or deletes the tags of the source objects.
+func (t Tag) Run(ctx context.Context) error {
+	client, err := storage.NewTaggingStorage(ctx, t.src, t.storageOpts)
+	if err != nil {
+		printError(t.fullCommand, t.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, t.src)
+	if err != nil {
+		printError(t.fullCommand, t.op, err)
+		return err
+	}
+
+	var (
+		merrorWaiter  error
+		merrorObjects error
+	)
+
+	waiter := parallel.NewWaiter()
+	errDoneCh := make(chan bool)
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(t.fullCommand, t.op, err)
+			merrorWaiter = multierror.Append(merrorWaiter, err)
+		}
+	}()
+
+	for object := range objch {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(t.fullCommand, t.op, err)
+			continue
+		}
+
+		parallel.Run(t.prepareTask(ctx, client, object.URL), waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return multierror.Append(merrorWaiter, merrorObjects).ErrorOrNil()
+}
+
+func (t Tag) prepareTask(ctx context.Context, client storage.TaggingStorage, objurl *url.URL) func() error {
+	return func() error {
+		switch t.op {
+		case "get":
+			tags, err := client.GetTags(ctx, objurl)
+			if err != nil {
+				return err
+			}
+			log.Info(TagMessage{URL: objurl, Tags: tags})
+			return nil
+		case "set":
+			if err := client.SetTags(ctx, objurl, t.tags); err != nil {
+				return err
+			}
+		case "delete":
+			if err := client.DeleteTags(ctx, objurl); err != nil {
+				return err
+			}
+		}
+
+		log.Info(log.InfoMessage{
+			Operation: t.op,
+			Source:    objurl,
+		})
+		return nil
+	}
+}
+
+func validateTagCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote object url")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithVersion(c.String("version-id")),
+		url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() || src.IsPrefix() {
+		return fmt.Errorf("remote source must be an object")
+	}
+
+	if c.Command.Name == "set" && !c.IsSet("tag") {
+		return fmt.Errorf(`"tag" flag is required`)
+	}
+
+	return checkVersioningWithGoogleEndpoint(c)
+}
+
+// TagMessage is a structure for logging the tags of objects.
+type TagMessage struct {
+	URL  *url.URL          `json:"key"`
+	Tags map[string]string `json:"tags"`
+}
+
+// String returns the string representation of TagMessage.
+func (t TagMessage) String() string {
+	pairs := make([]string, 0, len(t.Tags))
+	for k, v := range t.Tags {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", k, v))
+	}
+	sort.Strings(pairs)
+
+	if len(pairs) == 0 {
+		return t.URL.String()
+	}
+	return fmt.Sprintf("%v %v", t.URL, strings.Join(pairs, " "))
+}
+
+// JSON returns the JSON representation of TagMessage.
+func (t TagMessage) JSON() string {
+	return strutil.JSON(t)
+}
@@ -0,0 +1,196 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/v2/error"
+	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/log/stat"
+	"github.com/peak/s5cmd/v2/storage"
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+var undeleteHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Undelete an object of a versioned bucket
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Undelete all objects with a prefix
+		 > s5cmd {{.HelpName}} "s3://bucket/prefix/*"
+
+	3. Undelete the objects that matches a wildcard which are deleted after a point in time
+		 > s5cmd {{.HelpName}} --after 2026-09-01T00:00:00Z "s3://bucket/*.gz"
+
+	4. Print the delete markers that would be removed
+		 > s5cmd --dry-run {{.HelpName}} "s3://bucket/prefix/*"
+`
+
+func NewUndeleteCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:     "undelete",
+		HelpName: "undelete",
+		Usage:    "restore deleted objects of versioned buckets by removing their delete markers",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "raw",
+				Usage: "disable the wildcard operations, useful with filenames that contains glob characters",
+			},
+			&cli.StringFlag{
+				Name:  "after",
+				Usage: "only undelete the objects which are deleted after the given time (uses RFC3339 format), e.g. --after 2026-09-01T00:00:00Z",
+			},
+		},
+		CustomHelpTemplate: undeleteHelpTemplate,
+		Before: func(c *cli.Context) error {
+			err := validateUndeleteCommand(c)
+			if err != nil {
+				printError(commandFromContext(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			fullCommand := commandFromContext(c)
+
+			src, err := url.New(c.Args().Get(0), url.WithRaw(c.Bool("raw")),
+				url.WithAllVersions(true))
+			if err != nil {
+				printError(fullCommand, c.Command.Name, err)
+				return err
+			}
+
+			// the time is validated before.
+			var after time.Time
+			if c.IsSet("after") {
+				after, _ = time.Parse(time.RFC3339, c.String("after"))
+			}
+
+			return Undelete{
+				src:         src,
+				op:          c.Command.Name,
+				fullCommand: fullCommand,
+				after:       after,
+				storageOpts: NewStorageOpts(c),
+			}.Run(c.Context)
+		},
+	}
+
+	cmd.BashComplete = getBashCompleteFn(cmd, true, false)
+	return cmd
+}
+
+// Undelete holds undelete operation flags and states.
+type Undelete struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	after time.Time
+
+	storageOpts storage.Options
+}
+
+// Run removes the delete markers which are the latest versions of the source
+// objects, which makes their previous versions current.
+func (u Undelete) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, u.src, u.storageOpts)
+	if err != nil {
+		printError(u.fullCommand, u.op, err)
+		return err
+	}
+
+	// the latest version of an object is only known once all of its
+	// versions are listed.
+	latest := map[string]*storage.Object{}
+	var merrorObjects error
+	for object := range client.List(ctx, u.src, false) {
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			merrorObjects = multierror.Append(merrorObjects, err)
+			printError(u.fullCommand, u.op, err)
+			continue
+		}
+
+		key := object.URL.Path
+		if current, ok := latest[key]; !ok || object.ModTime.After(*current.ModTime) {
+			latest[key] = object
+		}
+	}
+
+	urlch := make(chan *url.URL)
+	go func() {
+		defer close(urlch)
+
+		for _, object := range latest {
+			if !object.DeleteMarker || !object.ModTime.After(u.after) {
+				continue
+			}
+			urlch <- object.URL
+		}
+	}()
+
+	var merrorResult error
+	for obj := range client.MultiDelete(ctx, urlch) {
+		if err := obj.Err; err != nil {
+			if errorpkg.IsCancelation(obj.Err) {
+				continue
+			}
+
+			merrorResult = multierror.Append(merrorResult, obj.Err)
+			printError(u.fullCommand, u.op, obj.Err)
+			continue
+		}
+
+		log.Info(log.InfoMessage{
+			Operation: u.op,
+			Source:    obj.URL,
+		})
+	}
+
+	return multierror.Append(merrorResult, merrorObjects).ErrorOrNil()
+}
+
+func validateUndeleteCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected remote object url")
+	}
+
+	src, err := url.New(c.Args().Get(0), url.WithRaw(c.Bool("raw")))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if src.IsBucket() {
+		return fmt.Errorf("remote source must be an object or a prefix")
+	}
+
+	if c.IsSet("after") {
+		if _, err := time.Parse(time.RFC3339, c.String("after")); err != nil {
+			return fmt.Errorf("invalid time %q: must be in RFC3339 format", c.String("after"))
+		}
+	}
+
+	return checkVersioningWithGoogleEndpoint(c)
+}
@@ -312,3 +312,54 @@ func TestAppEndpointShouldHaveScheme(t *testing.T) {
 		})
 	}
 }
+
+func TestAppInvalidLimitRate(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	cmd := s5cmd("--limit-rate", "fast")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR bad value for --limit-rate: invalid rate "fast", expected a positive value such as 50MB/s`),
+	})
+}
+
+func TestAppNumWorkersAuto(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	cmd := s5cmd("--numworkers", "auto", "--stat", "cp", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	out := result.Stdout()
+	assert.Assert(t, strings.Contains(out, "Gauge"), out)
+	assert.Assert(t, strings.Contains(out, "concurrency\t"), out)
+}
+
+func TestAppInvalidNumWorkers(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	cmd := s5cmd("--numworkers", "many", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`Incorrect Usage: invalid value "many" for flag -numworkers: expected a number or "auto"`),
+		1: equals("See 's5cmd --help' for usage"),
+	})
+}
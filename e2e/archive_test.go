@@ -0,0 +1,215 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestArchiveFlagsValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "unsupported format",
+			args:     []string{"cp", "--archive", "zip", "dir/", "s3://bucket/backup.zip"},
+			expected: `ERROR "cp --archive=zip dir/ s3://bucket/backup.zip": unsupported archive format "zip": must be tar, tar.gz or tar.zst`,
+		},
+		{
+			name:     "prefix destination",
+			args:     []string{"cp", "--archive", "tar", "dir/", "s3://bucket/backup/"},
+			expected: `ERROR "cp --archive=tar dir/ s3://bucket/backup/": target "s3://bucket/backup/" must be an object`,
+		},
+		{
+			name:     "download",
+			args:     []string{"cp", "--archive", "tar", "s3://bucket/*", "dir/"},
+			expected: `ERROR "cp --archive=tar s3://bucket/* dir/": "archive" flag is only supported for uploads`,
+		},
+		{
+			name:     "index of compressed archive",
+			args:     []string{"cp", "--archive", "tar.gz", "--archive-index", "dir/", "s3://bucket/backup.tar.gz"},
+			expected: `ERROR "cp --archive=tar.gz --archive-index=true dir/ s3://bucket/backup.tar.gz": "archive-index" flag is only supported for tar archives`,
+		},
+		{
+			name:     "index without archive",
+			args:     []string{"cp", "--archive-index", "dir/", "s3://bucket/"},
+			expected: `ERROR "cp --archive-index=true dir/ s3://bucket/": "archive-index" flag requires "archive" flag`,
+		},
+		{
+			name:     "extract to remote",
+			args:     []string{"extract", "s3://bucket/backup.tar", "s3://bucket/dir/"},
+			expected: `ERROR "extract s3://bucket/backup.tar s3://bucket/dir/": destination must be a local directory`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyArchiveAndExtract(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	srcdir := fs.NewDir(t, "archive",
+		fs.WithFile("a.txt", "content of a"),
+		fs.WithFile("b.log", "content of b"),
+		fs.WithDir("sub",
+			fs.WithFile("c.txt", "content of c"),
+		),
+	)
+
+	src := filepath.ToSlash(srcdir.Path())
+	dst := fmt.Sprintf("s3://%v/backup.tar", bucket)
+
+	cmd := s5cmd("cp", "--archive", "tar", "--archive-index", "--exclude", "*.log", src+"/", dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/ %v`, src, dst),
+	})
+
+	head, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("backup.tar"),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, aws.StringValue(head.ContentType), "application/x-tar")
+
+	_, err = s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("backup.tar.index.json"),
+	})
+	assert.NilError(t, err)
+
+	// extract the whole archive.
+	extractdir := fs.NewDir(t, "extract")
+	extracted := filepath.ToSlash(extractdir.Path())
+
+	cmd = s5cmd("extract", dst, extracted+"/")
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`extract %v %v/a.txt`, dst, extracted),
+		1: equals(`extract %v %v/sub/c.txt`, dst, extracted),
+	}, sortInput(true))
+
+	expected := fs.Expected(t,
+		fs.WithFile("a.txt", "content of a", fs.MatchAnyFileMode),
+		fs.WithDir("sub",
+			fs.WithFile("c.txt", "content of c", fs.MatchAnyFileMode),
+			fs.MatchAnyFileMode,
+		),
+	)
+	assert.Assert(t, fs.Equal(extractdir.Path(), expected))
+
+	// extract a single member with the index of the archive.
+	memberdir := fs.NewDir(t, "member")
+	member := filepath.ToSlash(memberdir.Path())
+
+	cmd = s5cmd("extract", "--use-index", "--include", "sub/c.txt", dst, member)
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`extract %v %v/sub/c.txt`, dst, member),
+	})
+
+	expected = fs.Expected(t,
+		fs.WithDir("sub",
+			fs.WithFile("c.txt", "content of c", fs.MatchAnyFileMode),
+			fs.MatchAnyFileMode,
+		),
+	)
+	assert.Assert(t, fs.Equal(memberdir.Path(), expected))
+}
+
+func TestCopyCompressedArchiveAndExtract(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		format      string
+		contentType string
+	}{
+		{format: "tar.gz", contentType: "application/gzip"},
+		{format: "tar.zst", contentType: "application/zstd"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd := setup(t)
+
+			bucket := s3BucketFromTestName(t)
+			createBucket(t, s3client, bucket)
+
+			srcdir := fs.NewDir(t, "archive",
+				fs.WithFile("a.txt", "content of a"),
+				fs.WithFile("b.txt", "content of b"),
+			)
+
+			src := filepath.ToSlash(srcdir.Path())
+			dst := fmt.Sprintf("s3://%v/backup.%v", bucket, tc.format)
+
+			cmd := s5cmd("cp", "--archive", tc.format, src+"/*.txt", dst)
+			result := icmd.RunCmd(cmd)
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals(`cp %v/*.txt %v`, src, dst),
+			})
+
+			head, err := s3client.HeadObject(&s3.HeadObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String("backup." + tc.format),
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, aws.StringValue(head.ContentType), tc.contentType)
+
+			extractdir := fs.NewDir(t, "extract")
+			extracted := filepath.ToSlash(extractdir.Path())
+
+			cmd = s5cmd("extract", "--exclude", "b.txt", dst, extracted)
+			result = icmd.RunCmd(cmd)
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals(`extract %v %v/a.txt`, dst, extracted),
+			})
+
+			expected := fs.Expected(t, fs.WithFile("a.txt", "content of a", fs.MatchAnyFileMode))
+			assert.Assert(t, fs.Equal(extractdir.Path(), expected))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestCompressionFlagsValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "unsupported compression",
+			args:     []string{"cp", "--compress", "lzma", "dir/*", "s3://bucket/"},
+			expected: `ERROR "cp --compress=lzma dir/* s3://bucket/": unsupported compression "lzma": must be gzip or zstd`,
+		},
+		{
+			name:     "compress download",
+			args:     []string{"cp", "--compress", "gzip", "s3://bucket/*", "dir/"},
+			expected: `ERROR "cp --compress=gzip s3://bucket/* dir/": "compress" flag is only supported for uploads`,
+		},
+		{
+			name:     "decompress upload",
+			args:     []string{"cp", "--decompress", "dir/*", "s3://bucket/"},
+			expected: `ERROR "cp --decompress=true dir/* s3://bucket/": "decompress" flag is only supported for downloads`,
+		},
+		{
+			name:     "compress with content encoding",
+			args:     []string{"pipe", "--compress", "gzip", "--content-encoding", "br", "s3://bucket/key"},
+			expected: `ERROR "pipe --content-encoding=br --compress=gzip s3://bucket/key": "compress" and "content-encoding" flags cannot be used together`,
+		},
+		{
+			name:     "suffix without compression",
+			args:     []string{"pipe", "--compress-suffix", "s3://bucket/key"},
+			expected: `ERROR "pipe --compress-suffix=true s3://bucket/key": "compress-suffix" flag requires "compress" flag`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyWithCompressAndDecompress(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	content := strings.Repeat("this is a line\n", 1000)
+
+	srcdir := fs.NewDir(t, "compress", fs.WithFile("app.txt", content))
+	defer srcdir.Remove()
+
+	src := filepath.ToSlash(srcdir.Path())
+
+	cmd := s5cmd("cp", "--compress", "gzip", "--compress-suffix", src+"/*", fmt.Sprintf("s3://%v/compressed/", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/app.txt s3://%v/compressed/app.txt.gz`, src, bucket),
+	})
+
+	head, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("compressed/app.txt.gz"),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, aws.StringValue(head.ContentEncoding), "gzip")
+	assert.Assert(t, aws.Int64Value(head.ContentLength) < int64(len(content)))
+
+	// the content type is of the original file.
+	assert.Equal(t, aws.StringValue(head.ContentType), "text/plain; charset=utf-8")
+
+	dstdir := fs.NewDir(t, "decompress")
+	defer dstdir.Remove()
+
+	dst := filepath.ToSlash(dstdir.Path())
+
+	cmd = s5cmd("cp", "--decompress", fmt.Sprintf("s3://%v/compressed/*", bucket), dst+"/")
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/compressed/app.txt.gz %v/app.txt.gz`, bucket, dst),
+	})
+
+	expected := fs.Expected(t, fs.WithFile("app.txt.gz", content, fs.WithMode(0644)))
+	assert.Assert(t, fs.Equal(dstdir.Path(), expected))
+}
+
+func TestPipeWithCompressAndCatWithDecompress(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("streamed content\n", 1000)
+
+	testcases := []struct {
+		compression string
+		magic       string
+	}{
+		{compression: "gzip", magic: "\x1f\x8b"},
+		{compression: "zstd", magic: "\x28\xb5\x2f\xfd"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.compression, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd := setup(t)
+
+			bucket := s3BucketFromTestName(t)
+			createBucket(t, s3client, bucket)
+
+			cmd := s5cmd("pipe", "--compress", tc.compression, fmt.Sprintf("s3://%v/stream.txt", bucket))
+			result := icmd.RunCmd(cmd, icmd.WithStdin(strings.NewReader(content)))
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals(`pipe s3://%v/stream.txt`, bucket),
+			})
+
+			head, err := s3client.HeadObject(&s3.HeadObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String("stream.txt"),
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, aws.StringValue(head.ContentEncoding), tc.compression)
+
+			cmd = s5cmd("cat", "--decompress", fmt.Sprintf("s3://%v/stream.txt", bucket))
+			result = icmd.RunCmd(cmd)
+			result.Assert(t, icmd.Success)
+			assert.Equal(t, result.Stdout(), content)
+
+			// the object is kept compressed without the flag.
+			cmd = s5cmd("cat", fmt.Sprintf("s3://%v/stream.txt", bucket))
+			result = icmd.RunCmd(cmd)
+			result.Assert(t, icmd.Success)
+			assert.Assert(t, strings.HasPrefix(result.Stdout(), tc.magic))
+		})
+	}
+}
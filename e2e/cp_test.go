@@ -24,6 +24,7 @@ package e2e
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -35,6 +36,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/fs"
 	"gotest.tools/v3/icmd"
@@ -667,6 +669,47 @@ func TestCopyMultipleS3ObjectsToGivenLocalDirectory(t *testing.T) {
 }
 
 // cp dir/file s3://bucket/
+func TestCopyWithLimitRate(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "file.txt"
+		content  = "this is a file content"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/%v", bucket, filename)
+
+	cmd := s5cmd("--limit-rate", "1MB/s", "--limit-upload-rate", "512K", "cp", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`cp %v %v`, srcpath, dstpath),
+	})
+
+	cmd = s5cmd("--limit-rate", "1MB/s", "--limit-download-rate", "512K", "cp", dstpath, "downloaded.txt")
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`cp %v downloaded.txt`, dstpath),
+	})
+
+	// assert S3
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
 func TestCopySingleFileToS3(t *testing.T) {
 	t.Parallel()
 
@@ -4561,3 +4604,399 @@ func TestCopyS3ObjectsWithIncludeExcludeFilter2(t *testing.T) {
 	expected := fs.Expected(t, expectedFileSystem...)
 	assert.Assert(t, fs.Equal(cmd.Dir, expected))
 }
+
+// cp --resume --part-size 5 file s3://bucket/
+func TestCopySingleFileToS3WithResume(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const filename = "bigfile.txt"
+	// make sure that the file is uploaded in multiple parts.
+	content := strings.Repeat("s5cmd", 6*1024*1024/5)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	cachedir := fs.NewDir(t, "cache")
+	defer cachedir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--resume", "--part-size", "5", srcpath, dstpath)
+	result := icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`cp %v %v%v`, srcpath, dstpath, filename),
+	})
+
+	// assert S3
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+
+	// the journal is removed once the upload is completed.
+	cmd = s5cmd("journal", "ls")
+	result = icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+
+	// durations in days are accepted like "mpu abort --older-than".
+	cmd = s5cmd("journal", "abort", "--older-than", "7d")
+	result = icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
+
+// cp --resume s3://bucket/object .
+func TestCopySingleS3ObjectToLocalWithResume(t *testing.T) {
+	t.Parallel()
+
+	const (
+		filename = "file1.txt"
+		content  = "this is a file content"
+	)
+
+	testcases := []struct {
+		name        string
+		partial     string
+		journalETag string
+		noJournal   bool
+	}{
+		{
+			name: "no partial file",
+		},
+		{
+			// only the first 4 bytes are downloaded, the rest is garbage.
+			name:    "partial file of the same object",
+			partial: content[:4] + strings.Repeat("x", len(content)-4),
+		},
+		{
+			name:        "partial file of a changed object",
+			partial:     strings.Repeat("x", len(content)),
+			journalETag: `"0123456789abcdef"`,
+		},
+		{
+			name:      "partial file without a journal",
+			partial:   strings.Repeat("x", 2*len(content)),
+			noJournal: true,
+		},
+		{
+			name: "journal without a partial file",
+		},
+	}
+
+	for i, tc := range testcases {
+		tc := tc
+		i := i
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd := setup(t)
+
+			bucket := s3BucketFromTestNameWithPrefix(t, strconv.Itoa(i))
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, filename, content)
+
+			src := fmt.Sprintf("s3://%v/%v", bucket, filename)
+			cmd := s5cmd("cp", "--resume", src, ".")
+
+			partialPath := filepath.Join(cmd.Dir, filename+".s5cmd-partial")
+			if tc.partial != "" {
+				assert.NilError(t, os.WriteFile(partialPath, []byte(tc.partial), 0644))
+			}
+
+			if i > 0 && !tc.noJournal {
+				etag := tc.journalETag
+				if etag == "" {
+					head, err := s3client.HeadObject(&s3.HeadObjectInput{
+						Bucket: aws.String(bucket),
+						Key:    aws.String(filename),
+					})
+					assert.NilError(t, err)
+					etag = aws.StringValue(head.ETag)
+				}
+
+				journal := fmt.Sprintf(`{"source":%q,"etag":%q,"size":%d,"ranges":[{"start":0,"end":4}]}`, src, etag, len(content))
+				assert.NilError(t, os.WriteFile(partialPath+".json", []byte(journal), 0644))
+			}
+
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Success)
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals(`cp %v %v`, src, filename),
+			})
+
+			// partial file and its journal are moved or removed.
+			expected := fs.Expected(t, fs.WithFile(filename, content, fs.WithMode(0644)))
+			assert.Assert(t, fs.Equal(cmd.Dir, expected))
+		})
+	}
+}
+
+func TestCopyResumeFlagsValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "compressed upload",
+			args:     []string{"cp", "--resume", "--compress", "gzip", "file.txt", "s3://bucket/"},
+			expected: `"resume" flag cannot be used with "compress" flag`,
+		},
+		{
+			name:     "decompressed download",
+			args:     []string{"cp", "--resume", "--decompress", "s3://bucket/key", "."},
+			expected: `"resume" flag cannot be used with "decompress" flag`,
+		},
+		{
+			name:     "encrypted upload",
+			args:     []string{"cp", "--resume", "--cse-passphrase-file", "passphrase", "file.txt", "s3://bucket/"},
+			expected: `"resume" flag cannot be used with client-side encryption`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyWithFailedOps(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+
+	workdir := fs.NewDir(t, bucket,
+		fs.WithDir("dir",
+			fs.WithFile("file1.txt", "content"),
+			fs.WithFile("file2.txt", "content"),
+		),
+	)
+	defer workdir.Remove()
+
+	failedOps := workdir.Join("failed.txt")
+	src := filepath.ToSlash(workdir.Join("dir", "*"))
+	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+	// the bucket does not exist, so that all uploads fail.
+	cmd := s5cmd("--failed-ops", failedOps, "cp", "--storage-class", "STANDARD_IA", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	data, err := os.ReadFile(failedOps)
+	assert.NilError(t, err)
+
+	assertLines(t, string(data), map[int]compareFunc{
+		0: equals(`cp --raw='true' --storage-class='STANDARD_IA' "%v" "s3://%v/prefix/file1.txt"`, filepath.ToSlash(workdir.Join("dir", "file1.txt")), bucket),
+		1: equals(`cp --raw='true' --storage-class='STANDARD_IA' "%v" "s3://%v/prefix/file2.txt"`, filepath.ToSlash(workdir.Join("dir", "file2.txt")), bucket),
+	}, sortInput(true))
+
+	// retry the failures, and record the failures of the retry to the
+	// same file.
+	createBucket(t, s3client, bucket)
+
+	cmd = s5cmd("--failed-ops", failedOps, "run", failedOps)
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v s3://%v/prefix/file1.txt`, filepath.ToSlash(workdir.Join("dir", "file1.txt")), bucket),
+		1: equals(`cp %v s3://%v/prefix/file2.txt`, filepath.ToSlash(workdir.Join("dir", "file2.txt")), bucket),
+	}, sortInput(true))
+
+	data, err = os.ReadFile(failedOps)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "")
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/file1.txt", "content", ensureStorageClass("STANDARD_IA")))
+}
+
+// cp --cse-key-file key file s3://bucket/ && cp --cse-key-file key s3://bucket/file dir/
+func TestCopyWithClientSideEncryption(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const filename = "file.txt"
+
+	// larger than a part, so that it is uploaded and downloaded in parts.
+	content := strings.Repeat("s5cmd client-side encryption\n", int(7*mb/29))
+
+	workdir := fs.NewDir(t, bucket,
+		fs.WithFile(filename, content),
+		fs.WithFile("key", strings.Repeat("ab", 32)),
+		fs.WithDir("dst"),
+	)
+	defer workdir.Remove()
+
+	keyFile := workdir.Join("key")
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/%v", bucket, filename)
+
+	cmd := s5cmd("cp", "-p", "5", "--cse-key-file", keyFile, srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// the object is stored encrypted, along with its wrapped key.
+	output, err := s3client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(filename),
+	})
+	assert.NilError(t, err)
+	defer output.Body.Close()
+
+	stored, err := io.ReadAll(output.Body)
+	assert.NilError(t, err)
+	assert.Assert(t, len(stored) > len(content))
+	assert.Assert(t, !strings.Contains(string(stored), "s5cmd client-side encryption"))
+
+	var algorithm string
+	for k, v := range output.Metadata {
+		if strings.EqualFold(k, "s5cmd-cse-algorithm") {
+			algorithm = aws.StringValue(v)
+		}
+	}
+	assert.Equal(t, algorithm, "AES256-GCM-64K")
+
+	// download in parallel ranges.
+	cmd = s5cmd("cp", "-p", "5", "-c", "4", "--cse-key-file", keyFile, dstpath, filepath.ToSlash(workdir.Join("dst", filename)))
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	data, err := os.ReadFile(workdir.Join("dst", filename))
+	assert.NilError(t, err)
+	assert.Assert(t, string(data) == content, "decrypted content does not match")
+
+	cmd = s5cmd("cat", "--cse-key-file", keyFile, dstpath)
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assert.Assert(t, result.Stdout() == content, "decrypted content does not match")
+
+	// an encrypted object can not be downloaded without the key.
+	cmd = s5cmd("cat", dstpath)
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cat %v": object is encrypted on the client side, a key is required to decrypt it`, dstpath),
+	})
+}
+
+func TestCopyWithClientSideEncryptionAndResume(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	cmd := s5cmd("cp", "--resume", "--cse-key-file", "key", "file.txt", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp --resume=true --cse-key-file=key file.txt s3://bucket/": "resume" flag cannot be used with client-side encryption`),
+	})
+}
+
+// cp --resume s3://bucket/encrypted-object .
+func TestCopyClientSideEncryptedObjectWithResume(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const filename = "file.txt"
+
+	putFile(t, s3client, bucket, filename, "ciphertext", putArbitraryMetadata(map[string]*string{
+		"s5cmd-cse-algorithm": aws.String("AES256-GCM-64K"),
+	}))
+
+	src := fmt.Sprintf("s3://%v/%v", bucket, filename)
+	cmd := s5cmd("cp", "--resume", src, ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`"resume" flag cannot be used with objects encrypted on the client side`),
+	})
+
+	// the ciphertext is not written to the destination.
+	assert.Assert(t, fs.Equal(cmd.Dir, fs.Expected(t)))
+}
+
+func TestCopyWithInvalidSSECustomerKey(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "short key",
+			args:     []string{"--sse-c-key", "short"},
+			expected: `ERROR "cp --sse-c-key=short file.txt s3://bucket/": invalid sse-c-key: expected a 256-bit key as 32 bytes, hex or base64`,
+		},
+		{
+			name:     "key and key file",
+			args:     []string{"--sse-c-copy-source-key", "short", "--sse-c-copy-source-key-file", "key"},
+			expected: `ERROR "cp --sse-c-copy-source-key=short --sse-c-copy-source-key-file=key file.txt s3://bucket/": "sse-c-copy-source-key" and "sse-c-copy-source-key-file" flags cannot be used together`,
+		},
+		{
+			name:     "sse and sse-c",
+			args:     []string{"--sse", "aws:kms", "--sse-c-key", strings.Repeat("a", 32)},
+			expected: fmt.Sprintf(`ERROR "cp --sse=aws:kms --sse-c-key=%v file.txt s3://bucket/": "sse" and "sse-c-key" flags cannot be used together`, strings.Repeat("a", 32)),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := append([]string{"cp"}, tc.args...)
+			cmd := s5cmd(append(args, "file.txt", "s3://bucket/")...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestFilterFlagsValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid time",
+			args:     []string{"ls", "--older-than", "yesterday", "s3://bucket/*"},
+			expected: `ERROR "ls --older-than=yesterday s3://bucket/*": invalid time "yesterday": must be a duration, e.g. 30d, or a date, e.g. 2026-01-02`,
+		},
+		{
+			name:     "invalid size",
+			args:     []string{"rm", "--min-size", "10X", "s3://bucket/*"},
+			expected: `ERROR "rm --min-size=10X s3://bucket/*": invalid size "10X"`,
+		},
+		{
+			name:     "min size greater than max size",
+			args:     []string{"du", "--min-size", "2K", "--max-size", "1K", "s3://bucket/*"},
+			expected: `ERROR "du --min-size=2K --max-size=1K s3://bucket/*": "min-size" can not be greater than "max-size"`,
+		},
+		{
+			name:     "bidirectional sync",
+			args:     []string{"sync", "--bidirectional", "--newer-than", "1d", "dir/", "s3://bucket/"},
+			expected: `ERROR "sync --bidirectional=true --newer-than=1d dir/ s3://bucket/": "bidirectional" flag cannot be used with the filter flags`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// putOldAndNewFiles puts small and large objects which are modified two days
+// and an hour ago, and returns the bucket of the objects.
+func putOldAndNewFiles(t *testing.T, timeSource *fixedTimeSource) (string, func(...string) icmd.Cmd) {
+	t.Helper()
+
+	s3client, s5cmd := setup(t, withTimeSource(timeSource))
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	large := strings.Repeat("x", 2048)
+
+	putFile(t, s3client, bucket, "old-small.txt", "small")
+	putFile(t, s3client, bucket, "old-large.txt", large)
+
+	timeSource.Advance(47 * time.Hour)
+
+	putFile(t, s3client, bucket, "new-small.txt", "small")
+	putFile(t, s3client, bucket, "new-large.txt", large)
+
+	return bucket, s5cmd
+}
+
+func TestListWithFilters(t *testing.T) {
+	t.Parallel()
+
+	timeSource := newFixedTimeSource(time.Now().Add(-48 * time.Hour))
+	bucket, s5cmd := putOldAndNewFiles(t, timeSource)
+
+	cmd := s5cmd("ls", "--older-than", "1d", fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("2048 old-large.txt"),
+		1: suffix("5 old-small.txt"),
+	})
+
+	cmd = s5cmd("ls", "--newer-than", "1d", "--min-size", "1K", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("2048 new-large.txt"),
+	})
+
+	cmd = s5cmd("ls", "--max-size", "1K", "--only-storage-class", "STANDARD", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("5 new-small.txt"),
+		1: suffix("5 old-small.txt"),
+	})
+}
+
+func TestRemoveWithFilters(t *testing.T) {
+	t.Parallel()
+
+	timeSource := newFixedTimeSource(time.Now().Add(-48 * time.Hour))
+	bucket, s5cmd := putOldAndNewFiles(t, timeSource)
+
+	cmd := s5cmd("rm", "--older-than", "1d", fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`rm s3://%v/old-large.txt`, bucket),
+		1: equals(`rm s3://%v/old-small.txt`, bucket),
+	}, sortInput(true))
+
+	cmd = s5cmd("ls", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("new-large.txt"),
+		1: suffix("new-small.txt"),
+	})
+}
+
+func TestDiskUsageWithFilters(t *testing.T) {
+	t.Parallel()
+
+	timeSource := newFixedTimeSource(time.Now().Add(-48 * time.Hour))
+	bucket, s5cmd := putOldAndNewFiles(t, timeSource)
+
+	cmd := s5cmd("du", "--min-size", "1K", fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`4096 bytes in 2 objects: s3://%v/*`, bucket),
+	})
+}
+
+func TestCopyWithFilters(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	now := time.Now()
+	old := fs.WithTimestamps(now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+
+	workdir := fs.NewDir(t, "somedir",
+		fs.WithFile("old-small.txt", "small", old),
+		fs.WithFile("old-large.txt", strings.Repeat("x", 2048), old),
+		fs.WithFile("new-small.txt", "small"),
+	)
+	defer workdir.Remove()
+
+	src := filepath.ToSlash(workdir.Path())
+
+	cmd := s5cmd("cp", "--older-than", "1d", "--max-size", "1K", src+"/*", fmt.Sprintf("s3://%v/", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/old-small.txt s3://%v/old-small.txt`, src, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "old-small.txt", "small"))
+}
+
+func TestSyncWithFilters(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	large := strings.Repeat("x", 2048)
+
+	putFile(t, s3client, srcbucket, "small.txt", "small")
+	putFile(t, s3client, srcbucket, "large.txt", large)
+
+	// the small objects are neither copied nor deleted.
+	putFile(t, s3client, dstbucket, "small.txt", "old small")
+	putFile(t, s3client, dstbucket, "deleted-small.txt", "small")
+	putFile(t, s3client, dstbucket, "deleted-large.txt", large)
+
+	src := fmt.Sprintf("s3://%v/", srcbucket)
+	dst := fmt.Sprintf("s3://%v/", dstbucket)
+
+	cmd := s5cmd("sync", "--delete", "--min-size", "1K", src+"*", dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %vlarge.txt %vlarge.txt`, src, dst),
+		1: equals(`rm %vdeleted-large.txt`, dst),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "large.txt", large))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "small.txt", "old small"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "deleted-small.txt", "small"))
+}
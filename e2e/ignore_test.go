@@ -0,0 +1,186 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestIgnoreFlagsValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	workdir := fs.NewDir(t, "ignore", fs.WithFile("invalid.txt", "*.log\n!\n"))
+
+	invalid := filepath.ToSlash(workdir.Join("invalid.txt"))
+	missing := filepath.ToSlash(workdir.Join("missing.txt"))
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "missing exclude file",
+			args:     []string{"ls", "--exclude-from", missing, "s3://bucket/*"},
+			expected: fmt.Sprintf(`ERROR "ls --exclude-from=%v s3://bucket/*": open %v: no such file or directory`, missing, missing),
+		},
+		{
+			name:     "invalid pattern",
+			args:     []string{"rm", "--include-from", invalid, "s3://bucket/*"},
+			expected: fmt.Sprintf(`ERROR "rm --include-from=%v s3://bucket/*": %v: invalid pattern "!"`, invalid, invalid),
+		},
+		{
+			name:     "invalid regular expression",
+			args:     []string{"du", "--exclude-regex", "(", "s3://bucket/*"},
+			expected: `ERROR "du --exclude-regex=( s3://bucket/*": invalid regular expression "(": error parsing regexp: missing closing ): ` + "`(`",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestListWithExcludeFrom(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	for _, key := range []string{"readme.md", "a.log", "logs/keep.log", "build/out.o", "src/build/main.go"} {
+		putFile(t, s3client, bucket, key, "content")
+	}
+
+	workdir := fs.NewDir(t, "ignore", fs.WithFile("exclude.txt", "# logs\n*.log\n!keep.log\n/build/\n"))
+	defer workdir.Remove()
+
+	cmd := s5cmd("ls", "--exclude-from", workdir.Join("exclude.txt"), fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("7 logs/keep.log"),
+		1: suffix("7 readme.md"),
+		2: suffix("7 src/build/main.go"),
+	})
+
+	cmd = s5cmd("du", "--exclude-from", workdir.Join("exclude.txt"), "--exclude-regex", `\.go$`, fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`14 bytes in 2 objects: s3://%v/*`, bucket),
+	})
+}
+
+func TestRemoveWithExcludeRegexAndIncludeFrom(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	for _, key := range []string{"a.txt", "b.txt", "c.md", "dir/d.txt"} {
+		putFile(t, s3client, bucket, key, "content")
+	}
+
+	workdir := fs.NewDir(t, "ignore", fs.WithFile("include.txt", "*.txt\n"))
+	defer workdir.Remove()
+
+	cmd := s5cmd("rm", "--include-from", workdir.Join("include.txt"), "--exclude-regex", "^b", fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`rm s3://%v/a.txt`, bucket),
+		1: equals(`rm s3://%v/dir/d.txt`, bucket),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "b.txt", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "c.md", "content"))
+}
+
+func TestListWithIgnoreFiles(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	workdir := fs.NewDir(t, "ignore",
+		fs.WithFile("a.txt", "a"),
+		fs.WithDir("sub",
+			fs.WithFile(".s5cmdignore", "*.log\n!keep.log\nbuild/\n"),
+			fs.WithFile("b.log", "b"),
+			fs.WithFile("keep.log", "keep"),
+			fs.WithFile("c.txt", "c"),
+			fs.WithDir("build", fs.WithFile("out.o", "out")),
+		),
+	)
+	defer workdir.Remove()
+
+	src := filepath.ToSlash(workdir.Path())
+
+	cmd := s5cmd("ls", "--use-ignore-files", "--show-fullpath", src+"/*")
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`%v/a.txt`, src),
+		1: equals(`%v/sub/`, src),
+		2: equals(`%v/sub/c.txt`, src),
+		3: equals(`%v/sub/keep.log`, src),
+	}, sortInput(true))
+}
+
+func TestSyncWithExcludeFrom(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, s3client, srcbucket, "a.txt", "a")
+	putFile(t, s3client, srcbucket, "tmp/b.txt", "b")
+
+	// the excluded objects are not deleted.
+	putFile(t, s3client, dstbucket, "tmp/c.txt", "c")
+
+	workdir := fs.NewDir(t, "ignore", fs.WithFile("exclude.txt", "tmp/\n"))
+	defer workdir.Remove()
+
+	src := fmt.Sprintf("s3://%v/", srcbucket)
+	dst := fmt.Sprintf("s3://%v/", dstbucket)
+
+	cmd := s5cmd("sync", "--delete", "--exclude-from", workdir.Join("exclude.txt"), src+"*", dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %va.txt %va.txt`, src, dst),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "a.txt", "a"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "tmp/c.txt", "c"))
+}
@@ -0,0 +1,151 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/icmd"
+)
+
+// createMultipartUpload starts a multipart upload of the key with a part of
+// the given content, and returns its upload ID.
+func createMultipartUpload(t *testing.T, s3client *s3.S3, bucket, key, content string) string {
+	t.Helper()
+
+	output, err := s3client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		t.Fatalf("failed to create multipart upload: %v", err)
+	}
+
+	_, err = s3client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		UploadId:   output.UploadId,
+		PartNumber: aws.Int64(1),
+		Body:       strings.NewReader(content),
+	})
+	if err != nil {
+		t.Fatalf("failed to upload part: %v", err)
+	}
+
+	return aws.StringValue(output.UploadId)
+}
+
+func TestMPUCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no source",
+			args:     []string{"mpu", "ls"},
+			expected: `ERROR "mpu ls": expected remote url`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"mpu", "abort", "dir/"},
+			expected: `ERROR "mpu abort dir/": source must be a remote url`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestMPUList(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	uploadID1 := createMultipartUpload(t, s3client, bucket, "prefix/file1.gz", "content")
+	uploadID2 := createMultipartUpload(t, s3client, bucket, "prefix/file2.txt", "content")
+	createMultipartUpload(t, s3client, bucket, "other/file3.gz", "content")
+
+	cmd := s5cmd("mpu", "ls", fmt.Sprintf("s3://%v/prefix/", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`1 7 s3://%v/prefix/file1.gz %v`, bucket, uploadID1),
+		1: suffix(`1 7 s3://%v/prefix/file2.txt %v`, bucket, uploadID2),
+	}, sortInput(true))
+
+	cmd = s5cmd("--json", "mpu", "ls", fmt.Sprintf("s3://%v/*.gz", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: contains(`"key":"s3://%v/other/file3.gz"`, bucket),
+		1: contains(`"key":"s3://%v/prefix/file1.gz","upload_id":"%v"`, bucket, uploadID1),
+	}, sortInput(true))
+
+	// the uploads which are started recently are not listed.
+	cmd = s5cmd("mpu", "ls", "--older-than", "1d", fmt.Sprintf("s3://%v", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
+
+func TestMPUAbort(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	createMultipartUpload(t, s3client, bucket, "file1.gz", "content")
+	createMultipartUpload(t, s3client, bucket, "file2.gz", "content")
+	uploadID := createMultipartUpload(t, s3client, bucket, "file3.txt", "content")
+
+	// the uploads which are started recently are not aborted.
+	cmd := s5cmd("mpu", "abort", "--older-than", "7d", fmt.Sprintf("s3://%v/*.gz", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+
+	cmd = s5cmd("mpu", "abort", fmt.Sprintf("s3://%v/*.gz", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`abort s3://%v/file1.gz`, bucket),
+		1: equals(`abort s3://%v/file2.gz`, bucket),
+	}, sortInput(true))
+
+	cmd = s5cmd("mpu", "ls", fmt.Sprintf("s3://%v", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`s3://%v/file3.txt %v`, bucket, uploadID),
+	})
+}
@@ -0,0 +1,165 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestCopyWithRenameValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		rename   string
+		expected string
+	}{
+		{
+			name:     "invalid pattern",
+			rename:   "( -> $1",
+			expected: `ERROR "cp --rename=( -> $1 s3://bucket/* s3://bucket/dst/": invalid rename pattern "(": error parsing regexp: missing closing ): ` + "`(`",
+		},
+		{
+			name:     "unknown variable",
+			rename:   "{size}",
+			expected: `ERROR "cp --rename={size} s3://bucket/* s3://bucket/dst/": unknown rename variable "{size}"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd("cp", "--rename", tc.rename, "s3://bucket/*", "s3://bucket/dst/")
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyS3ToS3WithRename(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "src/2024-05-report.csv", "may")
+	putFile(t, s3client, bucket, "src/2024-06-report.csv", "june")
+	putFile(t, s3client, bucket, "src/readme.md", "readme")
+
+	cmd := s5cmd("cp", "--rename", `(\d{4})-(\d{2})-(.*) -> $1/$2/$3`, fmt.Sprintf("s3://%v/src/*", bucket), fmt.Sprintf("s3://%v/dst/", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/src/2024-05-report.csv s3://%v/dst/2024/05/report.csv`, bucket, bucket),
+		1: equals(`cp s3://%v/src/2024-06-report.csv s3://%v/dst/2024/06/report.csv`, bucket, bucket),
+		2: equals(`cp s3://%v/src/readme.md s3://%v/dst/readme.md`, bucket, bucket),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "dst/2024/05/report.csv", "may"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "dst/2024/06/report.csv", "june"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "dst/readme.md", "readme"))
+}
+
+func TestCopyS3ToS3WithRenameCollision(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "src/a/file.txt", "a")
+	putFile(t, s3client, bucket, "src/b/file.txt", "b")
+
+	src := fmt.Sprintf("s3://%v/src/*", bucket)
+	dst := fmt.Sprintf("s3://%v/dst/", bucket)
+
+	cmd := s5cmd("cp", "--rename", "{basename}", src, dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/src/a/file.txt s3://%v/dst/file.txt`, bucket, bucket),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp --rename={basename} %v %v": "s3://%v/src/a/file.txt" and "s3://%v/src/b/file.txt" are renamed to the same destination "file.txt"`, src, dst, bucket, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "dst/file.txt", "a"))
+}
+
+func TestCopyLocalToS3WithRenameTemplate(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	modTime := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	workdir := fs.NewDir(t, "rename",
+		fs.WithFile("report.csv", "report", fs.WithTimestamps(modTime, modTime)),
+	)
+	defer workdir.Remove()
+
+	src := filepath.ToSlash(workdir.Path())
+
+	cmd := s5cmd("cp", "--rename", "{mtime:2006/01}/{stem}-copy{ext}", src+"/*", fmt.Sprintf("s3://%v/", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/report.csv s3://%v/2024/05/report-copy.csv`, src, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "2024/05/report-copy.csv", "report"))
+}
+
+func TestCopyS3ToLocalWithRename(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "logs/app-1.log", "1")
+	putFile(t, s3client, bucket, "logs/app-2.log", "2")
+
+	workdir := fs.NewDir(t, "rename")
+	defer workdir.Remove()
+
+	dst := filepath.ToSlash(workdir.Path())
+
+	cmd := s5cmd("cp", "--rename", `app-(\d+)\.log -> $1/app.log`, fmt.Sprintf("s3://%v/logs/*", bucket), dst+"/")
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/logs/app-1.log %v/1/app.log`, bucket, dst),
+		1: equals(`cp s3://%v/logs/app-2.log %v/2/app.log`, bucket, dst),
+	}, sortInput(true))
+
+	expected := fs.Expected(t,
+		fs.WithDir("1", fs.WithFile("app.log", "1", fs.WithMode(0644))),
+		fs.WithDir("2", fs.WithFile("app.log", "2", fs.WithMode(0644))),
+	)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/icmd"
+)
+
+func TestRestorePrefixCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no time",
+			args:     []string{"restore-prefix", "s3://bucket/prefix/*"},
+			expected: `ERROR "restore-prefix s3://bucket/prefix/*": "at" flag is required`,
+		},
+		{
+			name:     "invalid time",
+			args:     []string{"restore-prefix", "--at", "yesterday", "s3://bucket/prefix/*"},
+			expected: `ERROR "restore-prefix --at=yesterday s3://bucket/prefix/*": invalid time "yesterday": must be in RFC3339 format`,
+		},
+		{
+			name:     "local destination",
+			args:     []string{"restore-prefix", "--at", "2026-09-01T00:00:00Z", "--to", "dir/", "s3://bucket/prefix/*"},
+			expected: `ERROR "restore-prefix --at=2026-09-01T00:00:00Z --to=dir/ s3://bucket/prefix/*": destination must be a remote prefix`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// versionsBeforeAndAfter creates the versions of the objects in the bucket
+// before and after the returned point in time. At that time, "a.txt" and
+// "b.txt" were in their first versions and "c.txt" did not exist. Then
+// "a.txt" is overwritten, "b.txt" is deleted and "c.txt" is created.
+func versionsBeforeAndAfter(t *testing.T, s3client *s3.S3, timeSource *fixedTimeSource, bucket string) time.Time {
+	t.Helper()
+
+	createBucket(t, s3client, bucket)
+	setBucketVersioning(t, s3client, bucket, "Enabled")
+
+	putFile(t, s3client, bucket, "a.txt", "a version 1")
+	putFile(t, s3client, bucket, "b.txt", "b version 1")
+
+	timeSource.Advance(time.Minute)
+	at := timeSource.Now()
+	timeSource.Advance(time.Minute)
+
+	putFile(t, s3client, bucket, "a.txt", "a version 2")
+	putFile(t, s3client, bucket, "c.txt", "c version 1")
+	_, err := s3client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("b.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return at
+}
+
+// TestRestorePrefixDryRun tests the plan of restores. The fake S3 server can
+// not copy the versions of objects, so the restores are not run.
+func TestRestorePrefixDryRun(t *testing.T) {
+	t.Parallel()
+
+	timeSource := newFixedTimeSource(time.Now().Add(-time.Hour).UTC())
+	s3client, s5cmd := setup(t, withS3Backend("mem"), withTimeSource(timeSource))
+
+	bucket := s3BucketFromTestName(t)
+	at := versionsBeforeAndAfter(t, s3client, timeSource, bucket).Format(time.RFC3339)
+
+	cmd := s5cmd("--dry-run", "restore-prefix", "--at", at, "--delete", fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^restore-prefix s3://%v/a.txt \S+ s3://%v/a.txt$`, bucket, bucket)),
+		1: match(fmt.Sprintf(`^restore-prefix s3://%v/b.txt \S+ s3://%v/b.txt$`, bucket, bucket)),
+		2: equals(`rm s3://%v/c.txt`, bucket),
+	}, sortInput(true))
+
+	// the objects which did not exist are not deleted without --delete flag.
+	cmd = s5cmd("--dry-run", "restore-prefix", "--at", at, fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^restore-prefix s3://%v/a.txt \S+ s3://%v/a.txt$`, bucket, bucket)),
+		1: match(fmt.Sprintf(`^restore-prefix s3://%v/b.txt \S+ s3://%v/b.txt$`, bucket, bucket)),
+	}, sortInput(true))
+
+	cmd = s5cmd("--dry-run", "restore-prefix", "--at", at, "--to", "s3://other/restored/", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^restore-prefix s3://%v/a.txt \S+ s3://other/restored/a.txt$`, bucket)),
+		1: match(fmt.Sprintf(`^restore-prefix s3://%v/b.txt \S+ s3://other/restored/b.txt$`, bucket)),
+	}, sortInput(true))
+}
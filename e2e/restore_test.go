@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestRestoreCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid tier",
+			args:     []string{"restore", "--tier", "Fast", "s3://bucket/object"},
+			expected: `ERROR "restore --tier=Fast s3://bucket/object": invalid retrieval tier "Fast": must be one of 'Standard', 'Bulk' or 'Expedited'`,
+		},
+		{
+			name:     "invalid days",
+			args:     []string{"restore", "--days", "0", "s3://bucket/object"},
+			expected: `ERROR "restore --days=0 s3://bucket/object": days must be a positive number`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"restore", "file.txt"},
+			expected: `ERROR "restore file.txt": source must be a remote object`,
+		},
+		{
+			name:     "timeout without wait",
+			args:     []string{"restore", "--timeout", "1h", "s3://bucket/object"},
+			expected: `ERROR "restore --timeout=1h s3://bucket/object": "timeout" flag requires "wait" flag`,
+		},
+		{
+			name:     "invalid restore tier of copy",
+			args:     []string{"cp", "--restore-if-needed", "--restore-tier", "Fast", "s3://bucket/object", "."},
+			expected: `ERROR "cp --restore-if-needed=true --restore-tier=Fast s3://bucket/object .": invalid retrieval tier "Fast": must be one of 'Standard', 'Bulk' or 'Expedited'`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// TestCopyRestoreIfNeededNotArchived tests that the objects which are not
+// archived are copied without being restored.
+func TestCopyRestoreIfNeededNotArchived(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "file1.txt"
+		content  = "this is a file content"
+	)
+
+	putFile(t, s3client, bucket, filename, content)
+
+	src := fmt.Sprintf("s3://%v/%v", bucket, filename)
+	cmd := s5cmd("cp", "--restore-if-needed", src, ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v`, src, filename),
+	})
+
+	expected := fs.Expected(t, fs.WithFile(filename, content, fs.WithMode(0644)))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
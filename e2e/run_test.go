@@ -350,3 +350,80 @@ func TestRunFixDataRace_Issue301(t *testing.T) {
 
 	assertLines(t, result.Stderr(), map[int]compareFunc{})
 }
+
+func TestRunWithMemoryStorage(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, "runmem", fs.WithFile("file.txt", "content"))
+	defer workdir.Remove()
+
+	input := strings.NewReader(
+		strings.Join([]string{
+			fmt.Sprintf("cp %v mem://scratch/file.txt", workdir.Join("file.txt")),
+			fmt.Sprintf("cp %v mem://scratch/copy.txt", workdir.Join("file.txt")),
+		}, "\n"),
+	)
+	cmd := s5cmd("run")
+	result := icmd.RunCmd(cmd, icmd.WithStdin(input))
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v mem://scratch/copy.txt`, workdir.Join("file.txt")),
+		1: equals(`cp %v mem://scratch/file.txt`, workdir.Join("file.txt")),
+	}, sortInput(true))
+}
+
+func TestRunWithCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content")
+
+	lines := strings.Join([]string{
+		fmt.Sprintf("ls s3://%v/file1.txt", bucket),
+		fmt.Sprintf("ls s3://%v/file2.txt", bucket),
+	}, "\n")
+
+	workdir := fs.NewDir(t, "checkpoint", fs.WithFile("commands.txt", lines))
+	defer workdir.Remove()
+
+	checkpoint := workdir.Join("commands.checkpoint")
+
+	cmd := s5cmd("run", "--checkpoint", checkpoint, workdir.Join("commands.txt"))
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("file1.txt"),
+	})
+
+	// the failed line is retried, and the completed one is skipped.
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	cmd = s5cmd("run", "--checkpoint", checkpoint, workdir.Join("commands.txt"))
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("file2.txt"),
+	})
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	// all lines are completed.
+	cmd = s5cmd("run", "--checkpoint", checkpoint, workdir.Join("commands.txt"))
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
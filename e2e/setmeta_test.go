@@ -0,0 +1,138 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestSetmetaCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no metadata flags",
+			args:     []string{"setmeta", "s3://bucket/object"},
+			expected: `ERROR "setmeta s3://bucket/object": at least one of the metadata flags must be given`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"setmeta", "--content-type", "text/plain", "file.txt"},
+			expected: `ERROR "setmeta --content-type=text/plain file.txt": source must be a remote object`,
+		},
+		{
+			name:     "invalid expires",
+			args:     []string{"setmeta", "--expires", "tomorrow", "s3://bucket/object"},
+			expected: `ERROR "setmeta --expires=tomorrow s3://bucket/object": invalid expires "tomorrow": must be in RFC3339 format`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestSetmetaWildcard(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const content = "this is a file content"
+
+	putFile(t, s3client, bucket, "file1.css", content)
+	putFile(t, s3client, bucket, "file2.css", content)
+	putFile(t, s3client, bucket, "file3.txt", content)
+
+	cmd := s5cmd("setmeta", "--content-type", "text/css", "--cache-control", "no-cache",
+		"--metadata", "project=s5cmd", fmt.Sprintf("s3://%v/*.css", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`setmeta s3://%v/file1.css`, bucket),
+		1: equals(`setmeta s3://%v/file2.css`, bucket),
+	}, sortInput(true))
+
+	cmd = s5cmd("stat", fmt.Sprintf("s3://%v/file1.css", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("URL: s3://%v/file1.css", bucket),
+		1: equals("Size: 22 (22 bytes)"),
+		2: prefix("Last-Modified: "),
+		3: prefix("ETag: "),
+		4: equals("Content-Type: text/css"),
+		5: equals("Cache-Control: no-cache"),
+		6: equals("Metadata.project: s5cmd"),
+	})
+
+	// the objects that does not match the wildcard are not changed.
+	cmd = s5cmd("stat", fmt.Sprintf("s3://%v/file3.txt", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("URL: s3://%v/file3.txt", bucket),
+		1: equals("Size: 22 (22 bytes)"),
+		2: prefix("Last-Modified: "),
+		3: prefix("ETag: "),
+	})
+}
+
+// TestSetmetaKeepsMetadata tests that the user metadata of the objects which
+// are not changed are kept.
+func TestSetmetaKeepsMetadata(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/file.txt", bucket)
+	cmd := s5cmd("cp", "--content-type", "text/plain", "--metadata", "owner=peak", workdir.Join("file.txt"), dst)
+	icmd.RunCmd(cmd).Assert(t, icmd.Success)
+
+	cmd = s5cmd("setmeta", "--metadata", "project=s5cmd", dst)
+	icmd.RunCmd(cmd).Assert(t, icmd.Success)
+
+	cmd = s5cmd("stat", dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("URL: %v", dst),
+		1: equals("Size: 7 (7 bytes)"),
+		2: prefix("Last-Modified: "),
+		3: prefix("ETag: "),
+		4: equals("Content-Type: text/plain"),
+		5: equals("Metadata.owner: peak"),
+		6: equals("Metadata.project: s5cmd"),
+	})
+}
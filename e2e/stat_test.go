@@ -0,0 +1,123 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestStatCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no source",
+			args:     []string{"stat"},
+			expected: `ERROR "stat": expected remote object url`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"stat", "file.txt"},
+			expected: `ERROR "stat file.txt": source must be a remote object`,
+		},
+		{
+			name:     "bucket source",
+			args:     []string{"stat", "s3://bucket"},
+			expected: `ERROR "stat s3://bucket": remote source must be an object`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestStatSingleObject(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "file1.txt"
+		content  = "this is a file content"
+	)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/%v", bucket, filename)
+	cmd := s5cmd("cp", "--content-type", "text/plain", "--cache-control", "no-cache",
+		"--metadata", "project=s5cmd", workdir.Join(filename), dst)
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	cmd = s5cmd("stat", dst)
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("URL: %v", dst),
+		1: equals("Size: 22 (22 bytes)"),
+		2: match(`^Last-Modified: \d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}$`),
+		3: equals("ETag: 7d8e0b1ca1e0d75129e48c2ee59fb371"),
+		4: equals("Content-Type: text/plain"),
+		5: equals("Cache-Control: no-cache"),
+		6: equals("Metadata.project: s5cmd"),
+	})
+}
+
+func TestStatWildcardJSON(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "file1.txt", "content", putArbitraryMetadata(map[string]*string{
+		"Project": aws.String("s5cmd"),
+	}))
+	putFile(t, s3client, bucket, "file2.txt", "content")
+	putFile(t, s3client, bucket, "other.txt", "content")
+
+	cmd := s5cmd("--json", "stat", fmt.Sprintf("s3://%v/file*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: contains(`"key":"s3://%v/file1.txt"`, bucket),
+		1: contains(`"key":"s3://%v/file2.txt"`, bucket),
+	}, sortInput(true))
+	assert.Assert(t, strings.Contains(result.Stdout(), `"metadata":{"project":"s5cmd"}`))
+
+	// the user metadata is only printed by stat.
+	cmd = s5cmd("--json", "ls", fmt.Sprintf("s3://%v/file1.txt", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+	assert.Assert(t, !strings.Contains(result.Stdout(), `"metadata"`))
+}
@@ -1074,6 +1074,80 @@ func TestSyncS3BucketToS3BucketSizeOnly(t *testing.T) {
 	}
 }
 
+// sync --checksum s3://bucket/* folder/
+func TestSyncS3BucketToLocalFolderChecksum(t *testing.T) {
+	t.Parallel()
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("testfile.txt", "D: this is a test file"),
+		fs.WithFile("readme.md", "S: this is a readme file"),
+		fs.WithDir("a",
+			fs.WithFile("another_test_file.txt", "D: yet another txt file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, "somedir", folderLayout...)
+	defer workdir.Remove()
+
+	S3Content := map[string]string{
+		"testfile.txt":            "S: this is a test file",   // content different from local, same size
+		"readme.md":               "S: this is a readme file", // same content with local
+		"a/another_test_file.txt": "S: yet another txt file",  // content different from local, same size
+	}
+
+	for filename, content := range S3Content {
+		putFile(t, s3client, bucket, filename, content)
+	}
+
+	bucketPath := fmt.Sprintf("s3://%v", bucket)
+	src := fmt.Sprintf("%s/*", bucketPath)
+	dst := fmt.Sprintf("%v/", workdir.Path())
+	dst = filepath.ToSlash(dst)
+
+	// log debug
+	cmd := s5cmd("--log", "debug", "sync", "--checksum", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "sync %v/readme.md %vreadme.md": object checksum matches`, bucketPath, dst),
+		1: equals(`cp %v/a/another_test_file.txt %va/another_test_file.txt`, bucketPath, dst),
+		2: equals(`cp %v/testfile.txt %vtestfile.txt`, bucketPath, dst),
+	}, sortInput(true))
+
+	expectedFolderLayout := []fs.PathOp{
+		fs.WithFile("testfile.txt", "S: this is a test file"),
+		fs.WithFile("readme.md", "S: this is a readme file"),
+		fs.WithDir("a",
+			fs.WithFile("another_test_file.txt", "S: yet another txt file"),
+		),
+	}
+
+	// expected folder structure without the timestamp.
+	expected := fs.Expected(t, expectedFolderLayout...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// sync --size-only --checksum s3://bucket/* folder/
+func TestSyncSizeOnlyAndChecksumFlagsCannotBeUsedTogether(t *testing.T) {
+	t.Parallel()
+	_, s5cmd := setup(t)
+
+	cmd := s5cmd("sync", "--size-only", "--checksum", "s3://bucket/*", "folder/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "sync --size-only=true --checksum=true s3://bucket/* folder/": "size-only" and "checksum" flags cannot be used together`),
+	})
+}
+
 // sync --delete s3://bucket/* .
 func TestSyncS3BucketToLocalWithDelete(t *testing.T) {
 	t.Parallel()
@@ -2048,3 +2122,269 @@ func TestSyncS3ObjectsIntoAnotherBucketWithIncludeFilters(t *testing.T) {
 		assertError(t, err, errS3NoSuchKey)
 	}
 }
+
+// sync --bidirectional dir/ s3://bucket/
+func TestSyncBidirectional(t *testing.T) {
+	t.Parallel()
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "remote.txt", "S: remote file")
+
+	workdir := fs.NewDir(t, "somedir",
+		fs.WithFile("local.txt", "D: local file"),
+		fs.WithFile("deleted.txt", "D: deleted file"),
+	)
+	defer workdir.Remove()
+
+	cachedir := fs.NewDir(t, "cache")
+	defer cachedir.Remove()
+
+	src := filepath.ToSlash(workdir.Path()) + "/"
+	dst := fmt.Sprintf("s3://%v/", bucket)
+
+	// the first sync copies the objects which are missing on either side.
+	cmd := s5cmd("sync", "--bidirectional", src, dst)
+	result := icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %vdeleted.txt %vdeleted.txt`, src, dst),
+		1: equals(`cp %vlocal.txt %vlocal.txt`, src, dst),
+		2: equals(`cp %vremote.txt %vremote.txt`, dst, src),
+	}, sortInput(true))
+
+	// the second sync propagates the changes on each side since the first.
+	assert.NilError(t, os.Remove(workdir.Join("deleted.txt")))
+	putFile(t, s3client, bucket, "remote.txt", "S: remote file is changed")
+	putFile(t, s3client, bucket, "new.txt", "S: new file")
+
+	result = icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %vnew.txt %vnew.txt`, dst, src),
+		1: equals(`cp %vremote.txt %vremote.txt`, dst, src),
+		2: equals(`rm %vdeleted.txt`, dst),
+	}, sortInput(true))
+
+	expected := fs.Expected(t,
+		fs.WithFile("local.txt", "D: local file"),
+		fs.WithFile("new.txt", "S: new file"),
+		fs.WithFile("remote.txt", "S: remote file is changed"),
+	)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "local.txt", "D: local file"))
+	err := ensureS3Object(s3client, bucket, "deleted.txt", "D: deleted file")
+	assertError(t, err, errS3NoSuchKey)
+
+	// nothing to do when neither side is changed.
+	result = icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
+
+// sync --bidirectional --conflict <policy> dir/ s3://bucket/
+func TestSyncBidirectionalConflict(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		policy         string
+		expectedStdout map[int]compareFunc
+		expectedStderr map[int]compareFunc
+		expectedLocal  []fs.PathOp
+		expectedRemote map[string]string
+	}{
+		{
+			name:           "skip",
+			policy:         "skip",
+			expectedStdout: map[int]compareFunc{},
+			expectedStderr: map[int]compareFunc{
+				0: contains(`file.txt": object is changed on both sides`),
+			},
+			expectedLocal: []fs.PathOp{
+				fs.WithFile("file.txt", "D: local change"),
+			},
+			expectedRemote: map[string]string{
+				"file.txt": "S: remote change",
+			},
+		},
+		{
+			name:   "newer wins",
+			policy: "newer-wins",
+			expectedStdout: map[int]compareFunc{
+				0: contains(`file.txt`),
+			},
+			expectedStderr: map[int]compareFunc{},
+			expectedLocal: []fs.PathOp{
+				fs.WithFile("file.txt", "S: remote change"),
+			},
+			expectedRemote: map[string]string{
+				"file.txt": "S: remote change",
+			},
+		},
+		{
+			name:   "keep both",
+			policy: "keep-both",
+			expectedStdout: map[int]compareFunc{
+				0: suffix(`file.txt`),
+				1: suffix(`file-conflict.txt`),
+				2: suffix(`file-conflict.txt`),
+			},
+			expectedStderr: map[int]compareFunc{},
+			expectedLocal: []fs.PathOp{
+				fs.WithFile("file.txt", "D: local change"),
+				fs.WithFile("file-conflict.txt", "S: remote change"),
+			},
+			expectedRemote: map[string]string{
+				"file.txt":          "D: local change",
+				"file-conflict.txt": "S: remote change",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s3client, s5cmd := setup(t)
+
+			bucket := s3BucketFromTestName(t)
+			createBucket(t, s3client, bucket)
+
+			workdir := fs.NewDir(t, "somedir", fs.WithFile("file.txt", "D: file"))
+			defer workdir.Remove()
+
+			cachedir := fs.NewDir(t, "cache")
+			defer cachedir.Remove()
+
+			src := filepath.ToSlash(workdir.Path()) + "/"
+			dst := fmt.Sprintf("s3://%v/", bucket)
+
+			cmd := s5cmd("sync", "--bidirectional", src, dst)
+			result := icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+			result.Assert(t, icmd.Success)
+
+			// change the object on both sides, the remote one is the newer.
+			past := time.Now().Add(-time.Hour)
+			assert.NilError(t, os.WriteFile(workdir.Join("file.txt"), []byte("D: local change"), 0o644))
+			assert.NilError(t, os.Chtimes(workdir.Join("file.txt"), past, past))
+			putFile(t, s3client, bucket, "file.txt", "S: remote change")
+
+			cmd = s5cmd("sync", "--bidirectional", "--conflict", tc.policy, src, dst)
+			result = icmd.RunCmd(cmd, withEnv("XDG_CACHE_HOME", cachedir.Path()))
+
+			if tc.policy == "skip" {
+				result.Assert(t, icmd.Expected{ExitCode: 1})
+			} else {
+				result.Assert(t, icmd.Success)
+			}
+
+			assertLines(t, result.Stdout(), tc.expectedStdout, sortInput(true))
+			assertLines(t, result.Stderr(), tc.expectedStderr)
+
+			expected := fs.Expected(t, tc.expectedLocal...)
+			assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+			for key, content := range tc.expectedRemote {
+				assert.Assert(t, ensureS3Object(s3client, bucket, key, content))
+			}
+		})
+	}
+}
+
+func TestSyncBidirectionalAndDeleteFlagsCannotBeUsedTogether(t *testing.T) {
+	t.Parallel()
+	_, s5cmd := setup(t)
+
+	cmd := s5cmd("sync", "--delete", "--bidirectional", "s3://bucket/*", "folder/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "sync --delete=true --bidirectional=true s3://bucket/* folder/": "bidirectional" and "delete" flags cannot be used together`),
+	})
+}
+
+// sync --state-file index.jsonl s3://srcbucket/* s3://dstbucket/
+func TestSyncWithStateFile(t *testing.T) {
+	t.Parallel()
+	s3client, s5cmd := setup(t)
+
+	srcbucket := s3BucketFromTestNameWithPrefix(t, "src")
+	dstbucket := s3BucketFromTestNameWithPrefix(t, "dst")
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, s3client, srcbucket, "a.txt", "S: a")
+	putFile(t, s3client, srcbucket, "b.txt", "S: b")
+
+	statedir := fs.NewDir(t, "state")
+	defer statedir.Remove()
+	stateFile := statedir.Join("index.jsonl")
+
+	src := fmt.Sprintf("s3://%v/", srcbucket)
+	dst := fmt.Sprintf("s3://%v/", dstbucket)
+
+	cmd := s5cmd("sync", "--delete", "--state-file", stateFile, src+"*", dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %va.txt %va.txt`, src, dst),
+		1: equals(`cp %vb.txt %vb.txt`, src, dst),
+	}, sortInput(true))
+
+	// the index records the destination listing after the sync.
+	index, err := os.ReadFile(stateFile)
+	assert.NilError(t, err)
+	assertLines(t, string(index), map[int]compareFunc{
+		0: contains(`"count":2`),
+		1: contains(`"key":"a.txt"`),
+		2: contains(`"key":"b.txt"`),
+	})
+
+	// objects created by other tools are not known by the index, thus they
+	// are not deleted as long as the index is consistent with the
+	// destination.
+	putFile(t, s3client, dstbucket, "external.txt", "D: external")
+	putFile(t, s3client, srcbucket, "c.txt", "S: c")
+
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %vc.txt %vc.txt`, src, dst),
+	})
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "external.txt", "D: external"))
+
+	// the index is discarded once an object in it is changed by other tools.
+	putFile(t, s3client, dstbucket, "b.txt", "D: b is changed")
+
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %vb.txt %vb.txt`, src, dst),
+		1: equals(`rm %vexternal.txt`, dst),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "b.txt", "S: b"))
+
+	index, err = os.ReadFile(stateFile)
+	assert.NilError(t, err)
+	assertLines(t, string(index), map[int]compareFunc{
+		0: contains(`"count":3`),
+		1: contains(`"key":"a.txt"`),
+		2: contains(`"key":"b.txt"`),
+		3: contains(`"key":"c.txt"`),
+	})
+}
@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func TestTagCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "set without tags",
+			args:     []string{"set", "s3://bucket/object"},
+			expected: `ERROR "tag set s3://bucket/object": "tag" flag is required`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"get", "file.txt"},
+			expected: `ERROR "tag get file.txt": source must be a remote object`,
+		},
+		{
+			name:     "prefix source",
+			args:     []string{"delete", "s3://bucket/prefix/"},
+			expected: `ERROR "tag delete s3://bucket/prefix/": remote source must be an object`,
+		},
+		{
+			name:     "no source",
+			args:     []string{"get"},
+			expected: `ERROR "tag get": expected remote object url`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(append([]string{"tag"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopySingleFileToS3WithTags(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd := setup(t)
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile.txt"
+		content  = "this is a file content"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	srcpath := workdir.Join(filename)
+	dstpath := fmt.Sprintf("s3://%v/%v", bucket, filename)
+
+	cmd := s5cmd("cp", "--tag", "project=s5cmd", "--tag", "retention=30d", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v`, srcpath, dstpath),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
@@ -0,0 +1,131 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/icmd"
+)
+
+func TestUndeleteCommandValidation(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd := setup(t)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no source",
+			args:     []string{"undelete"},
+			expected: `ERROR "undelete": expected remote object url`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"undelete", "dir/file.txt"},
+			expected: `ERROR "undelete dir/file.txt": source must be a remote object`,
+		},
+		{
+			name:     "bucket source",
+			args:     []string{"undelete", "s3://bucket"},
+			expected: `ERROR "undelete s3://bucket": remote source must be an object or a prefix`,
+		},
+		{
+			name:     "invalid time",
+			args:     []string{"undelete", "--after", "yesterday", "s3://bucket/*"},
+			expected: `ERROR "undelete --after=yesterday s3://bucket/*": invalid time "yesterday": must be in RFC3339 format`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+func TestUndelete(t *testing.T) {
+	t.Parallel()
+
+	timeSource := newFixedTimeSource(time.Now().Add(-time.Hour).UTC())
+	s3client, s5cmd := setup(t, withS3Backend("mem"), withTimeSource(timeSource))
+
+	bucket := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucket)
+	setBucketVersioning(t, s3client, bucket, "Enabled")
+
+	putFile(t, s3client, bucket, "a.txt", "a content")
+	putFile(t, s3client, bucket, "b.txt", "b content")
+	putFile(t, s3client, bucket, "c.txt", "c content")
+
+	deleteObject := func(key string) {
+		t.Helper()
+
+		timeSource.Advance(time.Minute)
+		_, err := s3client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteObject("a.txt")
+	timeSource.Advance(time.Minute)
+	after := timeSource.Now().Format(time.RFC3339)
+	deleteObject("b.txt")
+
+	// only the objects which are deleted after the given time are undeleted.
+	cmd := s5cmd("undelete", "--after", after, fmt.Sprintf("s3://%v/*", bucket))
+	result := icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^undelete s3://%v/b.txt \S+$`, bucket)),
+	})
+
+	cmd = s5cmd("--dry-run", "undelete", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^undelete s3://%v/a.txt \S+$`, bucket)),
+	})
+
+	cmd = s5cmd("undelete", fmt.Sprintf("s3://%v/*", bucket))
+	result = icmd.RunCmd(cmd)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^undelete s3://%v/a.txt \S+$`, bucket)),
+	})
+
+	// the fake S3 server does not make the previous versions current when
+	// the delete markers are removed, so only the removal of the markers is
+	// checked.
+	output, err := s3client.ListObjectVersions(&s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.DeleteMarkers) != 0 {
+		t.Errorf("expected no delete markers, got %v", output.DeleteMarkers)
+	}
+}
@@ -0,0 +1,287 @@
+// Package encryption implements client-side envelope encryption of objects.
+//
+// Each object is encrypted with its own random data key, which is wrapped
+// with a key encryption key and stored in the user metadata of the object.
+// The key encryption key is either read from a key file or derived from a
+// passphrase.
+//
+// The content is encrypted with AES-256-GCM in chunks of ChunkSize bytes. A
+// chunk is sealed with a nonce derived from the nonce of the object and the
+// index of the chunk, and the last chunk is marked as such, so that chunks
+// can neither be reordered nor truncated. Since the size of every encrypted
+// chunk is fixed, any byte range of an object maps to a known range of
+// chunks, and chunks can be encrypted and decrypted independently of each
+// other.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Algorithm identifies the format of the encrypted objects.
+	Algorithm = "AES256-GCM-64K"
+
+	// ChunkSize is the size of the chunks which are encrypted separately.
+	ChunkSize = 64 * 1024
+
+	tagSize         = 16
+	nonceSize       = 12
+	dataKeySize     = 32
+	chunkCipherSize = ChunkSize + tagSize
+)
+
+// Keys of the user metadata of the encrypted objects.
+const (
+	MetadataAlgorithm = "s5cmd-cse-algorithm"
+	MetadataKey       = "s5cmd-cse-key"
+	MetadataNonce     = "s5cmd-cse-nonce"
+	MetadataKDF       = "s5cmd-cse-kdf"
+)
+
+var (
+	// ErrNoKey indicates that an object is encrypted, but no key is given
+	// to decrypt it.
+	ErrNoKey = errors.New("object is encrypted on the client side, a key is required to decrypt it")
+
+	// ErrWrongKey indicates that the data key of an object can not be
+	// unwrapped with the given key.
+	ErrWrongKey = errors.New("object is encrypted with a different key")
+
+	// ErrCorrupted indicates that an encrypted object is modified or
+	// truncated.
+	ErrCorrupted = errors.New("encrypted object is corrupted")
+)
+
+// IsEncrypted reports whether the object with the given user metadata is
+// encrypted on the client side.
+func IsEncrypted(metadata map[string]string) bool {
+	return lookup(metadata, MetadataAlgorithm) != ""
+}
+
+// Metadata returns the encryption metadata within the given user metadata.
+func Metadata(metadata map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, key := range []string{MetadataAlgorithm, MetadataKey, MetadataNonce, MetadataKDF} {
+		if value := lookup(metadata, key); value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// lookup returns the value of the key in the metadata, ignoring the case of
+// the keys as some services capitalize them.
+func lookup(metadata map[string]string, key string) string {
+	if value, ok := metadata[key]; ok {
+		return value
+	}
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// numChunks returns the number of chunks of a plaintext of the given size. An
+// empty plaintext has a single empty chunk, so that it is authenticated too.
+func numChunks(size int64) int64 {
+	if size == 0 {
+		return 1
+	}
+	return (size + ChunkSize - 1) / ChunkSize
+}
+
+// CiphertextSize returns the size of the encrypted object of a plaintext of
+// the given size.
+func CiphertextSize(size int64) int64 {
+	return size + numChunks(size)*tagSize
+}
+
+// PlaintextSize returns the size of the plaintext of an encrypted object of
+// the given size. It reports false if no plaintext is encrypted into the
+// given size.
+func PlaintextSize(size int64) (int64, bool) {
+	if size < tagSize {
+		return 0, false
+	}
+
+	full, rest := size/chunkCipherSize, size%chunkCipherSize
+	switch {
+	case rest == 0:
+		return full * ChunkSize, true
+	case rest == tagSize && full > 0, rest < tagSize:
+		return 0, false
+	default:
+		return full*ChunkSize + rest - tagSize, true
+	}
+}
+
+// Envelope encrypts and decrypts the content of an object with its data
+// key.
+type Envelope struct {
+	aead  cipher.AEAD
+	nonce [nonceSize]byte
+}
+
+func newEnvelope(dataKey, nonce []byte) (*Envelope, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != nonceSize {
+		return nil, ErrCorrupted
+	}
+
+	e := &Envelope{aead: aead}
+	copy(e.nonce[:], nonce)
+	return e, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the chunk with the given index.
+func (e *Envelope) chunkNonce(index int64) []byte {
+	nonce := e.nonce
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(index))
+	for i := range counter {
+		nonce[nonceSize-8+i] ^= counter[i]
+	}
+	return nonce[:]
+}
+
+func chunkAAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func (e *Envelope) sealChunk(dst, plaintext []byte, index int64, final bool) []byte {
+	return e.aead.Seal(dst, e.chunkNonce(index), plaintext, chunkAAD(final))
+}
+
+func (e *Envelope) openChunk(dst, ciphertext []byte, index int64, final bool) ([]byte, error) {
+	plaintext, err := e.aead.Open(dst, e.chunkNonce(index), ciphertext, chunkAAD(final))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	return plaintext, nil
+}
+
+// wrapKey encrypts the data key with the key encryption key.
+func wrapKey(kek, dataKey []byte) (string, error) {
+	aead, err := newGCM(kek)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	wrapped := aead.Seal(nonce, nonce, dataKey, []byte(Algorithm))
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// unwrapKey decrypts the data key with the key encryption key.
+func unwrapKey(kek []byte, wrapped string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil || len(data) < nonceSize {
+		return nil, ErrCorrupted
+	}
+
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(Algorithm))
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return dataKey, nil
+}
+
+// NewEnvelope creates an envelope with a new data key, and returns it with
+// the user metadata to be stored with the encrypted object.
+func (k *Key) NewEnvelope() (*Envelope, map[string]string, error) {
+	dataKey := make([]byte, dataKeySize)
+	nonce := make([]byte, nonceSize)
+	for _, b := range [][]byte{dataKey, nonce} {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	kek, kdf, err := k.encryptionKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := wrapKey(kek, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envelope, err := newEnvelope(dataKey, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := map[string]string{
+		MetadataAlgorithm: Algorithm,
+		MetadataKey:       wrapped,
+		MetadataNonce:     base64.StdEncoding.EncodeToString(nonce),
+	}
+	if kdf != "" {
+		metadata[MetadataKDF] = kdf
+	}
+	return envelope, metadata, nil
+}
+
+// OpenEnvelope unwraps the data key of the object with the given user
+// metadata, and returns the envelope to decrypt its content.
+func (k *Key) OpenEnvelope(metadata map[string]string) (*Envelope, error) {
+	if k == nil {
+		return nil, ErrNoKey
+	}
+
+	if algorithm := lookup(metadata, MetadataAlgorithm); algorithm != Algorithm {
+		return nil, fmt.Errorf("unsupported client-side encryption algorithm %q", algorithm)
+	}
+
+	kek, err := k.decryptionKey(lookup(metadata, MetadataKDF))
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := unwrapKey(kek, lookup(metadata, MetadataKey))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(lookup(metadata, MetadataNonce))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+
+	return newEnvelope(dataKey, nonce)
+}
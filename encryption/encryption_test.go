@@ -0,0 +1,323 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writerAt is an in-memory io.WriterAt.
+type writerAt struct {
+	buf []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(w.buf) {
+		w.buf = append(w.buf, make([]byte, end-len(w.buf))...)
+	}
+	return copy(w.buf[off:], p), nil
+}
+
+func newTestKey(t *testing.T) *Key {
+	t.Helper()
+
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return &Key{key: key}
+}
+
+func encrypt(t *testing.T, envelope *Envelope, plaintext []byte) []byte {
+	t.Helper()
+
+	ciphertext, err := io.ReadAll(envelope.NewEncryptingReader(bytes.NewReader(plaintext)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ciphertext
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	sizes := []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 100}
+
+	key := newTestKey(t)
+	for _, size := range sizes {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatal(err)
+		}
+
+		envelope, metadata, err := key.NewEnvelope()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ciphertext := encrypt(t, envelope, plaintext)
+		if got, expected := int64(len(ciphertext)), CiphertextSize(int64(size)); got != expected {
+			t.Fatalf("size %v: expected ciphertext size %v, got %v", size, expected, got)
+		}
+		if got, ok := PlaintextSize(int64(len(ciphertext))); !ok || got != int64(size) {
+			t.Fatalf("size %v: expected plaintext size %v, got %v", size, size, got)
+		}
+
+		envelope, err = key.OpenEnvelope(metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// write the ciphertext in parts of sizes which are not aligned with
+		// the chunks, backwards, and write the first part twice as if it is
+		// retried.
+		w := &writerAt{}
+		dw, err := envelope.NewDecryptingWriterAt(w, int64(len(ciphertext)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		const partSize = 50000
+		for off := (len(ciphertext) - 1) / partSize * partSize; off >= 0; off -= partSize {
+			end := off + partSize
+			if end > len(ciphertext) {
+				end = len(ciphertext)
+			}
+			if _, err := dw.WriteAt(ciphertext[off:end], int64(off)); err != nil {
+				t.Fatalf("size %v: %v", size, err)
+			}
+		}
+		if _, err := dw.WriteAt(ciphertext[:1], 0); err != nil {
+			t.Fatalf("size %v: %v", size, err)
+		}
+		if err := dw.Close(); err != nil {
+			t.Fatalf("size %v: %v", size, err)
+		}
+
+		if !bytes.Equal(w.buf, plaintext) && !(size == 0 && len(w.buf) == 0) {
+			t.Fatalf("size %v: decrypted content does not match", size)
+		}
+		if dw.Size() != int64(size) {
+			t.Fatalf("size %v: expected size %v, got %v", size, size, dw.Size())
+		}
+	}
+}
+
+func TestDecryptCorrupted(t *testing.T) {
+	key := newTestKey(t)
+	envelope, _, err := key.NewEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := encrypt(t, envelope, make([]byte, 2*ChunkSize+10))
+
+	decrypt := func(ciphertext []byte) error {
+		dw, err := envelope.NewDecryptingWriterAt(&writerAt{}, int64(len(ciphertext)))
+		if err != nil {
+			return err
+		}
+		if _, err := dw.WriteAt(ciphertext, 0); err != nil {
+			return err
+		}
+		return dw.Close()
+	}
+
+	// modified
+	modified := append([]byte(nil), ciphertext...)
+	modified[ChunkSize+5] ^= 1
+	if err := decrypt(modified); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("modified: expected %v, got %v", ErrCorrupted, err)
+	}
+
+	// truncated at a chunk boundary
+	if err := decrypt(ciphertext[:2*chunkCipherSize]); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("truncated: expected %v, got %v", ErrCorrupted, err)
+	}
+
+	// chunks reordered
+	reordered := append([]byte(nil), ciphertext[chunkCipherSize:2*chunkCipherSize]...)
+	reordered = append(reordered, ciphertext[:chunkCipherSize]...)
+	reordered = append(reordered, ciphertext[2*chunkCipherSize:]...)
+	if err := decrypt(reordered); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("reordered: expected %v, got %v", ErrCorrupted, err)
+	}
+
+	// incomplete
+	dw, err := envelope.NewDecryptingWriterAt(&writerAt{}, int64(len(ciphertext)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dw.WriteAt(ciphertext[:chunkCipherSize], 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dw.Close(); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("incomplete: expected %v, got %v", ErrCorrupted, err)
+	}
+}
+
+func TestOpenEnvelopeWithWrongKey(t *testing.T) {
+	_, metadata, err := newTestKey(t).NewEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newTestKey(t).OpenEnvelope(metadata); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected %v, got %v", ErrWrongKey, err)
+	}
+
+	var nokey *Key
+	if _, err := nokey.OpenEnvelope(metadata); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected %v, got %v", ErrNoKey, err)
+	}
+}
+
+func TestPassphraseKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(path, []byte("correct horse battery staple\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadPassphraseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, metadata, err := key.NewEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata[MetadataKDF] == "" {
+		t.Fatal("expected the key derivation parameters in the metadata")
+	}
+
+	// a different process derives the key with the salt in the metadata.
+	other := &Key{passphrase: []byte("correct horse battery staple"), derived: map[string][]byte{}}
+	if _, err := other.OpenEnvelope(metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	// keys of files are not mixed with passphrases.
+	if _, err := newTestKey(t).OpenEnvelope(metadata); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected %v, got %v", ErrWrongKey, err)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	key := bytes.Repeat([]byte{0xab}, dataKeySize)
+
+	testcases := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{name: "raw", content: key},
+		{name: "hex", content: []byte(hex.EncodeToString(key) + "\n")},
+		{name: "base64", content: []byte("q6urq6urq6urq6urq6urq6urq6urq6urq6urq6urq6s=")},
+		{name: "short", content: []byte("abcd"), wantErr: true},
+	}
+
+	dir := t.TempDir()
+	for _, tc := range testcases {
+		path := filepath.Join(dir, tc.name)
+		if err := os.WriteFile(path, tc.content, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := LoadKeyFile(path)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%v: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !bytes.Equal(got.key, key) {
+			t.Errorf("%v: expected key %x, got %x", tc.name, key, got.key)
+		}
+	}
+}
+
+func TestParseKDF(t *testing.T) {
+	testcases := []struct {
+		name    string
+		kdf     string
+		wantErr bool
+	}{
+		{name: "default iterations", kdf: "pbkdf2-sha256:200000:c2FsdA=="},
+		{name: "max iterations", kdf: "pbkdf2-sha256:800000:c2FsdA=="},
+		{name: "oversized iterations", kdf: "pbkdf2-sha256:2000000000:c2FsdA==", wantErr: true},
+		{name: "zero iterations", kdf: "pbkdf2-sha256:0:c2FsdA==", wantErr: true},
+		{name: "unknown function", kdf: "scrypt:200000:c2FsdA==", wantErr: true},
+		{name: "invalid salt", kdf: "pbkdf2-sha256:200000:not base64!", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		_, _, err := parseKDF(tc.kdf)
+		if tc.wantErr && err == nil {
+			t.Errorf("%v: expected error", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, err)
+		}
+	}
+
+	// the key is not derived for an oversized iteration count.
+	key := &Key{passphrase: []byte("passphrase"), derived: map[string][]byte{}}
+	_, metadata, err := key.NewEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata[MetadataKDF] = "pbkdf2-sha256:2000000000:c2FsdA=="
+	if _, err := key.OpenEnvelope(metadata); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// test vectors of PBKDF2-HMAC-SHA256
+	testcases := []struct {
+		iterations int
+		expected   string
+	}{
+		{iterations: 1, expected: "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{iterations: 2, expected: "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{iterations: 4096, expected: "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, tc := range testcases {
+		got := hex.EncodeToString(pbkdf2([]byte("password"), []byte("salt"), tc.iterations, 32))
+		if got != tc.expected {
+			t.Errorf("iterations %v: expected %v, got %v", tc.iterations, tc.expected, got)
+		}
+	}
+}
+
+func TestPlaintextSize(t *testing.T) {
+	testcases := []struct {
+		size     int64
+		expected int64
+		ok       bool
+	}{
+		{size: 0, ok: false},
+		{size: tagSize - 1, ok: false},
+		{size: tagSize, expected: 0, ok: true},
+		{size: chunkCipherSize, expected: ChunkSize, ok: true},
+		{size: chunkCipherSize + tagSize, ok: false},
+		{size: chunkCipherSize + tagSize + 1, expected: ChunkSize + 1, ok: true},
+	}
+
+	for _, tc := range testcases {
+		got, ok := PlaintextSize(tc.size)
+		if ok != tc.ok || got != tc.expected {
+			t.Errorf("size %v: expected (%v, %v), got (%v, %v)", tc.size, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
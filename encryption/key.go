@@ -0,0 +1,211 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kdfPBKDF2     = "pbkdf2-sha256"
+	kdfIterations = 200000
+	// kdfMaxIterations bounds the iterations read from the metadata of the
+	// objects, so that an object cannot make the derivation run for hours.
+	kdfMaxIterations = 4 * kdfIterations
+	kdfSaltSize      = 16
+)
+
+// Key is a key encryption key, which wraps the data keys of the objects.
+type Key struct {
+	// key is the key read from a key file.
+	key []byte
+
+	// passphrase is the passphrase which the keys are derived from, and
+	// salt is the salt of the key which wraps the new data keys.
+	passphrase []byte
+	salt       []byte
+
+	mu      sync.Mutex
+	derived map[string][]byte
+}
+
+// keys caches the loaded keys by their files, as the keys derived from
+// passphrases are expensive to compute.
+var keys sync.Map
+
+// LoadKeyFile loads the 256-bit key in the given file. The file contains
+// either the 32 bytes of the key, or the key encoded in hex or base64.
+func LoadKeyFile(path string) (*Key, error) {
+	return load("key:", path, func(data []byte) (*Key, error) {
+		key, err := ParseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %q: %w", path, err)
+		}
+		return &Key{key: key}, nil
+	})
+}
+
+// LoadPassphraseFile loads the passphrase in the given file, which the keys
+// are derived from. Trailing newlines of the file are ignored.
+func LoadPassphraseFile(path string) (*Key, error) {
+	return load("passphrase:", path, func(data []byte) (*Key, error) {
+		passphrase := bytes.TrimRight(data, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("passphrase file %q is empty", path)
+		}
+
+		salt := make([]byte, kdfSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+		return &Key{passphrase: passphrase, salt: salt, derived: map[string][]byte{}}, nil
+	})
+}
+
+func load(kind, path string, parse func([]byte) (*Key, error)) (*Key, error) {
+	abspath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := kind + abspath
+	if key, ok := keys.Load(cacheKey); ok {
+		return key.(*Key), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := keys.LoadOrStore(cacheKey, key)
+	return actual.(*Key), nil
+}
+
+// ParseKey parses a 256-bit key given as 32 bytes, or encoded in hex or
+// base64.
+func ParseKey(data []byte) ([]byte, error) {
+	if len(data) == dataKeySize {
+		return data, nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == dataKeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == dataKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("expected a 256-bit key as 32 bytes, hex or base64")
+}
+
+// encryptionKey returns the key to wrap new data keys, and the parameters
+// which it is derived with, if any.
+func (k *Key) encryptionKey() ([]byte, string, error) {
+	if k.key != nil {
+		return k.key, "", nil
+	}
+
+	kdf := fmt.Sprintf("%s:%d:%s", kdfPBKDF2, kdfIterations, base64.StdEncoding.EncodeToString(k.salt))
+	key, err := k.decryptionKey(kdf)
+	return key, kdf, err
+}
+
+// decryptionKey returns the key to unwrap the data keys which are wrapped
+// with a key derived with the given parameters.
+func (k *Key) decryptionKey(kdf string) ([]byte, error) {
+	switch {
+	case k.key != nil && kdf == "":
+		return k.key, nil
+	case k.key != nil:
+		return nil, fmt.Errorf("%w: object key is derived from a passphrase", ErrWrongKey)
+	case kdf == "":
+		return nil, fmt.Errorf("%w: object key is read from a key file", ErrWrongKey)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.derived[kdf]; ok {
+		return key, nil
+	}
+
+	iterations, salt, err := parseKDF(kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2(k.passphrase, salt, iterations, dataKeySize)
+	k.derived[kdf] = key
+	return key, nil
+}
+
+// parseKDF parses the parameters of a key derivation in the form of
+// "pbkdf2-sha256:<iterations>:<base64 salt>".
+func parseKDF(kdf string) (int, []byte, error) {
+	fields := strings.Split(kdf, ":")
+	if len(fields) != 3 || fields[0] != kdfPBKDF2 {
+		return 0, nil, fmt.Errorf("unsupported key derivation %q", kdf)
+	}
+
+	iterations, err := strconv.Atoi(fields[1])
+	if err != nil || iterations < 1 {
+		return 0, nil, fmt.Errorf("unsupported key derivation %q", kdf)
+	}
+	if iterations > kdfMaxIterations {
+		return 0, nil, fmt.Errorf("key derivation %q exceeds %d iterations", kdf, kdfMaxIterations)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil {
+		return 0, nil, fmt.Errorf("unsupported key derivation %q", kdf)
+	}
+	return iterations, salt, nil
+}
+
+// pbkdf2 derives a key from the password with PBKDF2-HMAC-SHA256, as
+// described in RFC 8018.
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var counter [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for i := 2; i <= iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
@@ -0,0 +1,235 @@
+package encryption
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// EncryptingReader is an io.Reader which encrypts the content of the
+// underlying reader.
+type EncryptingReader struct {
+	envelope *Envelope
+	r        io.Reader
+
+	index     int64
+	plaintext []byte
+	peek      []byte
+	pending   []byte
+	done      bool
+	err       error
+}
+
+// NewEncryptingReader returns a reader which encrypts the content read from
+// r with the envelope.
+func (e *Envelope) NewEncryptingReader(r io.Reader) *EncryptingReader {
+	return &EncryptingReader{
+		envelope:  e,
+		r:         r,
+		plaintext: make([]byte, 0, ChunkSize),
+		pending:   make([]byte, 0, chunkCipherSize),
+	}
+}
+
+// Read implements io.Reader.
+func (r *EncryptingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// next encrypts the next chunk. A byte past the chunk is read ahead to know
+// whether the chunk is the last one.
+func (r *EncryptingReader) next() error {
+	plaintext := append(r.plaintext[:0], r.peek...)
+	n, err := io.ReadFull(r.r, plaintext[len(plaintext):ChunkSize])
+	plaintext = plaintext[:len(plaintext)+n]
+
+	final := false
+	switch err {
+	case nil:
+		var peek [1]byte
+		n, err := io.ReadFull(r.r, peek[:])
+		switch err {
+		case nil:
+			r.peek = append(r.peek[:0], peek[:n]...)
+		case io.EOF:
+			final = true
+		default:
+			return err
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return err
+	}
+	if final {
+		r.peek = r.peek[:0]
+	}
+
+	r.pending = r.envelope.sealChunk(r.pending[:0], plaintext, r.index, final)
+	r.index++
+	r.done = final
+	return nil
+}
+
+// DecryptingWriterAt is an io.WriterAt which decrypts an encrypted object
+// written at any offsets, in any order, such as by parallel ranged
+// downloads. Each chunk is buffered until all of its bytes are written, then
+// it is decrypted and written to the underlying writer.
+type DecryptingWriterAt struct {
+	envelope *Envelope
+	w        io.WriterAt
+	size     int64
+	chunks   int64
+
+	mu        sync.Mutex
+	pending   map[int64]*pendingChunk
+	decrypted []bool
+	remaining int64
+}
+
+// pendingChunk is an encrypted chunk which is partially written.
+type pendingChunk struct {
+	data    []byte
+	written []byteRange
+}
+
+type byteRange struct {
+	start, end int
+}
+
+// NewDecryptingWriterAt returns a writer which decrypts the encrypted object
+// of the given size, and writes its plaintext to w.
+func (e *Envelope) NewDecryptingWriterAt(w io.WriterAt, size int64) (*DecryptingWriterAt, error) {
+	if _, ok := PlaintextSize(size); !ok {
+		return nil, ErrCorrupted
+	}
+
+	chunks := (size + chunkCipherSize - 1) / chunkCipherSize
+	return &DecryptingWriterAt{
+		envelope:  e,
+		w:         w,
+		size:      size,
+		chunks:    chunks,
+		pending:   map[int64]*pendingChunk{},
+		decrypted: make([]bool, chunks),
+		remaining: chunks,
+	}, nil
+}
+
+// Size returns the size of the plaintext.
+func (w *DecryptingWriterAt) Size() int64 {
+	size, _ := PlaintextSize(w.size)
+	return size
+}
+
+// chunkLen returns the size of the encrypted chunk with the given index.
+func (w *DecryptingWriterAt) chunkLen(index int64) int {
+	if index == w.chunks-1 {
+		return int(w.size - index*chunkCipherSize)
+	}
+	return chunkCipherSize
+}
+
+// WriteAt implements io.WriterAt. Bytes which are written again, such as by
+// retried requests, are expected to be the same.
+func (w *DecryptingWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > w.size {
+		return 0, fmt.Errorf("%w: unexpected size", ErrCorrupted)
+	}
+
+	written := len(p)
+	for len(p) > 0 {
+		index := off / chunkCipherSize
+		within := int(off % chunkCipherSize)
+		n := w.chunkLen(index) - within
+		if n > len(p) {
+			n = len(p)
+		}
+
+		if chunk := w.write(index, within, p[:n]); chunk != nil {
+			final := index == w.chunks-1
+			plaintext, err := w.envelope.openChunk(chunk[:0], chunk, index, final)
+			if err != nil {
+				return 0, err
+			}
+			if _, err := w.w.WriteAt(plaintext, index*ChunkSize); err != nil {
+				return 0, err
+			}
+		}
+
+		p = p[n:]
+		off += int64(n)
+	}
+	return written, nil
+}
+
+// write writes p into the chunk, and returns the chunk once it is complete.
+func (w *DecryptingWriterAt) write(index int64, within int, p []byte) []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.decrypted[index] {
+		return nil
+	}
+
+	chunk, ok := w.pending[index]
+	if !ok {
+		chunk = &pendingChunk{data: make([]byte, w.chunkLen(index))}
+		w.pending[index] = chunk
+	}
+
+	copy(chunk.data[within:], p)
+	chunk.written = addRange(chunk.written, byteRange{start: within, end: within + len(p)})
+	if len(chunk.written) != 1 || chunk.written[0].start != 0 || chunk.written[0].end != len(chunk.data) {
+		return nil
+	}
+
+	delete(w.pending, index)
+	w.decrypted[index] = true
+	w.remaining--
+	return chunk.data
+}
+
+// Close reports an error if some of the chunks are not written.
+func (w *DecryptingWriterAt) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.remaining != 0 {
+		return fmt.Errorf("%w: %d of %d chunks are missing", ErrCorrupted, w.remaining, w.chunks)
+	}
+	return nil
+}
+
+// addRange adds r to the sorted and disjoint ranges, merging the ranges
+// which overlap or touch it.
+func addRange(ranges []byteRange, r byteRange) []byteRange {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].end >= r.start })
+
+	j := i
+	for j < len(ranges) && ranges[j].start <= r.end {
+		if ranges[j].start < r.start {
+			r.start = ranges[j].start
+		}
+		if ranges[j].end > r.end {
+			r.end = ranges[j].end
+		}
+		j++
+	}
+
+	result := append(ranges[:i:i], r)
+	return append(result, ranges[j:]...)
+}
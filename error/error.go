@@ -79,13 +79,20 @@ var (
 
 	// ErrObjectIsNewerAndSizesMatch indicates the specified object is newer or same age and sizes of objects match.
 	ErrObjectIsNewerAndSizesMatch = fmt.Errorf("%v and %v", ErrObjectIsNewer, ErrObjectSizesMatch)
+
+	// ErrObjectChecksumsMatch indicates the content hashes of objects match.
+	ErrObjectChecksumsMatch = fmt.Errorf("object checksum matches")
+
+	// ErrObjectConflict indicates an object is changed on both sides of a
+	// bidirectional sync since the previous one.
+	ErrObjectConflict = fmt.Errorf("object is changed on both sides")
 )
 
 // IsWarning checks if given error is either ErrObjectExists,
-// ErrObjectIsNewer or ErrObjectSizesMatch.
+// ErrObjectIsNewer, ErrObjectSizesMatch or ErrObjectChecksumsMatch.
 func IsWarning(err error) bool {
 	switch err {
-	case ErrObjectExists, ErrObjectIsNewer, ErrObjectSizesMatch, ErrObjectIsNewerAndSizesMatch:
+	case ErrObjectExists, ErrObjectIsNewer, ErrObjectSizesMatch, ErrObjectIsNewerAndSizesMatch, ErrObjectChecksumsMatch:
 		return true
 	}
 
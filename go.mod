@@ -1,6 +1,6 @@
 module github.com/peak/s5cmd/v2
 
-go 1.19
+go 1.22
 
 require (
 	github.com/Microsoft/go-winio v0.6.1
@@ -13,6 +13,8 @@ require (
 	github.com/igungor/gofakes3 v0.0.15
 	github.com/karrick/godirwalk v1.15.3
 	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51
+	github.com/klauspost/compress v1.18.0
+	github.com/klauspost/compress v1.18.0
 	github.com/lanrat/extsort v1.0.0
 	github.com/termie/go-shutil v0.0.0-20140729215957-bcacb06fecae
 	github.com/urfave/cli/v2 v2.11.2
@@ -0,0 +1,195 @@
+// Package ignore implements the patterns of gitignore-style filter files.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the filter files which are honored while walking
+// local directories.
+const FileName = ".s5cmdignore"
+
+// Result is the result of matching a path against the rules.
+type Result int
+
+const (
+	// NoMatch means that none of the rules match the path.
+	NoMatch Result = iota
+	// Exclude means that the last matching rule is a pattern.
+	Exclude
+	// Include means that the last matching rule is a negated pattern.
+	Include
+)
+
+type rule struct {
+	pattern string
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Rules are the rules of a filter file in the order they are given. The last
+// matching rule decides whether a path matches.
+type Rules []rule
+
+// ReadFile reads the rules of the filter file at the given path.
+func ReadFile(path string) (Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse parses the rules of a filter file. Blank lines and the lines starting
+// with "#" are ignored. A rule starting with "!" is negated, and a rule
+// ending with "/" only matches directories. A rule with a "/" at the
+// beginning or in the middle is relative to the root, otherwise it matches
+// at any level. "*" and "?" do not match "/", whereas "**" matches any
+// number of directories.
+func Parse(r io.Reader) (Rules, error) {
+	var rules Rules
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule, err := parseRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func parseRule(line string) (rule, error) {
+	r := rule{pattern: line}
+
+	pattern := line
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	if pattern == "" {
+		return rule{}, fmt.Errorf("invalid pattern %q", line)
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	regex, err := regexp.Compile(toRegexp(pattern, anchored))
+	if err != nil {
+		return rule{}, fmt.Errorf("invalid pattern %q: %w", line, err)
+	}
+	r.regex = regex
+	return r, nil
+}
+
+// toRegexp converts the pattern of a rule to a regular expression which
+// matches the slash separated paths relative to the root.
+func toRegexp(pattern string, anchored bool) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// Match matches the slash separated path, which is relative to the root of
+// the rules, against the rules. The parent directories of the path are not
+// matched.
+func (rules Rules) Match(path string, isDir bool) Result {
+	result := NoMatch
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if !r.regex.MatchString(path) {
+			continue
+		}
+
+		if r.negate {
+			result = Include
+		} else {
+			result = Exclude
+		}
+	}
+	return result
+}
+
+// Matches reports whether the file at the slash separated path, which is
+// relative to the root of the rules, matches the rules. A file matches if
+// one of its parent directories matches, in which case it can not be
+// negated by a later rule.
+func (rules Rules) Matches(path string) bool {
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if rules.Match(strings.Join(parts[:i], "/"), true) == Exclude {
+			return true
+		}
+	}
+	return rules.Match(path, false) == Exclude
+}
@@ -0,0 +1,123 @@
+package ignore
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRulesMatches(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		rules    string
+		matched  []string
+		excluded []string
+	}{
+		{
+			name:     "unanchored pattern",
+			rules:    "*.log",
+			matched:  []string{"a.log", "dir/a.log", "dir/sub/b.log"},
+			excluded: []string{"a.txt", "a.log.gz"},
+		},
+		{
+			name:     "anchored pattern",
+			rules:    "/build",
+			matched:  []string{"build", "build/a.o"},
+			excluded: []string{"src/build", "builds"},
+		},
+		{
+			name:     "pattern with a slash in the middle",
+			rules:    "doc/*.txt",
+			matched:  []string{"doc/a.txt"},
+			excluded: []string{"doc/sub/a.txt", "src/doc/a.txt"},
+		},
+		{
+			name:     "directory only pattern",
+			rules:    "cache/",
+			matched:  []string{"cache/a", "dir/cache/b/c"},
+			excluded: []string{"cache", "dir/cache.txt"},
+		},
+		{
+			name:     "negation",
+			rules:    "*.log\n!important.log",
+			matched:  []string{"a.log", "dir/b.log"},
+			excluded: []string{"important.log", "dir/important.log"},
+		},
+		{
+			name:     "negation can not include the files of an excluded directory",
+			rules:    "tmp/\n!tmp/keep.txt",
+			matched:  []string{"tmp/a.txt", "tmp/keep.txt"},
+			excluded: []string{"keep.txt"},
+		},
+		{
+			name:     "leading double asterisk",
+			rules:    "**/logs",
+			matched:  []string{"logs/a", "x/logs/a", "x/y/logs/a"},
+			excluded: []string{"x/logs.txt"},
+		},
+		{
+			name:     "trailing double asterisk",
+			rules:    "assets/**",
+			matched:  []string{"assets/a.png", "assets/img/b.png"},
+			excluded: []string{"assets", "src/assets/a.png"},
+		},
+		{
+			name:     "double asterisk in the middle",
+			rules:    "a/**/b.txt",
+			matched:  []string{"a/b.txt", "a/x/b.txt", "a/x/y/b.txt"},
+			excluded: []string{"b.txt", "c/a/x/b.txt"},
+		},
+		{
+			name:     "question mark and character class",
+			rules:    "file-?.[ct]sv\nreport-[!0-9].txt",
+			matched:  []string{"file-1.csv", "dir/file-a.tsv", "report-a.txt"},
+			excluded: []string{"file-10.csv", "file-1.psv", "report-1.txt"},
+		},
+		{
+			name:     "comments, blank lines and escapes",
+			rules:    "# comment\n\n\\#hash\n\\!bang\n",
+			matched:  []string{"#hash", "!bang"},
+			excluded: []string{"# comment", "comment"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := Parse(strings.NewReader(tc.rules))
+			assert.NilError(t, err)
+
+			for _, path := range tc.matched {
+				assert.Assert(t, rules.Matches(path), "expected %q to match", path)
+			}
+			for _, path := range tc.excluded {
+				assert.Assert(t, !rules.Matches(path), "expected %q not to match", path)
+			}
+		})
+	}
+}
+
+func TestRulesMatch(t *testing.T) {
+	t.Parallel()
+
+	rules, err := Parse(strings.NewReader("*.log\n!keep.log\nbuild/"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, rules.Match("a.log", false), Exclude)
+	assert.Equal(t, rules.Match("keep.log", false), Include)
+	assert.Equal(t, rules.Match("a.txt", false), NoMatch)
+	assert.Equal(t, rules.Match("build", true), Exclude)
+	assert.Equal(t, rules.Match("build", false), NoMatch)
+}
+
+func TestParseInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse(strings.NewReader("!"))
+	assert.ErrorContains(t, err, `invalid pattern "!"`)
+}
@@ -18,8 +18,16 @@ const (
 var (
 	enabled bool
 	stats   statistics
+
+	gaugesMu sync.Mutex
+	gauges   []gauge
 )
 
+type gauge struct {
+	name string
+	fn   func() int64
+}
+
 type statistics [2]syncMapStrInt64
 
 // InitStat initializes collecting program statistics.
@@ -110,3 +118,60 @@ func Statistics() Stats {
 	}
 	return result
 }
+
+// Gauge registers a value which is read with the given function when the
+// statistics are displayed.
+func Gauge(name string, fn func() int64) {
+	gaugesMu.Lock()
+	defer gaugesMu.Unlock()
+
+	gauges = append(gauges, gauge{name: name, fn: fn})
+}
+
+// GaugeStat is for storing the value of a gauge.
+type GaugeStat struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// GaugeStats implements log.Message interface.
+type GaugeStats []GaugeStat
+
+func (g GaugeStats) String() string {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 1, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "\n%s\t%s\t\n", "Gauge", "Value")
+	for _, gauge := range g {
+		fmt.Fprintf(w, "%s\t%d\t\n", gauge.Name, gauge.Value)
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+func (g GaugeStats) JSON() string {
+	var builder strings.Builder
+
+	for _, gauge := range g {
+		builder.WriteString(strutil.JSON(gauge) + "\n")
+	}
+	return builder.String()
+}
+
+// Gauges returns the current values of the registered gauges.
+func Gauges() GaugeStats {
+	if !enabled {
+		return GaugeStats{}
+	}
+
+	gaugesMu.Lock()
+	defer gaugesMu.Unlock()
+
+	var result GaugeStats
+	for _, gauge := range gauges {
+		result = append(result, GaugeStat{Name: gauge.name, Value: gauge.fn()})
+	}
+	return result
+}
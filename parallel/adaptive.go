@@ -0,0 +1,161 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// adaptiveWindow is the duration in which the throughput and the latency
+	// of the tasks are measured before the concurrency is adjusted.
+	adaptiveWindow = time.Second
+
+	// adaptiveIncrease is the number of workers added after a window in which
+	// the throughput improved, once the slow start is over.
+	adaptiveIncrease = 4
+
+	// adaptiveTolerance is the ratio of throughput which can be lost between
+	// two windows while the throughput is still considered as improving.
+	adaptiveTolerance = 0.1
+
+	// adaptiveLatencyFactor is the ratio of latency growth between two windows
+	// which is considered as a sign of congestion.
+	adaptiveLatencyFactor = 2
+)
+
+// adaptiveLimit limits the number of tasks in flight, and adjusts the limit
+// in an AIMD (additive increase, multiplicative decrease) manner. The limit
+// is halved when the storage throttles requests, and it is increased while
+// the throughput of the tasks improves and their latency does not grow.
+// Until the first throttling, the limit is doubled instead of increased, as
+// the slow start of TCP does.
+type adaptiveLimit struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	limit    int
+	min      int
+	max      int
+	inflight int
+
+	slowStart    bool
+	lastDecrease time.Time
+
+	// measurements of the current window.
+	windowStart time.Time
+	completed   int
+	latency     time.Duration
+	saturated   bool
+	throttled   bool
+
+	// measurements of the previous window.
+	prevRate    float64
+	prevLatency time.Duration
+}
+
+func newAdaptiveLimit(initial, min, max int) *adaptiveLimit {
+	a := &adaptiveLimit{
+		limit:       initial,
+		min:         min,
+		max:         max,
+		slowStart:   true,
+		windowStart: time.Now(),
+	}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// acquire blocks until the number of tasks in flight is below the limit.
+func (a *adaptiveLimit) acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for a.inflight >= a.limit {
+		a.saturated = true
+		a.cond.Wait()
+	}
+	a.inflight++
+	if a.inflight == a.limit {
+		a.saturated = true
+	}
+}
+
+// release marks a task which took the given duration as finished.
+func (a *adaptiveLimit) release(took time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.inflight--
+	a.completed++
+	a.latency += took
+
+	if a.adjust(time.Now()) {
+		a.cond.Broadcast()
+		return
+	}
+	a.cond.Signal()
+}
+
+// throttle halves the limit. The limit is decreased at most once in a
+// window, as the requests in flight are likely to be throttled together.
+func (a *adaptiveLimit) throttle() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.lastDecrease) < adaptiveWindow {
+		return
+	}
+
+	a.limit /= 2
+	if a.limit < a.min {
+		a.limit = a.min
+	}
+	a.slowStart = false
+	a.lastDecrease = now
+	a.throttled = true
+}
+
+// current returns the current limit.
+func (a *adaptiveLimit) current() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.limit
+}
+
+// adjust increases the limit if the window is over and the tasks were
+// limited by it, and reports whether the limit is increased.
+func (a *adaptiveLimit) adjust(now time.Time) bool {
+	elapsed := now.Sub(a.windowStart)
+	if elapsed < adaptiveWindow || a.completed == 0 {
+		return false
+	}
+
+	rate := float64(a.completed) / elapsed.Seconds()
+	latency := a.latency / time.Duration(a.completed)
+
+	improved := rate >= a.prevRate*(1-adaptiveTolerance)
+	congested := a.prevLatency > 0 && latency > a.prevLatency*adaptiveLatencyFactor
+
+	increased := false
+	if a.saturated && !a.throttled && improved && !congested && a.limit < a.max {
+		if a.slowStart {
+			a.limit *= 2
+		} else {
+			a.limit += adaptiveIncrease
+		}
+		if a.limit > a.max {
+			a.limit = a.max
+		}
+		increased = true
+	}
+
+	a.prevRate = rate
+	a.prevLatency = latency
+	a.windowStart = now
+	a.completed = 0
+	a.latency = 0
+	a.saturated = a.inflight >= a.limit
+	a.throttled = false
+	return increased
+}
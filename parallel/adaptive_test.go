@@ -0,0 +1,148 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimitThrottle(t *testing.T) {
+	a := newAdaptiveLimit(32, 2, 256)
+
+	a.throttle()
+	if got := a.current(); got != 16 {
+		t.Fatalf("expected limit to be halved to 16, got %v", got)
+	}
+
+	// throttling of the requests which are already in flight is ignored.
+	a.throttle()
+	if got := a.current(); got != 16 {
+		t.Fatalf("expected limit to stay 16 in the same window, got %v", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		a.lastDecrease = time.Time{}
+		a.throttle()
+	}
+	if got := a.current(); got != 2 {
+		t.Fatalf("expected limit not to go below the minimum 2, got %v", got)
+	}
+}
+
+func TestAdaptiveLimitAdjust(t *testing.T) {
+	testcases := []struct {
+		name        string
+		slowStart   bool
+		saturated   bool
+		throttled   bool
+		completed   int
+		latency     time.Duration
+		prevRate    float64
+		prevLatency time.Duration
+		expected    int
+	}{
+		{
+			name:      "slow start doubles the limit",
+			slowStart: true,
+			saturated: true,
+			completed: 10,
+			latency:   10 * time.Millisecond,
+			expected:  16,
+		},
+		{
+			name:      "congestion avoidance increases the limit",
+			saturated: true,
+			completed: 10,
+			latency:   10 * time.Millisecond,
+			expected:  8 + adaptiveIncrease,
+		},
+		{
+			name:      "not saturated",
+			completed: 10,
+			latency:   10 * time.Millisecond,
+			expected:  8,
+		},
+		{
+			name:      "throttled in the window",
+			saturated: true,
+			throttled: true,
+			completed: 10,
+			latency:   10 * time.Millisecond,
+			expected:  8,
+		},
+		{
+			name:      "throughput decreased",
+			saturated: true,
+			completed: 10,
+			latency:   10 * time.Millisecond,
+			prevRate:  100,
+			expected:  8,
+		},
+		{
+			name:        "latency increased",
+			saturated:   true,
+			completed:   10,
+			latency:     10 * time.Second,
+			prevLatency: time.Millisecond,
+			expected:    8,
+		},
+		{
+			name:      "no tasks completed",
+			saturated: true,
+			expected:  8,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := newAdaptiveLimit(8, 2, 256)
+			a.slowStart = tc.slowStart
+			a.saturated = tc.saturated
+			a.throttled = tc.throttled
+			a.completed = tc.completed
+			a.latency = tc.latency
+			a.prevRate = tc.prevRate
+			a.prevLatency = tc.prevLatency
+
+			a.adjust(a.windowStart.Add(adaptiveWindow))
+			if got := a.current(); got != tc.expected {
+				t.Errorf("expected limit %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAdaptiveManagerRun(t *testing.T) {
+	pm := NewAdaptive(16)
+	waiter := NewWaiter()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range waiter.Err() {
+		}
+	}()
+
+	var count int64
+	results := make(chan struct{}, 100)
+	for i := 0; i < 100; i++ {
+		pm.Run(func() error {
+			results <- struct{}{}
+			return nil
+		}, waiter)
+	}
+	waiter.Wait()
+	<-done
+	pm.Close()
+
+	close(results)
+	for range results {
+		count++
+	}
+	if count != 100 {
+		t.Fatalf("expected 100 tasks to run, got %v", count)
+	}
+	if got := pm.Concurrency(); got < minNumWorkers || got > 16 {
+		t.Fatalf("expected concurrency within [%v, 16], got %v", minNumWorkers, got)
+	}
+}
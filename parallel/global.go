@@ -11,6 +11,13 @@ func Init(workercount int) {
 	global = New(workercount)
 }
 
+// InitAdaptive tries to increase the soft limit of open files and creates
+// new global ParallelManager which adjusts its concurrency up to maxworkers.
+func InitAdaptive(maxworkers int) {
+	_ = fdlimit.Raise()
+	global = NewAdaptive(maxworkers)
+}
+
 // Close waits all jobs to finish and
 // closes the semaphore of global ParallelManager.
 func Close() {
@@ -21,3 +28,18 @@ func Close() {
 
 // Run runs global ParallelManager.
 func Run(task Task, waiter *Waiter) { global.Run(task, waiter) }
+
+// Throttled signals the global ParallelManager that a request is throttled.
+func Throttled() {
+	if global != nil {
+		global.Throttled()
+	}
+}
+
+// Concurrency returns the current concurrency of global ParallelManager.
+func Concurrency() int {
+	if global == nil {
+		return 0
+	}
+	return global.Concurrency()
+}
@@ -3,6 +3,7 @@ package parallel
 import (
 	"runtime"
 	"sync"
+	"time"
 )
 
 const (
@@ -16,6 +17,7 @@ type Task func() error
 type Manager struct {
 	wg        *sync.WaitGroup
 	semaphore chan bool
+	adaptive  *adaptiveLimit
 }
 
 // New creates a new parallel.Manager.
@@ -34,15 +36,43 @@ func New(workercount int) *Manager {
 	}
 }
 
+// NewAdaptive creates a new parallel.Manager which adjusts its concurrency
+// between minNumWorkers and maxworkers, based on the throughput and the
+// latency of the tasks and the throttling reported with Throttled.
+func NewAdaptive(maxworkers int) *Manager {
+	if maxworkers < minNumWorkers {
+		maxworkers = minNumWorkers
+	}
+
+	initial := maxworkers / 8
+	if initial < minNumWorkers {
+		initial = minNumWorkers
+	}
+
+	return &Manager{
+		wg:       &sync.WaitGroup{},
+		adaptive: newAdaptiveLimit(initial, minNumWorkers, maxworkers),
+	}
+}
+
 // acquire limits concurrency by trying to acquire the semaphore.
 func (p *Manager) acquire() {
-	p.semaphore <- true
+	if p.adaptive != nil {
+		p.adaptive.acquire()
+	} else {
+		p.semaphore <- true
+	}
 	p.wg.Add(1)
 }
 
-// release releases the acquired semaphore to signal that a task is finished.
-func (p *Manager) release() {
+// release releases the acquired semaphore to signal that a task which took
+// the given duration is finished.
+func (p *Manager) release(took time.Duration) {
 	p.wg.Done()
+	if p.adaptive != nil {
+		p.adaptive.release(took)
+		return
+	}
 	<-p.semaphore
 }
 
@@ -52,7 +82,9 @@ func (p *Manager) Run(fn Task, waiter *Waiter) {
 	p.acquire()
 	go func() {
 		defer waiter.wg.Done()
-		defer p.release()
+
+		start := time.Now()
+		defer func() { p.release(time.Since(start)) }()
 
 		if err := fn(); err != nil {
 			waiter.errch <- err
@@ -60,10 +92,29 @@ func (p *Manager) Run(fn Task, waiter *Waiter) {
 	}()
 }
 
+// Throttled signals that a request of a task is throttled, so that an
+// adaptive manager decreases its concurrency. It has no effect on the other
+// managers.
+func (p *Manager) Throttled() {
+	if p.adaptive != nil {
+		p.adaptive.throttle()
+	}
+}
+
+// Concurrency returns the number of tasks which can run concurrently.
+func (p *Manager) Concurrency() int {
+	if p.adaptive != nil {
+		return p.adaptive.current()
+	}
+	return cap(p.semaphore)
+}
+
 // Close waits all tasks to finish.
 func (p *Manager) Close() {
 	p.wg.Wait()
-	close(p.semaphore)
+	if p.semaphore != nil {
+		close(p.semaphore)
+	}
 }
 
 // Waiter is a structure for waiting and reading
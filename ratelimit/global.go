@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+)
+
+var (
+	total    *Limiter
+	upload   *Limiter
+	download *Limiter
+)
+
+// Init creates the global limiters shared by all transfers. Zero rates are
+// not limited.
+func Init(totalRate, uploadRate, downloadRate int64) {
+	total, upload, download = nil, nil, nil
+	if totalRate > 0 {
+		total = New(totalRate)
+	}
+	if uploadRate > 0 {
+		upload = New(uploadRate)
+	}
+	if downloadRate > 0 {
+		download = New(downloadRate)
+	}
+}
+
+// UploadReadCloser returns a reader which reads from rc within the global and
+// upload limits.
+func UploadReadCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return NewReadCloser(ctx, rc, total, upload)
+}
+
+// DownloadWriterAt returns a writer which writes to w within the global and
+// download limits.
+func DownloadWriterAt(ctx context.Context, w io.WriterAt) io.WriterAt {
+	return NewWriterAt(ctx, w, total, download)
+}
+
+// DownloadReadCloser returns a reader which reads from rc within the global
+// and download limits.
+func DownloadReadCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return NewReadCloser(ctx, rc, total, download)
+}
@@ -0,0 +1,171 @@
+// Package ratelimit limits the bandwidth of transfers with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peak/s5cmd/v2/strutil"
+)
+
+// Limiter is a token bucket which limits the number of bytes transferred per
+// second. A nil Limiter has no limit.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// New creates a Limiter which allows the given number of bytes per second.
+// Bursts are allowed up to a second's worth of bytes.
+func New(bytesPerSecond int64) *Limiter {
+	rate := float64(bytesPerSecond)
+	return &Limiter{
+		rate:   rate,
+		burst:  rate,
+		tokens: rate,
+		last:   time.Now(),
+	}
+}
+
+// WaitN blocks until n bytes can be transferred, or the context is canceled.
+// Bytes which exceed the tokens in the bucket are borrowed from the future,
+// so that transfers larger than the burst can proceed at the limited rate.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	delay := l.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait until
+// the bucket is refilled to cover them.
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Limiters is a set of limiters which all must allow a transfer.
+type Limiters []*Limiter
+
+// WaitN blocks until all limiters allow n bytes to be transferred.
+func (ls Limiters) WaitN(ctx context.Context, n int) error {
+	for _, l := range ls {
+		if err := l.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ls Limiters) enabled() bool {
+	for _, l := range ls {
+		if l != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// NewReader returns a reader which reads from r within the limits.
+func NewReader(ctx context.Context, r io.Reader, limiters ...*Limiter) io.Reader {
+	ls := Limiters(limiters)
+	if !ls.enabled() {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, limiters: ls}
+}
+
+// NewReadCloser is like NewReader, but for io.ReadCloser.
+func NewReadCloser(ctx context.Context, rc io.ReadCloser, limiters ...*Limiter) io.ReadCloser {
+	ls := Limiters(limiters)
+	if !ls.enabled() {
+		return rc
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: &reader{ctx: ctx, r: rc, limiters: ls},
+		Closer: rc,
+	}
+}
+
+// NewWriterAt returns an io.WriterAt which writes to w within the limits.
+func NewWriterAt(ctx context.Context, w io.WriterAt, limiters ...*Limiter) io.WriterAt {
+	ls := Limiters(limiters)
+	if !ls.enabled() {
+		return w
+	}
+	return &writerAt{ctx: ctx, w: w, limiters: ls}
+}
+
+type reader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters Limiters
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if werr := r.limiters.WaitN(r.ctx, n); werr != nil {
+		return n, werr
+	}
+	return n, err
+}
+
+type writerAt struct {
+	ctx      context.Context
+	w        io.WriterAt
+	limiters Limiters
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if err := w.limiters.WaitN(w.ctx, len(p)); err != nil {
+		return 0, err
+	}
+	return w.w.WriteAt(p, off)
+}
+
+// ParseRate parses a rate such as "50MB/s", "1.5M" or "800K" into bytes per
+// second. Units are binary, e.g. 1K is 1024 bytes, and "B", "iB" and "/s"
+// suffixes are optional.
+func ParseRate(s string) (int64, error) {
+	rate, err := strutil.ParseBytes(strings.TrimSuffix(strings.TrimSpace(s), "/s"))
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected a positive value such as 50MB/s", s)
+	}
+	return rate, nil
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	testcases := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{value: "100", expected: 100},
+		{value: "100B/s", expected: 100},
+		{value: "1K", expected: 1024},
+		{value: "1KB/s", expected: 1024},
+		{value: "1KiB/s", expected: 1024},
+		{value: "50MB/s", expected: 50 << 20},
+		{value: "1.5m", expected: 3 << 19},
+		{value: "2G", expected: 2 << 30},
+		{value: "", wantErr: true},
+		{value: "MB/s", wantErr: true},
+		{value: "-1M", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "fast", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		got, err := ParseRate(tc.value)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseRate(%q): expected error, got %v", tc.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRate(%q): unexpected error: %v", tc.value, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("ParseRate(%q) = %v, expected %v", tc.value, got, tc.expected)
+		}
+	}
+}
+
+func TestLimiterWaitN(t *testing.T) {
+	const rate = 1000
+
+	l := New(rate)
+	ctx := context.Background()
+
+	// the burst is allowed immediately.
+	start := time.Now()
+	if err := l.WaitN(ctx, rate); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("burst took %v", elapsed)
+	}
+
+	// the rest is limited.
+	start = time.Now()
+	if err := l.WaitN(ctx, rate/4); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected to wait at least 200ms, waited %v", elapsed)
+	}
+}
+
+func TestLimiterWaitNCanceled(t *testing.T) {
+	l := New(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.WaitN(ctx, 10); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	if err := l.WaitN(context.Background(), 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	r := strings.NewReader("content")
+	if got := NewReader(context.Background(), r, nil, nil); got != r {
+		t.Fatal("expected the reader to be returned as is")
+	}
+}
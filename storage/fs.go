@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 	"github.com/karrick/godirwalk"
 	"github.com/termie/go-shutil"
 
+	"github.com/peak/s5cmd/v2/ignore"
 	"github.com/peak/s5cmd/v2/storage/url"
 )
 
@@ -23,6 +25,10 @@ func (e *InvalidOwnershipFormatError) Error() string {
 // Filesystem is the Storage implementation of a local filesystem.
 type Filesystem struct {
 	dryRun bool
+
+	// useIgnoreFiles enables the ignore files found while walking
+	// directories.
+	useIgnoreFiles bool
 }
 
 // Stat returns the Object structure describing object.
@@ -126,8 +132,26 @@ func walkDir(ctx context.Context, fs *Filesystem, src *url.URL, followSymlinks b
 	if !ShouldProcessURL(src, followSymlinks) {
 		return
 	}
+	var ignores *ignoreFiles
+	if fs.useIgnoreFiles {
+		ignores = &ignoreFiles{rules: map[string]ignore.Rules{}}
+	}
+
 	err := godirwalk.Walk(src.Absolute(), &godirwalk.Options{
 		Callback: func(pathname string, dirent *godirwalk.Dirent) error {
+			if ignores != nil {
+				skip, err := ignores.skip(pathname, dirent.IsDir())
+				if err != nil {
+					return err
+				}
+				if skip && dirent.IsDir() {
+					return filepath.SkipDir
+				}
+				if skip {
+					return nil
+				}
+			}
+
 			// we're interested in files
 			if dirent.IsDir() {
 				pathname += string(os.PathSeparator)
@@ -161,6 +185,54 @@ func walkDir(ctx context.Context, fs *Filesystem, src *url.URL, followSymlinks b
 	}
 }
 
+// ignoreFiles keeps the rules of the ignore files of the directories which
+// are visited while walking a directory.
+type ignoreFiles struct {
+	// rules are the rules of the visited directories, keyed by their paths.
+	rules map[string]ignore.Rules
+}
+
+// skip reports whether the path, which is visited after its parent
+// directories, is excluded by the ignore files of its parent directories.
+// The rules of the deepest ignore file which matches the path win. The
+// ignore files are skipped too. The ignore file of a directory is read when
+// the directory is not skipped.
+func (i *ignoreFiles) skip(pathname string, isDir bool) (bool, error) {
+	if !isDir && filepath.Base(pathname) == ignore.FileName {
+		return true, nil
+	}
+
+	result := ignore.NoMatch
+	for dir := filepath.Dir(pathname); ; dir = filepath.Dir(dir) {
+		if rules, ok := i.rules[dir]; ok {
+			rel, err := filepath.Rel(dir, pathname)
+			if err != nil {
+				return false, err
+			}
+			if r := rules.Match(filepath.ToSlash(rel), isDir); r != ignore.NoMatch {
+				result = r
+				break
+			}
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	if result == ignore.Exclude {
+		return true, nil
+	}
+
+	if isDir {
+		rules, err := ignore.ReadFile(filepath.Join(pathname, ignore.FileName))
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		i.rules[filepath.Clean(pathname)] = rules
+	}
+	return false, nil
+}
+
 func (f *Filesystem) walkDir(ctx context.Context, src *url.URL, followSymlinks bool) <-chan *Object {
 	ch := make(chan *Object)
 	go func() {
@@ -174,7 +246,7 @@ func (f *Filesystem) walkDir(ctx context.Context, src *url.URL, followSymlinks b
 }
 
 // Copy copies given source to destination.
-func (f *Filesystem) Copy(ctx context.Context, src, dst *url.URL, _ Metadata) error {
+func (f *Filesystem) Copy(ctx context.Context, src, dst *url.URL, _ Metadata, _ int, _ int64) error {
 	if f.dryRun {
 		return nil
 	}
@@ -273,6 +345,31 @@ func (f *Filesystem) CreateTemp(dir, pattern string) (*os.File, error) {
 	return file, err
 }
 
+// OpenPartial opens the file in the given path for writing without
+// truncating it, creating the file if it does not exist.
+func (f *Filesystem) OpenPartial(path string) (*os.File, error) {
+	if f.dryRun {
+		return &os.File{}, nil
+	}
+
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+}
+
+// RemovePartial removes the given files of a partial download. The files
+// which do not exist are ignored.
+func (f *Filesystem) RemovePartial(paths ...string) error {
+	if f.dryRun {
+		return nil
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
 // Rename a file
 func (f *Filesystem) Rename(file *os.File, newpath string) error {
 	if f.dryRun {
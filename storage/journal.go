@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const journalExtension = ".json"
+
+// Journal records the progress of a resumable multipart upload. It is kept on
+// the local disk until the upload completes, so that an interrupted upload
+// can be continued by another process instead of starting over.
+type Journal struct {
+	Source      string        `json:"source"`
+	Destination string        `json:"destination"`
+	UploadID    string        `json:"upload_id"`
+	Size        int64         `json:"size"`
+	ModTime     time.Time     `json:"mod_time"`
+	PartSize    int64         `json:"part_size"`
+	Parts       []JournalPart `json:"parts,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// JournalPart is a part of a multipart upload which is known to be uploaded.
+type JournalPart struct {
+	Number int64  `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+// DefaultJournalDir returns the directory where upload journals are kept,
+// which is under the user's cache directory.
+func DefaultJournalDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "s5cmd", "uploads"), nil
+}
+
+// journalPath returns the path of the journal of the upload from source to
+// destination in the given directory.
+func journalPath(dir, source, destination string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + destination))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+journalExtension)
+}
+
+// LoadJournal reads the journal in the given path.
+func LoadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	j.path = path
+	return &j, nil
+}
+
+// ListJournals returns the journals in the given directory, oldest first.
+// A non-existent directory has no journals.
+func ListJournals(dir string) ([]*Journal, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var journals []*Journal
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), journalExtension) {
+			continue
+		}
+
+		j, err := LoadJournal(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		journals = append(journals, j)
+	}
+
+	sort.Slice(journals, func(i, k int) bool {
+		return journals[i].CreatedAt.Before(journals[k].CreatedAt)
+	})
+	return journals, nil
+}
+
+// Path returns the path of the journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Save writes the journal to its file atomically.
+func (j *Journal) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.save()
+}
+
+func (j *Journal) save() error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// Remove deletes the journal file.
+func (j *Journal) Remove() error {
+	err := os.Remove(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// addPart records an uploaded part and saves the journal.
+func (j *Journal) addPart(number int64, etag string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Parts = append(j.Parts, JournalPart{Number: number, ETag: etag})
+	return j.save()
+}
+
+// matches reports whether the journal belongs to an upload of the given
+// file version.
+func (j *Journal) matches(size int64, modTime time.Time, partSize int64) bool {
+	return j.Size == size && j.ModTime.Equal(modTime) && j.PartSize == partSize
+}
+
+// partCount returns the number of parts of the upload.
+func (j *Journal) partCount() int64 {
+	return (j.Size + j.PartSize - 1) / j.PartSize
+}
+
+// partLength returns the size of the part with the given number.
+func (j *Journal) partLength(number int64) int64 {
+	offset := (number - 1) * j.PartSize
+	if remaining := j.Size - offset; remaining < j.PartSize {
+		return remaining
+	}
+	return j.PartSize
+}
+
+// DownloadJournal records the byte ranges of an object which are downloaded
+// into a partial file. It is kept next to the partial file until the download
+// completes, so that an interrupted download can be continued by another
+// process instead of starting over.
+type DownloadJournal struct {
+	Source    string      `json:"source"`
+	ETag      string      `json:"etag"`
+	VersionID string      `json:"version_id,omitempty"`
+	Size      int64       `json:"size"`
+	Ranges    []ByteRange `json:"ranges,omitempty"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// ByteRange is a half-open range of bytes, [Start, End).
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// LoadDownloadJournal reads the download journal in the given path.
+func LoadDownloadJournal(path string) (*DownloadJournal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var j DownloadJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	j.path = path
+	return &j, nil
+}
+
+// Save writes the journal to its file atomically.
+func (j *DownloadJournal) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.save()
+}
+
+func (j *DownloadJournal) save() error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// Remove deletes the journal file.
+func (j *DownloadJournal) Remove() error {
+	err := os.Remove(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// addRange records a downloaded range and saves the journal. Overlapping and
+// adjacent ranges are merged.
+func (j *DownloadJournal) addRange(start, end int64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ranges := append(j.Ranges, ByteRange{Start: start, End: end})
+	sort.Slice(ranges, func(i, k int) bool {
+		return ranges[i].Start < ranges[k].Start
+	})
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	j.Ranges = merged
+	return j.save()
+}
+
+// missingRanges returns the ranges which are not downloaded yet, split into
+// ranges of at most partSize bytes.
+func (j *DownloadJournal) missingRanges(partSize int64) []ByteRange {
+	var (
+		missing []ByteRange
+		offset  int64
+	)
+
+	split := func(start, end int64) {
+		for ; start < end; start += partSize {
+			r := ByteRange{Start: start, End: start + partSize}
+			if r.End > end {
+				r.End = end
+			}
+			missing = append(missing, r)
+		}
+	}
+
+	for _, r := range j.Ranges {
+		split(offset, r.Start)
+		if r.End > offset {
+			offset = r.End
+		}
+	}
+	split(offset, j.Size)
+	return missing
+}
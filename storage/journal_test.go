@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestJournalSaveAndList(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+
+	// listing a directory which is not created yet is not an error.
+	journals, err := ListJournals(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(journals), 0)
+
+	now := time.Now().UTC()
+	older := &Journal{
+		Source:      "/tmp/a",
+		Destination: "s3://bucket/a",
+		UploadID:    "upload-a",
+		Size:        10,
+		PartSize:    4,
+		CreatedAt:   now.Add(-time.Hour),
+		path:        journalPath(dir, "/tmp/a", "s3://bucket/a"),
+	}
+	newer := &Journal{
+		Source:      "/tmp/b",
+		Destination: "s3://bucket/b",
+		UploadID:    "upload-b",
+		Size:        10,
+		PartSize:    4,
+		CreatedAt:   now,
+		path:        journalPath(dir, "/tmp/b", "s3://bucket/b"),
+	}
+	assert.NilError(t, newer.Save())
+	assert.NilError(t, older.Save())
+	assert.NilError(t, older.addPart(1, "etag-1"))
+
+	journals, err = ListJournals(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(journals), 2)
+	assert.Equal(t, journals[0].UploadID, "upload-a")
+	assert.DeepEqual(t, journals[0].Parts, []JournalPart{{Number: 1, ETag: "etag-1"}})
+	assert.Equal(t, journals[1].UploadID, "upload-b")
+
+	assert.NilError(t, journals[0].Remove())
+	journals, err = ListJournals(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(journals), 1)
+}
+
+func TestJournalPartLength(t *testing.T) {
+	j := &Journal{Size: 10, PartSize: 4}
+
+	assert.Equal(t, j.partCount(), int64(3))
+	assert.Equal(t, j.partLength(1), int64(4))
+	assert.Equal(t, j.partLength(2), int64(4))
+	assert.Equal(t, j.partLength(3), int64(2))
+}
+
+func TestDownloadJournalRanges(t *testing.T) {
+	j := &DownloadJournal{
+		Size: 20,
+		path: filepath.Join(t.TempDir(), "object.json"),
+	}
+
+	assert.DeepEqual(t, j.missingRanges(8), []ByteRange{{0, 8}, {8, 16}, {16, 20}})
+
+	assert.NilError(t, j.addRange(8, 12))
+	assert.NilError(t, j.addRange(0, 4))
+	assert.NilError(t, j.addRange(4, 8))
+	assert.DeepEqual(t, j.Ranges, []ByteRange{{0, 12}})
+	assert.DeepEqual(t, j.missingRanges(5), []ByteRange{{12, 17}, {17, 20}})
+
+	assert.NilError(t, j.addRange(14, 16))
+	assert.DeepEqual(t, j.Ranges, []ByteRange{{0, 12}, {14, 16}})
+	assert.DeepEqual(t, j.missingRanges(8), []ByteRange{{12, 14}, {16, 20}})
+
+	loaded, err := LoadDownloadJournal(j.path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded.Ranges, j.Ranges)
+}
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+// memoryScheme is the scheme of the URLs of objects kept in memory.
+const memoryScheme = "mem"
+
+// globalMemoryStore keeps the objects of all Memory storages, so that objects
+// created by a command are visible to the other commands of the same process.
+var globalMemoryStore = &memoryStore{
+	objects: map[string]*memoryObject{},
+}
+
+// Memory is a storage type which keeps objects in the memory of the process.
+// Objects are lost when the process exits, which makes it useful for tests
+// and scratch pipelines run with the "run" command.
+type Memory struct {
+	store  *memoryStore
+	dryRun bool
+}
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+type memoryObject struct {
+	data     []byte
+	etag     string
+	modTime  time.Time
+	metadata Metadata
+}
+
+func newMemoryStorage(_ context.Context, _ *url.URL, opts Options) (Storage, error) {
+	return &Memory{store: globalMemoryStore, dryRun: opts.DryRun}, nil
+}
+
+// memoryKey returns the key of the object in the store.
+func memoryKey(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// Stat retrieves metadata of the object.
+func (m *Memory) Stat(_ context.Context, src *url.URL) (*Object, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	if !ok {
+		return nil, &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+	return obj.object(src), nil
+}
+
+// List lists the objects in the bucket of src which match with it. Keys are
+// grouped by their prefixes up to the delimiter, as S3 does.
+func (m *Memory) List(ctx context.Context, src *url.URL, _ bool) <-chan *Object {
+	objCh := make(chan *Object)
+
+	go func() {
+		defer close(objCh)
+
+		m.store.mu.RLock()
+		var keys []string
+		bucketPrefix := memoryKey(src.Bucket, "")
+		for key := range m.store.objects {
+			if strings.HasPrefix(key, bucketPrefix+src.Prefix) {
+				keys = append(keys, strings.TrimPrefix(key, bucketPrefix))
+			}
+		}
+		m.store.mu.RUnlock()
+		sort.Strings(keys)
+
+		objectFound := false
+		prefixes := map[string]struct{}{}
+		for _, key := range keys {
+			if src.Delimiter != "" {
+				rest := strings.TrimPrefix(key, src.Prefix)
+				if i := strings.Index(rest, src.Delimiter); i >= 0 {
+					prefix := src.Prefix + rest[:i+len(src.Delimiter)]
+					if _, ok := prefixes[prefix]; ok || !src.Match(prefix) {
+						continue
+					}
+					prefixes[prefix] = struct{}{}
+
+					newurl := src.Clone()
+					newurl.Path = prefix
+					sendObject(ctx, &Object{URL: newurl, Type: ObjectType{os.ModeDir}}, objCh)
+					objectFound = true
+					continue
+				}
+			}
+
+			if !src.Match(key) && key != src.Path {
+				continue
+			}
+
+			m.store.mu.RLock()
+			obj, ok := m.store.objects[memoryKey(src.Bucket, key)]
+			m.store.mu.RUnlock()
+			if !ok {
+				continue
+			}
+
+			newurl := src.Clone()
+			newurl.Path = key
+			sendObject(ctx, obj.object(newurl), objCh)
+			objectFound = true
+		}
+
+		if !objectFound {
+			sendError(ctx, ErrNoObjectFound, objCh)
+		}
+	}()
+
+	return objCh
+}
+
+// Delete deletes the object.
+func (m *Memory) Delete(_ context.Context, src *url.URL) error {
+	if m.dryRun {
+		return nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	delete(m.store.objects, memoryKey(src.Bucket, src.Path))
+	return nil
+}
+
+// MultiDelete deletes all objects returned from given urls.
+func (m *Memory) MultiDelete(ctx context.Context, urls <-chan *url.URL) <-chan *Object {
+	resultch := make(chan *Object)
+
+	go func() {
+		defer close(resultch)
+
+		for u := range urls {
+			err := m.Delete(ctx, u)
+			resultch <- &Object{URL: u, Err: err}
+		}
+	}()
+
+	return resultch
+}
+
+// Copy copies the src object to dst, replacing its metadata with the given
+// one. The user metadata and the tags of the source are kept if none are
+// given, as they are on S3.
+func (m *Memory) Copy(_ context.Context, src, dst *url.URL, metadata Metadata, _ int, _ int64) error {
+	if m.dryRun {
+		return nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	if !ok {
+		return &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+
+	if len(metadata.UserDefined) == 0 && metadata.MetadataDirective != "REPLACE" {
+		metadata.UserDefined = obj.metadata.UserDefined
+	}
+	if len(metadata.Tags) == 0 {
+		metadata.Tags = obj.metadata.Tags
+	}
+
+	m.store.objects[memoryKey(dst.Bucket, dst.Path)] = &memoryObject{
+		data:     obj.data,
+		etag:     obj.etag,
+		modTime:  time.Now().UTC(),
+		metadata: metadata,
+	}
+	return nil
+}
+
+// Read returns a reader of the object.
+func (m *Memory) Read(_ context.Context, src *url.URL) (io.ReadCloser, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	if !ok {
+		return nil, &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+// ReadRange returns a reader of the length bytes of the object which start at
+// the given offset.
+func (m *Memory) ReadRange(_ context.Context, src *url.URL, offset, length int64) (io.ReadCloser, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	if !ok {
+		return nil, &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+
+	size := int64(len(obj.data))
+	if offset > size {
+		offset = size
+	}
+	end := offset + length
+	if end > size {
+		end = size
+	}
+	return io.NopCloser(bytes.NewReader(obj.data[offset:end])), nil
+}
+
+// Get writes the object to the given writer, and returns its size.
+func (m *Memory) Get(ctx context.Context, src *url.URL, dst io.WriterAt, _ int, _ int64) (int64, error) {
+	if m.dryRun {
+		return 0, nil
+	}
+
+	m.store.mu.RLock()
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	m.store.mu.RUnlock()
+	if !ok {
+		return 0, &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+
+	n, err := dst.WriteAt(obj.data, 0)
+	return int64(n), err
+}
+
+// Put stores the content of the reader as the dst object.
+func (m *Memory) Put(_ context.Context, src io.Reader, dst *url.URL, metadata Metadata, _ int, _ int64) error {
+	if m.dryRun {
+		return nil
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(data)
+	obj := &memoryObject{
+		data:     data,
+		etag:     hex.EncodeToString(sum[:]),
+		modTime:  time.Now().UTC(),
+		metadata: metadata,
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	m.store.objects[memoryKey(dst.Bucket, dst.Path)] = obj
+	return nil
+}
+
+// CreateDir creates an empty object which represents a directory.
+func (m *Memory) CreateDir(ctx context.Context, dst *url.URL, metadata Metadata) error {
+	dir := dst.Clone()
+	if !strings.HasSuffix(dir.Path, "/") {
+		dir.Path += "/"
+	}
+	return m.Put(ctx, bytes.NewReader(nil), dir, metadata, 0, 0)
+}
+
+// GetTags returns the tags of the object.
+func (m *Memory) GetTags(_ context.Context, src *url.URL) (map[string]string, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	obj, ok := m.store.objects[memoryKey(src.Bucket, src.Path)]
+	if !ok {
+		return nil, &ErrGivenObjectNotFound{ObjectAbsPath: src.Absolute()}
+	}
+
+	tags := make(map[string]string, len(obj.metadata.Tags))
+	for k, v := range obj.metadata.Tags {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags of the object with the given tags.
+func (m *Memory) SetTags(_ context.Context, dst *url.URL, tags map[string]string) error {
+	if m.dryRun {
+		return nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	obj, ok := m.store.objects[memoryKey(dst.Bucket, dst.Path)]
+	if !ok {
+		return &ErrGivenObjectNotFound{ObjectAbsPath: dst.Absolute()}
+	}
+
+	obj.metadata.Tags = make(map[string]string, len(tags))
+	for k, v := range tags {
+		obj.metadata.Tags[k] = v
+	}
+	return nil
+}
+
+// DeleteTags removes all tags of the object.
+func (m *Memory) DeleteTags(ctx context.Context, dst *url.URL) error {
+	return m.SetTags(ctx, dst, nil)
+}
+
+// object returns the Object of the stored object in the given URL.
+func (o *memoryObject) object(u *url.URL) *Object {
+	modTime := o.modTime
+
+	var objtype os.FileMode
+	if strings.HasSuffix(u.Path, "/") {
+		objtype = os.ModeDir
+	}
+
+	return &Object{
+		URL:          u,
+		Etag:         o.etag,
+		ModTime:      &modTime,
+		Size:         int64(len(o.data)),
+		Type:         ObjectType{objtype},
+		StorageClass: StorageClass(o.metadata.StorageClass),
+		UserDefined:  o.metadata.UserDefined,
+
+		ContentType:        o.metadata.ContentType,
+		ContentEncoding:    o.metadata.ContentEncoding,
+		ContentDisposition: o.metadata.ContentDisposition,
+		CacheControl:       o.metadata.CacheControl,
+		Expires:            o.metadata.Expires,
+		EncryptionMethod:   o.metadata.EncryptionMethod,
+		EncryptionKeyID:    o.metadata.EncryptionKeyID,
+	}
+}
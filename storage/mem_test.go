@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/v2/storage/url"
+)
+
+func TestMemoryImplementsRemoteStorageInterface(t *testing.T) {
+	var _ RemoteStorage = new(Memory)
+	var _ RemoteStorage = new(S3)
+	var _ TaggingStorage = new(Memory)
+	var _ TaggingStorage = new(S3)
+}
+
+func newTestMemory() *Memory {
+	return &Memory{store: &memoryStore{objects: map[string]*memoryObject{}}}
+}
+
+func mustURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+
+	u, err := url.New(s)
+	assert.NilError(t, err)
+	return u
+}
+
+func TestNewClientWithMemoryScheme(t *testing.T) {
+	client, err := NewClient(context.Background(), mustURL(t, "mem://bucket/key"), Options{})
+	assert.NilError(t, err)
+
+	_, ok := client.(*Memory)
+	assert.Assert(t, ok, "expected %T to be a memory storage", client)
+}
+
+func TestMemoryPutStatGet(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMemory()
+
+	dst := mustURL(t, "mem://bucket/dir/key.txt")
+	err := m.Put(ctx, strings.NewReader("content"), dst, Metadata{StorageClass: "STANDARD"}, 1, 0)
+	assert.NilError(t, err)
+
+	obj, err := m.Stat(ctx, dst)
+	assert.NilError(t, err)
+	assert.Equal(t, obj.Size, int64(len("content")))
+	assert.Equal(t, obj.Etag, "9a0364b9e99bb480dd25e1f0284c8555")
+	assert.Equal(t, obj.StorageClass, StorageClass("STANDARD"))
+
+	buf := aws.NewWriteAtBuffer(nil)
+	n, err := m.Get(ctx, dst, buf, 1, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(len("content")))
+	assert.Equal(t, string(buf.Bytes()), "content")
+
+	reader, err := m.Read(ctx, dst)
+	assert.NilError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "content")
+
+	reader, err = m.ReadRange(ctx, dst, 2, 3)
+	assert.NilError(t, err)
+	data, err = io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "nte")
+
+	_, err = m.Stat(ctx, mustURL(t, "mem://bucket/missing"))
+	var notFound *ErrGivenObjectNotFound
+	assert.Assert(t, errors.As(err, &notFound))
+}
+
+func TestMemoryList(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMemory()
+
+	for _, key := range []string{"a/1.txt", "a/2.txt", "a/b/3.txt", "c.txt", "d.gz"} {
+		err := m.Put(ctx, bytes.NewReader(nil), mustURL(t, "mem://bucket/"+key), Metadata{}, 1, 0)
+		assert.NilError(t, err)
+	}
+	// objects in other buckets should not be listed.
+	err := m.Put(ctx, bytes.NewReader(nil), mustURL(t, "mem://other/a/1.txt"), Metadata{}, 1, 0)
+	assert.NilError(t, err)
+
+	testcases := []struct {
+		src      string
+		expected []string
+	}{
+		{src: "mem://bucket/", expected: []string{"a/", "c.txt", "d.gz"}},
+		{src: "mem://bucket/a/", expected: []string{"a/1.txt", "a/2.txt", "a/b/"}},
+		{src: "mem://bucket/*", expected: []string{"a/1.txt", "a/2.txt", "a/b/3.txt", "c.txt", "d.gz"}},
+		{src: "mem://bucket/*.txt", expected: []string{"a/1.txt", "a/2.txt", "a/b/3.txt", "c.txt"}},
+		{src: "mem://bucket/c.txt", expected: []string{"c.txt"}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.src, func(t *testing.T) {
+			var keys []string
+			for obj := range m.List(ctx, mustURL(t, tc.src), false) {
+				assert.NilError(t, obj.Err)
+				keys = append(keys, obj.URL.Path)
+			}
+			sort.Strings(keys)
+			assert.DeepEqual(t, keys, tc.expected)
+		})
+	}
+
+	for obj := range m.List(ctx, mustURL(t, "mem://bucket/missing/*"), false) {
+		assert.Equal(t, obj.Err, ErrNoObjectFound)
+	}
+}
+
+func TestMemoryCopyAndDelete(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMemory()
+
+	src := mustURL(t, "mem://bucket/src")
+	dst := mustURL(t, "mem://bucket/dst")
+
+	assert.NilError(t, m.Put(ctx, strings.NewReader("content"), src, Metadata{}, 1, 0))
+	assert.NilError(t, m.Copy(ctx, src, dst, Metadata{}, 1, 0))
+
+	urls := make(chan *url.URL, 2)
+	urls <- src
+	urls <- dst
+	close(urls)
+
+	var deleted []string
+	for obj := range m.MultiDelete(ctx, urls) {
+		assert.NilError(t, obj.Err)
+		deleted = append(deleted, obj.URL.Path)
+	}
+	assert.DeepEqual(t, deleted, []string{"src", "dst"})
+	assert.Equal(t, len(m.store.objects), 0)
+}
+
+func TestMemoryTags(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMemory()
+
+	src := mustURL(t, "mem://bucket/src")
+	dst := mustURL(t, "mem://bucket/dst")
+
+	metadata := Metadata{Tags: map[string]string{"project": "s5cmd"}}
+	assert.NilError(t, m.Put(ctx, strings.NewReader("content"), src, metadata, 1, 0))
+
+	// tags of the source are kept if none are given
+	assert.NilError(t, m.Copy(ctx, src, dst, Metadata{}, 1, 0))
+	tags, err := m.GetTags(ctx, dst)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tags, map[string]string{"project": "s5cmd"})
+
+	assert.NilError(t, m.SetTags(ctx, dst, map[string]string{"retention": "30d"}))
+	tags, err = m.GetTags(ctx, dst)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tags, map[string]string{"retention": "30d"})
+
+	assert.NilError(t, m.DeleteTags(ctx, src))
+	tags, err = m.GetTags(ctx, src)
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 0)
+
+	var notFound *ErrGivenObjectNotFound
+	err = m.SetTags(ctx, mustURL(t, "mem://bucket/missing"), nil)
+	assert.Assert(t, errors.As(err, &notFound))
+}
@@ -36,17 +36,17 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 }
 
 // Copy mocks base method.
-func (m *MockStorage) Copy(ctx context.Context, src, dst *url.URL, metadata Metadata) error {
+func (m *MockStorage) Copy(ctx context.Context, src, dst *url.URL, metadata Metadata, concurrency int, partSize int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Copy", ctx, src, dst, metadata)
+	ret := m.ctrl.Call(m, "Copy", ctx, src, dst, metadata, concurrency, partSize)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Copy indicates an expected call of Copy.
-func (mr *MockStorageMockRecorder) Copy(ctx, src, dst, metadata interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) Copy(ctx, src, dst, metadata, concurrency, partSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockStorage)(nil).Copy), ctx, src, dst, metadata)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockStorage)(nil).Copy), ctx, src, dst, metadata, concurrency, partSize)
 }
 
 // Delete mocks base method.
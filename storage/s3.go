@@ -14,6 +14,7 @@ import (
 	"net/http"
 	urlpkg "net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,11 +33,16 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 
 	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/ratelimit"
 	"github.com/peak/s5cmd/v2/storage/url"
 )
 
 var sentinelURL = urlpkg.URL{}
 
+// ErrObjectChanged indicates that a remote object has changed since its
+// interrupted download was started, hence the download can not be resumed.
+var ErrObjectChanged = fmt.Errorf("object has changed since the download was started")
+
 const (
 	// deleteObjectsMax is the max allowed objects to be deleted on single HTTP
 	// request.
@@ -54,6 +60,10 @@ const (
 	// the ETag of an empty object. Should be used to assume folders.
 	//   Limitation being we can't have zero width objects
 	folderETag = "d41d8cd98f00b204e9800998ecf8427e"
+
+	// the only algorithm of server side encryption with customer provided
+	// keys.
+	sseCustomerAlgorithm = "AES256"
 )
 
 // Re-used AWS sessions dramatically improve performance.
@@ -72,6 +82,7 @@ type S3 struct {
 	useListObjectsV1       bool
 	noSuchUploadRetryCount int
 	requestPayer           string
+	sseCustomerKey         string
 }
 
 func (s *S3) RequestPayer() *string {
@@ -81,6 +92,16 @@ func (s *S3) RequestPayer() *string {
 	return &s.requestPayer
 }
 
+// sseCustomerKeyParams returns the algorithm and the key parameters of the
+// server side encryption with the given customer provided key. It returns
+// nils if no key is given.
+func sseCustomerKeyParams(key string) (*string, *string) {
+	if key == "" {
+		return nil, nil
+	}
+	return aws.String(sseCustomerAlgorithm), aws.String(key)
+}
+
 func parseEndpoint(endpoint string) (urlpkg.URL, error) {
 	if endpoint == "" {
 		return sentinelURL, nil
@@ -114,6 +135,7 @@ func newS3Storage(ctx context.Context, opts Options) (*S3, error) {
 		dryRun:                 opts.DryRun,
 		useListObjectsV1:       opts.UseListObjectsV1,
 		requestPayer:           opts.RequestPayer,
+		sseCustomerKey:         opts.SSECustomerKey,
 		noSuchUploadRetryCount: opts.NoSuchUploadRetryCount,
 	}, nil
 }
@@ -128,6 +150,7 @@ func (s *S3) Stat(ctx context.Context, url *url.URL) (*Object, error) {
 	if url.VersionID != "" {
 		input.SetVersionId(url.VersionID)
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
 
 	output, err := s.api.HeadObjectWithContext(ctx, input)
 	if err != nil {
@@ -151,6 +174,33 @@ func (s *S3) Stat(ctx context.Context, url *url.URL) (*Object, error) {
 		AccessTime: &time.Time{},
 		UserID:     userID,
 		GroupID:    groupID,
+
+		StorageClass: StorageClass(aws.StringValue(output.StorageClass)),
+		Restore:      parseRestoreStatus(aws.StringValue(output.Restore)),
+
+		ContentType:        aws.StringValue(output.ContentType),
+		ContentEncoding:    aws.StringValue(output.ContentEncoding),
+		ContentDisposition: aws.StringValue(output.ContentDisposition),
+		CacheControl:       aws.StringValue(output.CacheControl),
+		Expires:            aws.StringValue(output.Expires),
+		EncryptionMethod:   aws.StringValue(output.ServerSideEncryption),
+		EncryptionKeyID:    aws.StringValue(output.SSEKMSKeyId),
+	}
+
+	if output.SSECustomerAlgorithm != nil {
+		obj.EncryptionMethod = "SSE-C"
+	}
+
+	// unversioned objects have the "null" version on versioned buckets.
+	if versionID := aws.StringValue(output.VersionId); url.VersionID == "" && versionID != "null" {
+		obj.VersionID = versionID
+	}
+
+	if len(output.Metadata) != 0 {
+		obj.UserDefined = make(map[string]string, len(output.Metadata))
+		for k, v := range output.Metadata {
+			obj.UserDefined[k] = aws.StringValue(v)
+		}
 	}
 
 	if strings.Trim(etag, `"`) == folderETag && strings.HasSuffix(url.Absolute(), "/") {
@@ -196,6 +246,18 @@ func (s *S3) Stat(ctx context.Context, url *url.URL) (*Object, error) {
 	return obj, nil
 }
 
+// parseRestoreStatus parses the restore header of HeadObject responses, e.g.
+// `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`.
+func parseRestoreStatus(header string) RestoreStatus {
+	switch {
+	case strings.Contains(header, `ongoing-request="true"`):
+		return RestoreOngoing
+	case strings.Contains(header, `ongoing-request="false"`):
+		return RestoreCompleted
+	}
+	return ""
+}
+
 // List is a non-blocking S3 list operation which paginates and filters S3
 // keys. If no object found or an error is encountered during this period,
 // it sends these errors to object channel.
@@ -315,10 +377,11 @@ func (s *S3) listObjectVersions(ctx context.Context, url *url.URL) <-chan *Objec
 					newurl.VersionID = aws.StringValue(d.VersionId)
 
 					objCh <- &Object{
-						URL:     newurl,
-						ModTime: &mod,
-						Type:    ObjectType{objtype},
-						Size:    0,
+						URL:          newurl,
+						ModTime:      &mod,
+						Type:         ObjectType{objtype},
+						Size:         0,
+						DeleteMarker: true,
 					}
 
 					objectFound = true
@@ -522,29 +585,42 @@ func (s *S3) listObjects(ctx context.Context, url *url.URL) <-chan *Object {
 	return objCh
 }
 
+// maxCopyObjectSize is the maximum size of the objects which can be copied
+// with a single CopyObject request.
+var maxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+
 // Copy is a single-object copy operation which copies objects to S3
-// destination from another S3 source.
-func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata) error {
+// destination from another S3 source. If the part size is given, the sources
+// larger than 5GiB, as given in the metadata, are copied in parts with the
+// given concurrency.
+func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata, concurrency int, partSize int64) error {
 	if s.dryRun {
 		return nil
 	}
 
-	// SDK expects CopySource like "bucket[/key]"
-	copySource := from.EscapedPath()
+	if partSize > 0 && metadata.SourceSize > maxCopyObjectSize {
+		source, err := s.headCopySource(ctx, from, metadata.SSECopySourceCustomerKey)
+		if err != nil {
+			return err
+		}
+		return s.multipartCopy(ctx, from, to, source, metadata, concurrency, partSize)
+	}
+	return s.copyObject(ctx, from, to, metadata)
+}
 
+// copyObject copies the source object to the destination with a single
+// CopyObject request.
+func (s *S3) copyObject(ctx context.Context, from, to *url.URL, metadata Metadata) error {
 	input := &s3.CopyObjectInput{
 		Bucket:       aws.String(to.Bucket),
 		Key:          aws.String(to.Path),
-		CopySource:   aws.String(copySource),
+		CopySource:   aws.String(copySource(from)),
 		RequestPayer: s.RequestPayer(),
+		Metadata:     map[string]*string{},
 	}
-	if from.VersionID != "" {
-		// Unlike many other *Input and *Output types version ID is not a field,
-		// but rather something that must be appended to CopySource string.
-		// This is same in both v1 and v2 SDKs:
-		// https://pkg.go.dev/github.com/aws/aws-sdk-go/service/s3#CopyObjectInput
-		// https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3#CopyObjectInput
-		input.CopySource = aws.String(copySource + "?versionId=" + from.VersionID)
+
+	if metadata.MetadataDirective != "" {
+		input.MetadataDirective = aws.String(metadata.MetadataDirective)
 	}
 
 	storageClass := metadata.StorageClass
@@ -579,6 +655,18 @@ func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata) err
 			input.SSEKMSKeyId = aws.String(sseKmsKeyID)
 		}
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(metadata.SSECustomerKey)
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey = sseCustomerKeyParams(metadata.SSECopySourceCustomerKey)
+
+	if taggingDirective(metadata) == s3.TaggingDirectiveReplace {
+		input.Tagging = aws.String(encodeTags(metadata.Tags))
+		input.TaggingDirective = aws.String(s3.TaggingDirectiveReplace)
+	}
+
+	contentType := metadata.ContentType
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
 
 	contentEncoding := metadata.ContentEncoding
 	if contentEncoding != "" {
@@ -610,21 +698,234 @@ func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata) err
 		input.Metadata["file-atime"] = aws.String(atime)
 	}
 
-	input.Metadata["file-owner"] = aws.String(metadata.FileUID)
-	input.Metadata["file-group"] = aws.String(metadata.FileGID)
+	if metadata.FileUID != "" {
+		input.Metadata["file-owner"] = aws.String(metadata.FileUID)
+	}
+	if metadata.FileGID != "" {
+		input.Metadata["file-group"] = aws.String(metadata.FileGID)
+	}
 
-	if len(metadata.UserDefined) != 0 {
-		m := make(map[string]*string)
-		for k, v := range metadata.UserDefined {
-			m[k] = aws.String(v)
-		}
-		input.Metadata = m
+	for k, v := range metadata.UserDefined {
+		input.Metadata[k] = aws.String(v)
 	}
 
-	_, err := s.api.CopyObject(input)
+	_, err := s.api.CopyObjectWithContext(ctx, input)
 	return err
 }
 
+// copySource returns the copy source parameter of the src object.
+func copySource(src *url.URL) string {
+	// SDK expects CopySource like "bucket[/key]"
+	copySource := src.EscapedPath()
+	if src.VersionID != "" {
+		// Unlike many other *Input and *Output types version ID is not a field,
+		// but rather something that must be appended to CopySource string.
+		// This is same in both v1 and v2 SDKs:
+		// https://pkg.go.dev/github.com/aws/aws-sdk-go/service/s3#CopyObjectInput
+		// https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/s3#CopyObjectInput
+		copySource += "?versionId=" + src.VersionID
+	}
+	return copySource
+}
+
+// headCopySource retrieves the metadata of the source object of a copy,
+// which is encrypted with the given customer provided key, if any.
+func (s *S3) headCopySource(ctx context.Context, src *url.URL, sseCustomerKey string) (*s3.HeadObjectOutput, error) {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(src.Bucket),
+		Key:          aws.String(src.Path),
+		RequestPayer: s.RequestPayer(),
+	}
+	if src.VersionID != "" {
+		input.SetVersionId(src.VersionID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(sseCustomerKey)
+
+	return s.api.HeadObjectWithContext(ctx, input)
+}
+
+// multipartCopy copies the source object to the destination with a multipart
+// upload whose parts are copied on the server side. Unlike CopyObject, the
+// metadata and the tags of the source are not copied by S3, hence they are
+// set on the upload as CopyObject would do: the metadata unless it is
+// replaced, and the tags unless new ones are given.
+func (s *S3) multipartCopy(
+	ctx context.Context,
+	from *url.URL,
+	to *url.URL,
+	source *s3.HeadObjectOutput,
+	metadata Metadata,
+	concurrency int,
+	partSize int64,
+) error {
+	size := aws.Int64Value(source.ContentLength)
+
+	// increase the part size to stay within the part limit, as the uploader
+	// does.
+	if (size+partSize-1)/partSize > s3manager.MaxUploadParts {
+		partSize = size/s3manager.MaxUploadParts + 1
+	}
+
+	// the metadata is given in full if it is replaced.
+	if metadata.MetadataDirective != s3.MetadataDirectiveReplace {
+		copySourceMetadata(&metadata, source)
+	}
+	if taggingDirective(metadata) == s3.TaggingDirectiveCopy {
+		tags, err := s.GetTags(ctx, from)
+		if err != nil {
+			return err
+		}
+		metadata.Tags = tags
+	}
+
+	uploadID, err := s.createMultipartUpload(ctx, to, metadata)
+	if err != nil {
+		return err
+	}
+
+	parts, err := s.copyParts(ctx, from, to, uploadID, size, partSize, metadata, concurrency)
+	if err == nil {
+		_, err = s.api.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(to.Bucket),
+			Key:             aws.String(to.Path),
+			UploadId:        aws.String(uploadID),
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+			RequestPayer:    s.RequestPayer(),
+		})
+	}
+	if err != nil {
+		// the context may be canceled already, while the copied parts must
+		// be deleted regardless.
+		_ = s.AbortMultipartUpload(context.Background(), to, uploadID)
+		return err
+	}
+	return nil
+}
+
+// copySourceMetadata sets the metadata of the source object which is not
+// given.
+func copySourceMetadata(metadata *Metadata, source *s3.HeadObjectOutput) {
+	if len(metadata.UserDefined) == 0 {
+		metadata.UserDefined = aws.StringValueMap(source.Metadata)
+	}
+	if metadata.ContentType == "" {
+		metadata.ContentType = aws.StringValue(source.ContentType)
+	}
+	if metadata.CacheControl == "" {
+		metadata.CacheControl = aws.StringValue(source.CacheControl)
+	}
+	if metadata.ContentEncoding == "" {
+		metadata.ContentEncoding = aws.StringValue(source.ContentEncoding)
+	}
+	if metadata.ContentDisposition == "" {
+		metadata.ContentDisposition = aws.StringValue(source.ContentDisposition)
+	}
+	if metadata.Expires == "" {
+		if t, err := http.ParseTime(aws.StringValue(source.Expires)); err == nil {
+			metadata.Expires = t.Format(time.RFC3339)
+		}
+	}
+}
+
+// taggingDirective returns the tagging directive of the copies with the given
+// metadata. The tags of the source are copied unless new ones are given.
+func taggingDirective(metadata Metadata) string {
+	if len(metadata.Tags) != 0 {
+		return s3.TaggingDirectiveReplace
+	}
+	return s3.TaggingDirectiveCopy
+}
+
+// copyParts copies the parts of the source object to the multipart upload
+// with the given concurrency, and returns the completed parts.
+func (s *S3) copyParts(
+	ctx context.Context,
+	from *url.URL,
+	to *url.URL,
+	uploadID string,
+	size int64,
+	partSize int64,
+	metadata Metadata,
+	concurrency int,
+) ([]*s3.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	total := (size + partSize - 1) / partSize
+	parts := make([]*s3.CompletedPart, total)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		numbers  = make(chan int64)
+	)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				start := (number - 1) * partSize
+				end := start + partSize - 1
+				if end >= size {
+					end = size - 1
+				}
+
+				input := &s3.UploadPartCopyInput{
+					Bucket:          aws.String(to.Bucket),
+					Key:             aws.String(to.Path),
+					UploadId:        aws.String(uploadID),
+					PartNumber:      aws.Int64(number),
+					CopySource:      aws.String(copySource(from)),
+					CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+					RequestPayer:    s.RequestPayer(),
+				}
+				input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(metadata.SSECustomerKey)
+				input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey = sseCustomerKeyParams(metadata.SSECopySourceCustomerKey)
+
+				output, err := s.api.UploadPartCopyWithContext(ctx, input)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+
+				var etag *string
+				if output.CopyPartResult != nil {
+					etag = output.CopyPartResult.ETag
+				}
+				parts[number-1] = &s3.CompletedPart{PartNumber: aws.Int64(number), ETag: etag}
+			}
+		}()
+	}
+
+loop:
+	for number := int64(1); number <= total; number++ {
+		select {
+		case numbers <- number:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(numbers)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
 // Read fetches the remote object and returns its contents as an io.ReadCloser.
 func (s *S3) Read(ctx context.Context, src *url.URL) (io.ReadCloser, error) {
 	input := &s3.GetObjectInput{
@@ -635,13 +936,35 @@ func (s *S3) Read(ctx context.Context, src *url.URL) (io.ReadCloser, error) {
 	if src.VersionID != "" {
 		input.SetVersionId(src.VersionID)
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
 
 	resp, err := s.api.GetObjectWithContext(ctx, input)
 
 	if err != nil {
 		return nil, err
 	}
-	return resp.Body, nil
+	return ratelimit.DownloadReadCloser(ctx, resp.Body), nil
+}
+
+// ReadRange returns a reader of the length bytes of the src object which
+// start at the given offset, which are fetched with a ranged GET request.
+func (s *S3) ReadRange(ctx context.Context, src *url.URL, offset, length int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(src.Bucket),
+		Key:          aws.String(src.Path),
+		Range:        aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+		RequestPayer: s.RequestPayer(),
+	}
+	if src.VersionID != "" {
+		input.SetVersionId(src.VersionID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
+
+	resp, err := s.api.GetObjectWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.DownloadReadCloser(ctx, resp.Body), nil
 }
 
 func (s *S3) Presign(ctx context.Context, from *url.URL, expire time.Duration) (string, error) {
@@ -650,6 +973,7 @@ func (s *S3) Presign(ctx context.Context, from *url.URL, expire time.Duration) (
 		Key:          aws.String(from.Path),
 		RequestPayer: s.RequestPayer(),
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
 
 	req, _ := s.api.GetObjectRequest(input)
 
@@ -678,13 +1002,207 @@ func (s *S3) Get(
 	if from.VersionID != "" {
 		input.VersionId = aws.String(from.VersionID)
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
 
+	to = ratelimit.DownloadWriterAt(ctx, to)
 	return s.downloader.DownloadWithContext(ctx, to, input, func(u *s3manager.Downloader) {
 		u.PartSize = partSize
 		u.Concurrency = concurrency
 	})
 }
 
+// GetResumable is a multipart download operation like Get, except that the
+// downloaded byte ranges are recorded in a journal in the given path. If the
+// journal exists, only the missing ranges are downloaded into the writer. The
+// download is refused with ErrObjectChanged if the object has changed since
+// the journal is created. If the journal is created and the writer can be
+// truncated, it is truncated to the size of the object. The journal is removed
+// once the download completes.
+func (s *S3) GetResumable(
+	ctx context.Context,
+	from *url.URL,
+	to io.WriterAt,
+	journalPath string,
+	concurrency int,
+	partSize int64,
+) (int64, error) {
+	if s.dryRun {
+		return 0, nil
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(from.Bucket),
+		Key:          aws.String(from.Path),
+		RequestPayer: s.RequestPayer(),
+	}
+	if from.VersionID != "" {
+		input.VersionId = aws.String(from.VersionID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
+
+	output, err := s.api.HeadObjectWithContext(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	etag := aws.StringValue(output.ETag)
+	versionID := aws.StringValue(output.VersionId)
+	size := aws.Int64Value(output.ContentLength)
+
+	journal, err := LoadDownloadJournal(journalPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		journal = &DownloadJournal{
+			Source:    from.String(),
+			ETag:      etag,
+			VersionID: versionID,
+			Size:      size,
+			path:      journalPath,
+		}
+		// the ranges of a previous download are not recorded anymore, size
+		// the partial file to the object so that none of them is kept.
+		if t, ok := to.(truncater); ok {
+			if err := t.Truncate(size); err != nil {
+				return 0, err
+			}
+		}
+		if err := journal.Save(); err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	case journal.ETag != etag || journal.VersionID != versionID || journal.Size != size:
+		return 0, ErrObjectChanged
+	}
+
+	if err := s.downloadRanges(ctx, from, to, journal, concurrency, partSize); err != nil {
+		return 0, err
+	}
+
+	return size, journal.Remove()
+}
+
+// truncater is implemented by the files which resumable downloads are written
+// into.
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// downloadRanges downloads the ranges of the object which are missing in the
+// journal.
+func (s *S3) downloadRanges(
+	ctx context.Context,
+	from *url.URL,
+	to io.WriterAt,
+	journal *DownloadJournal,
+	concurrency int,
+	partSize int64,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		ranges   = make(chan ByteRange)
+	)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range ranges {
+				err := s.downloadRange(ctx, from, to, journal, r)
+				if err == nil {
+					err = journal.addRange(r.Start, r.End)
+				}
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+loop:
+	for _, r := range journal.missingRanges(partSize) {
+		select {
+		case ranges <- r:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(ranges)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// downloadRange downloads a range of the object which is recorded in the
+// journal. The ETag of the journal is used as a precondition so that ranges
+// of different versions of the object are never mixed.
+func (s *S3) downloadRange(
+	ctx context.Context,
+	from *url.URL,
+	to io.WriterAt,
+	journal *DownloadJournal,
+	r ByteRange,
+) error {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(from.Bucket),
+		Key:          aws.String(from.Path),
+		Range:        aws.String(fmt.Sprintf("bytes=%d-%d", r.Start, r.End-1)),
+		IfMatch:      aws.String(journal.ETag),
+		RequestPayer: s.RequestPayer(),
+	}
+	if journal.VersionID != "" {
+		input.VersionId = aws.String(journal.VersionID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
+
+	output, err := s.api.GetObjectWithContext(ctx, input)
+	if errHasCode(err, "PreconditionFailed") {
+		return ErrObjectChanged
+	}
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	to = ratelimit.DownloadWriterAt(ctx, to)
+	n, err := io.Copy(&offsetWriter{w: to, offset: r.Start}, output.Body)
+	if err != nil {
+		return err
+	}
+	if n != r.End-r.Start {
+		return fmt.Errorf("unexpected length of range %d-%d: %d", r.Start, r.End-1, n)
+	}
+	return nil
+}
+
+// offsetWriter is an io.Writer which writes to an io.WriterAt sequentially,
+// starting from an offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
+
 type SelectQuery struct {
 	InputFormat           string
 	InputContentStructure string
@@ -807,6 +1325,7 @@ func (s *S3) Select(ctx context.Context, url *url.URL, query *SelectQuery, resul
 		InputSerialization:  inputFormat,
 		OutputSerialization: outputFormat,
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(s.sseCustomerKey)
 
 	resp, err := s.api.SelectObjectContentWithContext(ctx, input)
 	if err != nil {
@@ -918,6 +1437,11 @@ func (s *S3) CreateDir(
 			input.SSEKMSKeyId = aws.String(sseKmsKeyID)
 		}
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(metadata.SSECustomerKey)
+
+	if len(metadata.Tags) != 0 {
+		input.Tagging = aws.String(encodeTags(metadata.Tags))
+	}
 
 	contentEncoding := metadata.ContentEncoding
 	if contentEncoding != "" {
@@ -959,12 +1483,8 @@ func (s *S3) CreateDir(
 		input.Metadata["file-group"] = aws.String(groupID)
 	}
 
-	if len(metadata.UserDefined) != 0 {
-		m := make(map[string]*string)
-		for k, v := range metadata.UserDefined {
-			m[k] = aws.String(v)
-		}
-		input.Metadata = m
+	for k, v := range metadata.UserDefined {
+		input.Metadata[k] = aws.String(v)
 	}
 
 	_, err = s.api.PutObjectWithContext(ctx, input)
@@ -985,6 +1505,27 @@ func (s *S3) Put(
 		return nil
 	}
 
+	input, err := s.uploadInput(reader, to, metadata)
+	if err != nil {
+		return err
+	}
+
+	uploaderOptsFn := func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = concurrency
+	}
+	_, err = s.uploader.UploadWithContext(ctx, input, uploaderOptsFn)
+
+	if errHasCode(err, s3.ErrCodeNoSuchUpload) && s.noSuchUploadRetryCount > 0 {
+		return s.retryOnNoSuchUpload(ctx, to, input, err, uploaderOptsFn)
+	}
+
+	return err
+}
+
+// uploadInput creates the input of an upload of the reader to the given
+// destination with the given metadata.
+func (s *S3) uploadInput(reader io.Reader, to *url.URL, metadata Metadata) (*s3manager.UploadInput, error) {
 	contentType := metadata.ContentType
 	if contentType == "" {
 		contentType = "application/octet-stream"
@@ -1018,7 +1559,7 @@ func (s *S3) Put(
 	if expires != "" {
 		t, err := time.Parse(time.RFC3339, expires)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		input.Expires = aws.Time(t)
 	}
@@ -1031,6 +1572,11 @@ func (s *S3) Put(
 			input.SSEKMSKeyId = aws.String(sseKmsKeyID)
 		}
 	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(metadata.SSECustomerKey)
+
+	if len(metadata.Tags) != 0 {
+		input.Tagging = aws.String(encodeTags(metadata.Tags))
+	}
 
 	contentEncoding := metadata.ContentEncoding
 	if contentEncoding != "" {
@@ -1072,25 +1618,11 @@ func (s *S3) Put(
 		input.Metadata["file-group"] = aws.String(fileGID)
 	}
 
-	if len(metadata.UserDefined) != 0 {
-		m := make(map[string]*string)
-		for k, v := range metadata.UserDefined {
-			m[k] = aws.String(v)
-		}
-		input.Metadata = m
-	}
-
-	uploaderOptsFn := func(u *s3manager.Uploader) {
-		u.PartSize = partSize
-		u.Concurrency = concurrency
-	}
-	_, err := s.uploader.UploadWithContext(ctx, input, uploaderOptsFn)
-
-	if errHasCode(err, s3.ErrCodeNoSuchUpload) && s.noSuchUploadRetryCount > 0 {
-		return s.retryOnNoSuchUpload(ctx, to, input, err, uploaderOptsFn)
+	for k, v := range metadata.UserDefined {
+		input.Metadata[k] = aws.String(v)
 	}
 
-	return err
+	return input, nil
 }
 
 func (s *S3) retryOnNoSuchUpload(ctx aws.Context, to *url.URL, input *s3manager.UploadInput,
@@ -1125,6 +1657,544 @@ func (s *S3) retryOnNoSuchUpload(ctx aws.Context, to *url.URL, input *s3manager.
 	return err
 }
 
+// PutResumable is a multipart upload operation like Put, except that the
+// progress of the upload is recorded in a journal under journalDir. If an
+// earlier upload of the same file to the same destination was interrupted,
+// only the parts which are missing on S3 are uploaded. The journal is removed
+// once the upload completes.
+func (s *S3) PutResumable(
+	ctx context.Context,
+	reader io.ReaderAt,
+	from *url.URL,
+	size int64,
+	modTime time.Time,
+	to *url.URL,
+	metadata Metadata,
+	concurrency int,
+	partSize int64,
+	journalDir string,
+) error {
+	if s.dryRun {
+		return nil
+	}
+
+	// increase the part size to stay within the part limit, as the uploader
+	// does.
+	if (size+partSize-1)/partSize > s3manager.MaxUploadParts {
+		partSize = size/s3manager.MaxUploadParts + 1
+	}
+
+	journal, err := s.openJournal(ctx, journalDir, from, size, modTime, to, partSize)
+	if err != nil {
+		return err
+	}
+
+	uploaded := map[int64]string{}
+	if journal.UploadID != "" {
+		uploaded, err = s.uploadedParts(ctx, to, journal)
+		if errHasCode(err, s3.ErrCodeNoSuchUpload) {
+			msg := log.DebugMessage{Err: fmt.Sprintf("Restarting upload of %v, upload %q no longer exists", to, journal.UploadID)}
+			log.Debug(msg)
+
+			journal.UploadID = ""
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if journal.UploadID == "" {
+		uploadID, err := s.createMultipartUpload(ctx, to, metadata)
+		if err != nil {
+			return err
+		}
+
+		journal.UploadID = uploadID
+		journal.Parts = nil
+		if err := journal.Save(); err != nil {
+			return err
+		}
+	}
+
+	parts, err := s.uploadParts(ctx, reader, to, journal, uploaded, metadata.SSECustomerKey, concurrency)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.api.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(to.Bucket),
+		Key:             aws.String(to.Path),
+		UploadId:        aws.String(journal.UploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+		RequestPayer:    s.RequestPayer(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return journal.Remove()
+}
+
+// openJournal returns the journal of the upload from source to destination.
+// A journal of an upload of another version of the source is discarded after
+// aborting its upload.
+func (s *S3) openJournal(
+	ctx context.Context,
+	dir string,
+	from *url.URL,
+	size int64,
+	modTime time.Time,
+	to *url.URL,
+	partSize int64,
+) (*Journal, error) {
+	path := journalPath(dir, from.Absolute(), to.String())
+
+	journal, err := LoadJournal(path)
+	if err == nil && journal.matches(size, modTime, partSize) {
+		return journal, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if journal != nil && journal.UploadID != "" {
+		err := s.AbortMultipartUpload(ctx, to, journal.UploadID)
+		if err != nil && !errHasCode(err, s3.ErrCodeNoSuchUpload) {
+			return nil, err
+		}
+	}
+
+	return &Journal{
+		Source:      from.Absolute(),
+		Destination: to.String(),
+		Size:        size,
+		ModTime:     modTime,
+		PartSize:    partSize,
+		CreatedAt:   time.Now().UTC(),
+		path:        path,
+	}, nil
+}
+
+// uploadedParts returns the ETags of the parts of the journal's upload which
+// are already on S3, keyed by part number. The journal is updated to match.
+func (s *S3) uploadedParts(ctx context.Context, to *url.URL, journal *Journal) (map[int64]string, error) {
+	input := &s3.ListPartsInput{
+		Bucket:       aws.String(to.Bucket),
+		Key:          aws.String(to.Path),
+		UploadId:     aws.String(journal.UploadID),
+		RequestPayer: s.RequestPayer(),
+	}
+
+	parts := map[int64]string{}
+	var journalParts []JournalPart
+	err := s.api.ListPartsPagesWithContext(ctx, input, func(p *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range p.Parts {
+			number := aws.Int64Value(part.PartNumber)
+			// a part with an unexpected size can not be a part of this upload.
+			if aws.Int64Value(part.Size) != journal.partLength(number) {
+				continue
+			}
+			parts[number] = aws.StringValue(part.ETag)
+			journalParts = append(journalParts, JournalPart{Number: number, ETag: aws.StringValue(part.ETag)})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	journal.Parts = journalParts
+	return parts, journal.Save()
+}
+
+// uploadParts uploads the parts of the journal's upload which are not
+// uploaded yet, and returns all of the parts of the upload in order.
+func (s *S3) uploadParts(
+	ctx context.Context,
+	reader io.ReaderAt,
+	to *url.URL,
+	journal *Journal,
+	uploaded map[int64]string,
+	sseCustomerKey string,
+	concurrency int,
+) ([]*s3.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	total := journal.partCount()
+	parts := make([]*s3.CompletedPart, total)
+	for number, etag := range uploaded {
+		if number <= total {
+			parts[number-1] = &s3.CompletedPart{PartNumber: aws.Int64(number), ETag: aws.String(etag)}
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		numbers  = make(chan int64)
+	)
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				offset := (number - 1) * journal.PartSize
+				input := &s3.UploadPartInput{
+					Bucket:       aws.String(to.Bucket),
+					Key:          aws.String(to.Path),
+					UploadId:     aws.String(journal.UploadID),
+					PartNumber:   aws.Int64(number),
+					Body:         io.NewSectionReader(reader, offset, journal.partLength(number)),
+					RequestPayer: s.RequestPayer(),
+				}
+				input.SSECustomerAlgorithm, input.SSECustomerKey = sseCustomerKeyParams(sseCustomerKey)
+
+				output, err := s.api.UploadPartWithContext(ctx, input)
+				if err == nil {
+					err = journal.addPart(number, aws.StringValue(output.ETag))
+				}
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				parts[number-1] = &s3.CompletedPart{PartNumber: aws.Int64(number), ETag: output.ETag}
+			}
+		}()
+	}
+
+loop:
+	for number := int64(1); number <= total; number++ {
+		if parts[number-1] != nil {
+			continue
+		}
+		select {
+		case numbers <- number:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(numbers)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+// createMultipartUpload initiates a multipart upload to the given destination
+// and returns its upload ID.
+func (s *S3) createMultipartUpload(ctx context.Context, to *url.URL, metadata Metadata) (string, error) {
+	upload, err := s.uploadInput(nil, to, metadata)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := s.api.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               upload.Bucket,
+		Key:                  upload.Key,
+		ACL:                  upload.ACL,
+		CacheControl:         upload.CacheControl,
+		ContentDisposition:   upload.ContentDisposition,
+		ContentEncoding:      upload.ContentEncoding,
+		ContentType:          upload.ContentType,
+		Expires:              upload.Expires,
+		Metadata:             upload.Metadata,
+		RequestPayer:         upload.RequestPayer,
+		SSEKMSKeyId:          upload.SSEKMSKeyId,
+		ServerSideEncryption: upload.ServerSideEncryption,
+		SSECustomerAlgorithm: upload.SSECustomerAlgorithm,
+		SSECustomerKey:       upload.SSECustomerKey,
+		StorageClass:         upload.StorageClass,
+		Tagging:              upload.Tagging,
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.UploadId), nil
+}
+
+// AbortMultipartUpload aborts the multipart upload with the given ID, and
+// deletes its uploaded parts.
+func (s *S3) AbortMultipartUpload(ctx context.Context, to *url.URL, uploadID string) error {
+	if s.dryRun {
+		return nil
+	}
+
+	_, err := s.api.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:       aws.String(to.Bucket),
+		Key:          aws.String(to.Path),
+		UploadId:     aws.String(uploadID),
+		RequestPayer: s.RequestPayer(),
+	})
+	return err
+}
+
+// MultipartUpload is an incomplete multipart upload of an object.
+type MultipartUpload struct {
+	URL          *url.URL
+	UploadID     string
+	Initiated    time.Time
+	StorageClass StorageClass
+	Err          error
+}
+
+// ListMultipartUploads lists the incomplete multipart uploads of the objects
+// that matches the src.
+func (s *S3) ListMultipartUploads(ctx context.Context, src *url.URL) <-chan *MultipartUpload {
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(src.Bucket),
+		Prefix: aws.String(src.Prefix),
+	}
+
+	uploadCh := make(chan *MultipartUpload)
+
+	go func() {
+		defer close(uploadCh)
+
+		err := s.api.ListMultipartUploadsPagesWithContext(ctx, input, func(p *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+			for _, upload := range p.Uploads {
+				key := aws.StringValue(upload.Key)
+				if !src.Match(key) && key != src.Path {
+					continue
+				}
+
+				newurl := src.Clone()
+				newurl.Path = key
+
+				uploadCh <- &MultipartUpload{
+					URL:          newurl,
+					UploadID:     aws.StringValue(upload.UploadId),
+					Initiated:    aws.TimeValue(upload.Initiated).UTC(),
+					StorageClass: StorageClass(aws.StringValue(upload.StorageClass)),
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			uploadCh <- &MultipartUpload{Err: err}
+		}
+	}()
+
+	return uploadCh
+}
+
+// ListParts returns the number and the total size of the uploaded parts of
+// the multipart upload with the given ID.
+func (s *S3) ListParts(ctx context.Context, to *url.URL, uploadID string) (int, int64, error) {
+	input := &s3.ListPartsInput{
+		Bucket:       aws.String(to.Bucket),
+		Key:          aws.String(to.Path),
+		UploadId:     aws.String(uploadID),
+		RequestPayer: s.RequestPayer(),
+	}
+
+	var (
+		count int
+		size  int64
+	)
+	err := s.api.ListPartsPagesWithContext(ctx, input, func(p *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range p.Parts {
+			count++
+			size += aws.Int64Value(part.Size)
+		}
+		return !lastPage
+	})
+	return count, size, err
+}
+
+// Restore initiates the restore of the archived src object with the given
+// retrieval tier, which keeps its restored copy for the given number of days.
+// It succeeds if a restore of the object is already in progress.
+func (s *S3) Restore(ctx context.Context, src *url.URL, days int64, tier string) error {
+	if s.dryRun {
+		return nil
+	}
+
+	input := &s3.RestoreObjectInput{
+		Bucket:       aws.String(src.Bucket),
+		Key:          aws.String(src.Path),
+		RequestPayer: s.RequestPayer(),
+		RestoreRequest: &s3.RestoreRequest{
+			Days: aws.Int64(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{
+				Tier: aws.String(tier),
+			},
+		},
+	}
+	if src.VersionID != "" {
+		input.SetVersionId(src.VersionID)
+	}
+
+	_, err := s.api.RestoreObjectWithContext(ctx, input)
+	if errHasCode(err, "RestoreAlreadyInProgress") {
+		return nil
+	}
+	return err
+}
+
+// GetTags returns the tags of the src object.
+func (s *S3) GetTags(ctx context.Context, src *url.URL) (map[string]string, error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket:       aws.String(src.Bucket),
+		Key:          aws.String(src.Path),
+		RequestPayer: s.RequestPayer(),
+	}
+	if src.VersionID != "" {
+		input.SetVersionId(src.VersionID)
+	}
+
+	output, err := s.api.GetObjectTaggingWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(output.TagSet))
+	for _, tag := range output.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags of the dst object with the given tags.
+func (s *S3) SetTags(ctx context.Context, dst *url.URL, tags map[string]string) error {
+	if s.dryRun {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]*s3.Tag, 0, len(keys))
+	for _, k := range keys {
+		tagSet = append(tagSet, &s3.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+
+	input := &s3.PutObjectTaggingInput{
+		Bucket:       aws.String(dst.Bucket),
+		Key:          aws.String(dst.Path),
+		RequestPayer: s.RequestPayer(),
+		Tagging:      &s3.Tagging{TagSet: tagSet},
+	}
+	if dst.VersionID != "" {
+		input.SetVersionId(dst.VersionID)
+	}
+
+	_, err := s.api.PutObjectTaggingWithContext(ctx, input)
+	return err
+}
+
+// DeleteTags removes all tags of the dst object.
+func (s *S3) DeleteTags(ctx context.Context, dst *url.URL) error {
+	if s.dryRun {
+		return nil
+	}
+
+	input := &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(dst.Bucket),
+		Key:    aws.String(dst.Path),
+	}
+	if dst.VersionID != "" {
+		input.SetVersionId(dst.VersionID)
+	}
+
+	_, err := s.api.DeleteObjectTaggingWithContext(ctx, input)
+	return err
+}
+
+// ACL is the access control list of an object.
+type ACL struct {
+	policy *s3.AccessControlPolicy
+}
+
+// IsPrivate reports whether the ACL only grants full control to the owner of
+// the object, which is the ACL of the objects written without a canned ACL.
+func (a *ACL) IsPrivate() bool {
+	if len(a.policy.Grants) != 1 {
+		return len(a.policy.Grants) == 0
+	}
+
+	grant := a.policy.Grants[0]
+	if grant.Grantee == nil || a.policy.Owner == nil {
+		return false
+	}
+	return aws.StringValue(grant.Permission) == s3.PermissionFullControl &&
+		aws.StringValue(grant.Grantee.Type) == s3.TypeCanonicalUser &&
+		aws.StringValue(grant.Grantee.ID) == aws.StringValue(a.policy.Owner.ID)
+}
+
+// GetACL returns the access control list of the src object.
+func (s *S3) GetACL(ctx context.Context, src *url.URL) (*ACL, error) {
+	input := &s3.GetObjectAclInput{
+		Bucket:       aws.String(src.Bucket),
+		Key:          aws.String(src.Path),
+		RequestPayer: s.RequestPayer(),
+	}
+	if src.VersionID != "" {
+		input.SetVersionId(src.VersionID)
+	}
+
+	output, err := s.api.GetObjectAclWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ACL{
+		policy: &s3.AccessControlPolicy{
+			Owner:  output.Owner,
+			Grants: output.Grants,
+		},
+	}, nil
+}
+
+// SetACL replaces the access control list of the dst object with the given
+// one.
+func (s *S3) SetACL(ctx context.Context, dst *url.URL, acl *ACL) error {
+	if s.dryRun {
+		return nil
+	}
+
+	input := &s3.PutObjectAclInput{
+		Bucket:              aws.String(dst.Bucket),
+		Key:                 aws.String(dst.Path),
+		RequestPayer:        s.RequestPayer(),
+		AccessControlPolicy: acl.policy,
+	}
+	if dst.VersionID != "" {
+		input.SetVersionId(dst.VersionID)
+	}
+
+	_, err := s.api.PutObjectAclWithContext(ctx, input)
+	return err
+}
+
+// encodeTags encodes the tags as URL query parameters, which is the form
+// expected by the Tagging field of the upload and copy requests.
+func encodeTags(tags map[string]string) string {
+	values := urlpkg.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
 // chunk is an object identifier container which is used on MultiDelete
 // operations. Since DeleteObjects API allows deleting objects up to 1000,
 // splitting keys into multiple chunks is required.
@@ -1468,6 +2538,8 @@ func (sc *SessionCache) newSession(ctx context.Context, opts Options) (*session.
 		return nil, err
 	}
 
+	sess.Handlers.Send.PushFrontNamed(limitUploadHandler)
+
 	// get region of the bucket and create session accordingly. if the region
 	// is not provided, it means we want region-independent session
 	// for operations such as listing buckets, making a new bucket etc.
@@ -1543,6 +2615,16 @@ func newCustomRetryer(maxRetries int) *customRetryer {
 	}
 }
 
+// throttled is called when a request is throttled by the remote storage.
+var throttled = func() {}
+
+// SetThrottledFunc sets the function which is called when a request is
+// throttled by the remote storage. It must be set before any requests are
+// sent.
+func SetThrottledFunc(fn func()) {
+	throttled = fn
+}
+
 // ShouldRetry overrides SDK's built in DefaultRetryer, adding custom retry
 // logics that are not included in the SDK.
 func (c *customRetryer) ShouldRetry(req *request.Request) bool {
@@ -1556,6 +2638,11 @@ func (c *customRetryer) ShouldRetry(req *request.Request) bool {
 		return false
 	}
 
+	// let the workers back off, if they are adjusted to throttling.
+	if isThrottled(req) {
+		throttled()
+	}
+
 	if shouldRetry && req.Error != nil {
 		err := fmt.Errorf("retryable error: %v", req.Error)
 		msg := log.DebugMessage{Err: err.Error()}
@@ -1565,6 +2652,34 @@ func (c *customRetryer) ShouldRetry(req *request.Request) bool {
 	return shouldRetry
 }
 
+// isThrottled reports whether the request failed because the service is
+// throttling the requests.
+func isThrottled(req *request.Request) bool {
+	if errHasCode(req.Error, "SlowDown") || request.IsErrorThrottle(req.Error) {
+		return true
+	}
+
+	if req.HTTPResponse == nil {
+		return false
+	}
+	code := req.HTTPResponse.StatusCode
+	return code == http.StatusServiceUnavailable || code == http.StatusTooManyRequests
+}
+
+// limitUploadHandler limits the bandwidth of the request bodies. The body
+// which is sent is limited rather than the body of the request, which is also
+// read to sign the request, so that each uploaded byte is counted once.
+var limitUploadHandler = request.NamedHandler{
+	Name: "s5cmd.LimitUploadHandler",
+	Fn: func(r *request.Request) {
+		body := r.HTTPRequest.Body
+		if body == nil || body == request.NoBody {
+			return
+		}
+		r.HTTPRequest.Body = ratelimit.UploadReadCloser(r.Context(), body)
+	},
+}
+
 var insecureHTTPClient = &http.Client{
 	Transport: &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
@@ -1614,6 +2729,12 @@ func IsCancelationError(err error) bool {
 	return errHasCode(err, request.CanceledErrorCode)
 }
 
+// IsNoSuchUploadError reports whether given error is caused by a multipart
+// upload which does not exist anymore.
+func IsNoSuchUploadError(err error) bool {
+	return errHasCode(err, s3.ErrCodeNoSuchUpload)
+}
+
 // generate a retry ID for this upload attempt
 func generateRetryID() *string {
 	num, _ := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
@@ -3,6 +3,8 @@ package storage
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -11,8 +13,11 @@ import (
 	"net/http/httptest"
 	urlpkg "net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -30,6 +35,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/peak/s5cmd/v2/log"
+	"github.com/peak/s5cmd/v2/ratelimit"
 	"github.com/peak/s5cmd/v2/storage/url"
 )
 
@@ -575,6 +581,83 @@ func TestS3Retry(t *testing.T) {
 	}
 }
 
+func TestS3IsThrottled(t *testing.T) {
+	testcases := []struct {
+		name       string
+		err        error
+		statusCode int
+		expected   bool
+	}{
+		{
+			name:     "SlowDown",
+			err:      awserr.New("SlowDown", "Please reduce your request rate.", nil),
+			expected: true,
+		},
+		{
+			name:     "Throttling",
+			err:      awserr.New("Throttling", "throttling", nil),
+			expected: true,
+		},
+		{
+			name:       "ServiceUnavailable",
+			err:        awserr.New("ServiceUnavailable", "service unavailable", nil),
+			statusCode: http.StatusServiceUnavailable,
+			expected:   true,
+		},
+		{
+			name:       "TooManyRequests",
+			err:        fmt.Errorf("too many requests"),
+			statusCode: http.StatusTooManyRequests,
+			expected:   true,
+		},
+		{
+			name:       "InternalError",
+			err:        awserr.New("InternalError", "internal error", nil),
+			statusCode: http.StatusInternalServerError,
+			expected:   false,
+		},
+		{
+			name:     "ConnectionReset",
+			err:      fmt.Errorf("connection reset by peer"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := &request.Request{Error: tc.err}
+			if tc.statusCode != 0 {
+				req.HTTPResponse = &http.Response{StatusCode: tc.statusCode}
+			}
+
+			if got := isThrottled(req); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestS3RetryerCallsThrottledFunc(t *testing.T) {
+	log.Init("debug", false)
+
+	var count int
+	SetThrottledFunc(func() { count++ })
+	defer SetThrottledFunc(func() {})
+
+	retryer := newCustomRetryer(1)
+
+	req := &request.Request{Error: awserr.New("SlowDown", "Please reduce your request rate.", nil)}
+	retryer.ShouldRetry(req)
+
+	req = &request.Request{Error: awserr.New("InternalError", "internal error", nil)}
+	retryer.ShouldRetry(req)
+
+	if count != 1 {
+		t.Errorf("expected the throttled function to be called once, got %v", count)
+	}
+}
+
 func TestS3RetryOnNoSuchUpload(t *testing.T) {
 	log.Init("debug", false)
 
@@ -652,6 +735,535 @@ func TestS3RetryOnNoSuchUpload(t *testing.T) {
 	}
 }
 
+func TestS3PutResumable(t *testing.T) {
+	log.Init("debug", false)
+
+	noSuchUploadError := awserr.New(s3.ErrCodeNoSuchUpload, "The specified upload does not exist.", nil)
+	testcases := []struct {
+		name          string
+		journalUpload string
+		listedParts   []*s3.Part
+		listErr       error
+		expectedParts []int64
+		expectCreate  bool
+	}{
+		{
+			name:          "no journal",
+			expectedParts: []int64{1, 2, 3},
+			expectCreate:  true,
+		},
+		{
+			name:          "resume from journal",
+			journalUpload: "upload-1",
+			listedParts: []*s3.Part{
+				{PartNumber: aws.Int64(1), Size: aws.Int64(4), ETag: aws.String("etag-1")},
+				// the size does not match, so it should be uploaded again.
+				{PartNumber: aws.Int64(2), Size: aws.Int64(3), ETag: aws.String("etag-2")},
+			},
+			expectedParts: []int64{2, 3},
+		},
+		{
+			name:          "upload in journal no longer exists",
+			journalUpload: "upload-1",
+			listErr:       noSuchUploadError,
+			expectedParts: []int64{1, 2, 3},
+			expectCreate:  true,
+		},
+	}
+
+	const content = "0123456789"
+	modTime := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	from, err := url.New("/tmp/file")
+	assert.NilError(t, err)
+	to, err := url.New("s3://bucket/key")
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := journalPath(dir, from.Absolute(), to.String())
+			if tc.journalUpload != "" {
+				journal := &Journal{
+					Source:      from.Absolute(),
+					Destination: to.String(),
+					UploadID:    tc.journalUpload,
+					Size:        int64(len(content)),
+					ModTime:     modTime,
+					PartSize:    4,
+					path:        path,
+				}
+				assert.NilError(t, journal.Save())
+			}
+
+			mockAPI := s3.New(unit.Session)
+			mockS3 := &S3{api: mockAPI}
+
+			var (
+				created   bool
+				uploaded  []int64
+				completed []*s3.CompletedPart
+				mu        sync.Mutex
+			)
+
+			mockAPI.Handlers.Send.Clear()
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")),
+				}
+			})
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.ValidateResponse.Clear()
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				switch r.Operation.Name {
+				case "CreateMultipartUpload":
+					created = true
+					r.Data.(*s3.CreateMultipartUploadOutput).UploadId = aws.String("upload-2")
+				case "ListParts":
+					if tc.listErr != nil {
+						r.Error = tc.listErr
+						return
+					}
+					r.Data.(*s3.ListPartsOutput).Parts = tc.listedParts
+				case "UploadPart":
+					input := r.Params.(*s3.UploadPartInput)
+					mu.Lock()
+					uploaded = append(uploaded, aws.Int64Value(input.PartNumber))
+					mu.Unlock()
+					r.Data.(*s3.UploadPartOutput).ETag = aws.String(fmt.Sprintf("etag-%d", aws.Int64Value(input.PartNumber)))
+				case "CompleteMultipartUpload":
+					completed = r.Params.(*s3.CompleteMultipartUploadInput).MultipartUpload.Parts
+				}
+			})
+
+			err := mockS3.PutResumable(context.Background(), strings.NewReader(content), from, int64(len(content)), modTime, to, Metadata{}, 2, 4, dir)
+			assert.NilError(t, err)
+
+			assert.Equal(t, created, tc.expectCreate)
+			assert.Equal(t, len(uploaded), len(tc.expectedParts))
+			for _, number := range tc.expectedParts {
+				assert.Assert(t, containsPart(uploaded, number), "part %d is not uploaded", number)
+			}
+
+			assert.Equal(t, len(completed), 3)
+			for i, part := range completed {
+				assert.Equal(t, aws.Int64Value(part.PartNumber), int64(i+1))
+				assert.Equal(t, aws.StringValue(part.ETag), fmt.Sprintf("etag-%d", i+1))
+			}
+
+			// journal should be removed after the upload is completed.
+			_, err = os.Stat(path)
+			assert.Assert(t, errors.Is(err, os.ErrNotExist))
+		})
+	}
+}
+
+func TestS3PutRateLimit(t *testing.T) {
+	const rate = 256 << 10
+
+	ratelimit.Init(rate, 0, 0)
+	defer ratelimit.Init(0, 0, 0)
+
+	// the body is read once to sign the request and once more to send it,
+	// only the latter is limited. the first second's worth of bytes is sent
+	// without waiting.
+	content := bytes.Repeat([]byte("s5cmd"), 2*rate/5)
+	sum := sha256.Sum256(content)
+
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("X-Amz-Content-Sha256"), hex.EncodeToString(sum[:]))
+
+		n, _ := io.Copy(io.Discard, r.Body)
+		atomic.AddInt64(&received, n)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sess := unit.Session.Copy(&aws.Config{
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	sess.Handlers.Send.PushFrontNamed(limitUploadHandler)
+
+	api := s3.New(sess)
+	mockS3 := &S3{api: api, uploader: s3manager.NewUploaderWithClient(api)}
+
+	u, err := url.New("s3://bucket/key")
+	assert.NilError(t, err)
+
+	start := time.Now()
+	err = mockS3.Put(context.Background(), bytes.NewReader(content), u, Metadata{}, 1, s3manager.DefaultUploadPartSize)
+	assert.NilError(t, err)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, atomic.LoadInt64(&received), int64(len(content)))
+	if elapsed < 800*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatalf("expected the upload to take about a second, took %v", elapsed)
+	}
+}
+
+func TestS3MultipartCopy(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+
+	testcases := []struct {
+		name           string
+		size           int64
+		metadata       Metadata
+		copyErr        error
+		expectedRanges []string
+		expectedTags   string
+		expectedType   string
+		expectGetTags  bool
+		expectAbort    bool
+	}{
+		{
+			name:           "source is not larger than the maximum size of copies",
+			size:           4,
+			expectedRanges: nil,
+		},
+		{
+			name:           "copy in parts",
+			size:           10,
+			expectedRanges: []string{"bytes=0-3", "bytes=4-7", "bytes=8-9"},
+			expectedTags:   "project=s5cmd",
+			expectedType:   "text/plain",
+			expectGetTags:  true,
+		},
+		{
+			name: "copy in parts with given metadata and tags",
+			size: 8,
+			metadata: Metadata{
+				ContentType:  "application/json",
+				StorageClass: "STANDARD_IA",
+				Tags:         map[string]string{"retention": "30d"},
+			},
+			expectedRanges: []string{"bytes=0-3", "bytes=4-7"},
+			expectedTags:   "retention=30d",
+			expectedType:   "application/json",
+		},
+		{
+			name: "copy in parts with replaced metadata",
+			size: 8,
+			metadata: Metadata{
+				MetadataDirective: "REPLACE",
+			},
+			expectedRanges: []string{"bytes=0-3", "bytes=4-7"},
+			expectedTags:   "project=s5cmd",
+			expectedType:   "application/octet-stream",
+			expectGetTags:  true,
+		},
+		{
+			name:           "abort upload if a part fails",
+			size:           10,
+			copyErr:        awserr.New("InternalError", "internal error", nil),
+			expectedRanges: []string{"bytes=0-3", "bytes=4-7", "bytes=8-9"},
+			expectedTags:   "project=s5cmd",
+			expectedType:   "text/plain",
+			expectGetTags:  true,
+			expectAbort:    true,
+		},
+	}
+
+	// the sources larger than 4 bytes are copied in parts.
+	defer func(size int64) { maxCopyObjectSize = size }(maxCopyObjectSize)
+	maxCopyObjectSize = 4
+
+	from, err := url.New("s3://bucket/src")
+	assert.NilError(t, err)
+	to, err := url.New("s3://bucket/dst")
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+			mockS3 := &S3{api: mockAPI}
+
+			var (
+				copiedObject bool
+				headObject   bool
+				getTags      bool
+				ranges       []string
+				completed    []*s3.CompletedPart
+				aborted      bool
+				mu           sync.Mutex
+			)
+
+			mockAPI.Handlers.Send.Clear()
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("<CopyPartResult></CopyPartResult>")),
+				}
+			})
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.ValidateResponse.Clear()
+			mockAPI.Handlers.Retry.Clear()
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				switch r.Operation.Name {
+				case "HeadObject":
+					headObject = true
+					assert.Equal(t, valueAtPath(r.Params, "SSECustomerKey"), key)
+					output := r.Data.(*s3.HeadObjectOutput)
+					output.ContentLength = aws.Int64(tc.size)
+					output.ContentType = aws.String("text/plain")
+					output.Metadata = map[string]*string{"owner": aws.String("s5cmd")}
+				case "GetObjectTagging":
+					getTags = true
+					r.Data.(*s3.GetObjectTaggingOutput).TagSet = []*s3.Tag{
+						{Key: aws.String("project"), Value: aws.String("s5cmd")},
+					}
+				case "CopyObject":
+					copiedObject = true
+				case "CreateMultipartUpload":
+					assert.Equal(t, valueAtPath(r.Params, "Tagging"), tc.expectedTags)
+					input := r.Params.(*s3.CreateMultipartUploadInput)
+					assert.Equal(t, aws.StringValue(input.ContentType), tc.expectedType)
+					// the metadata of the source is not kept if it is replaced.
+					owner := ""
+					if tc.metadata.MetadataDirective != "REPLACE" {
+						owner = "s5cmd"
+					}
+					assert.Equal(t, aws.StringValue(input.Metadata["owner"]), owner)
+					if tc.metadata.StorageClass != "" {
+						assert.Equal(t, valueAtPath(r.Params, "StorageClass"), tc.metadata.StorageClass)
+					}
+					r.Data.(*s3.CreateMultipartUploadOutput).UploadId = aws.String("upload-1")
+				case "UploadPartCopy":
+					input := r.Params.(*s3.UploadPartCopyInput)
+					assert.Equal(t, aws.StringValue(input.CopySource), "bucket/src")
+					assert.Equal(t, aws.StringValue(input.CopySourceSSECustomerKey), key)
+					mu.Lock()
+					ranges = append(ranges, aws.StringValue(input.CopySourceRange))
+					mu.Unlock()
+					if tc.copyErr != nil && aws.Int64Value(input.PartNumber) == 2 {
+						r.Error = tc.copyErr
+						return
+					}
+					r.Data.(*s3.UploadPartCopyOutput).CopyPartResult = &s3.CopyPartResult{
+						ETag: aws.String(fmt.Sprintf("etag-%d", aws.Int64Value(input.PartNumber))),
+					}
+				case "CompleteMultipartUpload":
+					completed = r.Params.(*s3.CompleteMultipartUploadInput).MultipartUpload.Parts
+				case "AbortMultipartUpload":
+					aborted = true
+				}
+			})
+
+			metadata := tc.metadata
+			metadata.SSECopySourceCustomerKey = key
+			metadata.SourceSize = tc.size
+
+			err := mockS3.Copy(context.Background(), from, to, metadata, 2, 4)
+			assert.Equal(t, err != nil, tc.copyErr != nil, "unexpected error: %v", err)
+			assert.Equal(t, aborted, tc.expectAbort)
+			assert.Equal(t, getTags, tc.expectGetTags)
+
+			// the metadata of the source is only retrieved if it is too
+			// large to be copied at once.
+			assert.Equal(t, headObject, tc.expectedRanges != nil)
+
+			if tc.expectedRanges == nil {
+				assert.Assert(t, copiedObject)
+				return
+			}
+
+			// the first failure cancels the copies of the remaining parts.
+			if tc.copyErr != nil {
+				return
+			}
+
+			sort.Strings(ranges)
+			assert.DeepEqual(t, ranges, tc.expectedRanges)
+
+			assert.Equal(t, len(completed), len(tc.expectedRanges))
+			for i, part := range completed {
+				assert.Equal(t, aws.Int64Value(part.PartNumber), int64(i+1))
+				assert.Equal(t, aws.StringValue(part.ETag), fmt.Sprintf("etag-%d", i+1))
+			}
+		})
+	}
+}
+
+func TestS3ACL(t *testing.T) {
+	owner := &s3.Owner{ID: aws.String("owner-id")}
+	ownerGrant := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String("owner-id")},
+		Permission: aws.String(s3.PermissionFullControl),
+	}
+	publicGrant := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String("http://acs.amazonaws.com/groups/global/AllUsers")},
+		Permission: aws.String(s3.PermissionRead),
+	}
+
+	testcases := []struct {
+		name    string
+		grants  []*s3.Grant
+		private bool
+	}{
+		{name: "no grants", private: true},
+		{name: "owner full control", grants: []*s3.Grant{ownerGrant}, private: true},
+		{name: "public read", grants: []*s3.Grant{ownerGrant, publicGrant}, private: false},
+	}
+
+	u, err := url.New("s3://bucket/key")
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+			mockS3 := &S3{api: mockAPI}
+
+			var put *s3.AccessControlPolicy
+
+			mockAPI.Handlers.Send.Clear()
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+			})
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.ValidateResponse.Clear()
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				switch r.Operation.Name {
+				case "GetObjectAcl":
+					output := r.Data.(*s3.GetObjectAclOutput)
+					output.Owner = owner
+					output.Grants = tc.grants
+				case "PutObjectAcl":
+					put = r.Params.(*s3.PutObjectAclInput).AccessControlPolicy
+				}
+			})
+
+			acl, err := mockS3.GetACL(context.Background(), u)
+			assert.NilError(t, err)
+			assert.Equal(t, acl.IsPrivate(), tc.private)
+
+			assert.NilError(t, mockS3.SetACL(context.Background(), u, acl))
+			assert.DeepEqual(t, put, &s3.AccessControlPolicy{Owner: owner, Grants: tc.grants})
+		})
+	}
+}
+
+func TestS3GetResumable(t *testing.T) {
+	const content = "0123456789"
+
+	testcases := []struct {
+		name           string
+		journal        *DownloadJournal
+		expectedRanges []string
+		expectedErr    error
+	}{
+		{
+			name:           "no journal",
+			expectedRanges: []string{"bytes=0-3", "bytes=4-7", "bytes=8-9"},
+		},
+		{
+			name: "resume from journal",
+			journal: &DownloadJournal{
+				ETag:   `"etag"`,
+				Size:   int64(len(content)),
+				Ranges: []ByteRange{{Start: 0, End: 4}},
+			},
+			expectedRanges: []string{"bytes=4-7", "bytes=8-9"},
+		},
+		{
+			name: "object has changed",
+			journal: &DownloadJournal{
+				ETag:   `"another-etag"`,
+				Size:   int64(len(content)),
+				Ranges: []ByteRange{{Start: 0, End: 4}},
+			},
+			expectedErr: ErrObjectChanged,
+		},
+	}
+
+	from, err := url.New("s3://bucket/key")
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "key.json")
+			if tc.journal != nil {
+				tc.journal.path = path
+				assert.NilError(t, tc.journal.Save())
+			}
+
+			mockAPI := s3.New(unit.Session)
+			mockS3 := &S3{api: mockAPI}
+
+			var (
+				mu     sync.Mutex
+				ranges []string
+			)
+
+			mockAPI.Handlers.Send.Clear()
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.ValidateResponse.Clear()
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{}
+				switch r.Operation.Name {
+				case "HeadObject":
+					output := r.Data.(*s3.HeadObjectOutput)
+					output.ETag = aws.String(`"etag"`)
+					output.ContentLength = aws.Int64(int64(len(content)))
+				case "GetObject":
+					input := r.Params.(*s3.GetObjectInput)
+					assert.Equal(t, aws.StringValue(input.IfMatch), `"etag"`)
+
+					mu.Lock()
+					ranges = append(ranges, aws.StringValue(input.Range))
+					mu.Unlock()
+
+					var start, end int
+					fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-%d", &start, &end)
+					r.Data.(*s3.GetObjectOutput).Body = io.NopCloser(strings.NewReader(content[start : end+1]))
+				}
+			})
+
+			buf := aws.NewWriteAtBuffer(make([]byte, len(content)))
+			if tc.journal != nil {
+				copy(buf.Bytes(), content[:4])
+			}
+
+			size, err := mockS3.GetResumable(context.Background(), from, buf, path, 2, 4)
+			if tc.expectedErr != nil {
+				assert.Equal(t, err, tc.expectedErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, size, int64(len(content)))
+			assert.Equal(t, string(buf.Bytes()), content)
+
+			sort.Strings(ranges)
+			assert.DeepEqual(t, ranges, tc.expectedRanges)
+
+			// journal should be removed after the download is completed.
+			_, err = os.Stat(path)
+			assert.Assert(t, errors.Is(err, os.ErrNotExist))
+		})
+	}
+}
+
+func containsPart(parts []int64, number int64) bool {
+	for _, part := range parts {
+		if part == number {
+			return true
+		}
+	}
+	return false
+}
+
 func TestS3CopyEncryptionRequest(t *testing.T) {
 	testcases := []struct {
 		name     string
@@ -750,7 +1362,7 @@ func TestS3CopyEncryptionRequest(t *testing.T) {
 			metadata.EncryptionKeyID = tc.sseKeyID
 			metadata.ACL = tc.acl
 
-			err = mockS3.Copy(context.Background(), u, u, metadata)
+			err = mockS3.Copy(context.Background(), u, u, metadata, 1, 0)
 
 			if err != nil {
 				t.Errorf("Expected %v, but received %q", nil, err)
@@ -759,6 +1371,46 @@ func TestS3CopyEncryptionRequest(t *testing.T) {
 	}
 }
 
+func TestS3CopyMetadataDirective(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mockAPI := s3.New(unit.Session)
+
+	mockAPI.Handlers.Unmarshal.Clear()
+	mockAPI.Handlers.UnmarshalMeta.Clear()
+	mockAPI.Handlers.UnmarshalError.Clear()
+	mockAPI.Handlers.Send.Clear()
+
+	mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("<CopyObjectResult></CopyObjectResult>")),
+		}
+
+		assert.Equal(t, r.Operation.Name, "CopyObject")
+		assert.Equal(t, valueAtPath(r.Params, "MetadataDirective"), "REPLACE")
+		assert.Equal(t, valueAtPath(r.Params, "ContentType"), "text/css")
+		assert.DeepEqual(t, r.Params.(*s3.CopyObjectInput).Metadata, map[string]*string{
+			"project": aws.String("s5cmd"),
+		})
+	})
+
+	mockS3 := &S3{api: mockAPI}
+
+	metadata := Metadata{
+		MetadataDirective: "REPLACE",
+		ContentType:       "text/css",
+		UserDefined:       map[string]string{"project": "s5cmd"},
+	}
+
+	if err := mockS3.Copy(context.Background(), u, u, metadata, 1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestS3PutEncryptionRequest(t *testing.T) {
 	testcases := []struct {
 		name     string
@@ -855,6 +1507,107 @@ func TestS3PutEncryptionRequest(t *testing.T) {
 	}
 }
 
+func TestS3SSECustomerKeyRequest(t *testing.T) {
+	const (
+		key           = "0123456789abcdef0123456789abcdef"
+		copySourceKey = "fedcba9876543210fedcba9876543210"
+	)
+
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testcases := []struct {
+		name               string
+		operation          func(*S3) error
+		expectedCopySrcKey string
+		expectedOperation  string
+	}{
+		{
+			name: "stat",
+			operation: func(s *S3) error {
+				_, err := s.Stat(context.Background(), u)
+				return err
+			},
+			expectedOperation: "HeadObject",
+		},
+		{
+			name: "read",
+			operation: func(s *S3) error {
+				_, err := s.Read(context.Background(), u)
+				return err
+			},
+			expectedOperation: "GetObject",
+		},
+		{
+			name: "copy",
+			operation: func(s *S3) error {
+				metadata := Metadata{SSECustomerKey: key, SSECopySourceCustomerKey: copySourceKey}
+				return s.Copy(context.Background(), u, u, metadata, 1, 0)
+			},
+			expectedCopySrcKey: copySourceKey,
+			expectedOperation:  "CopyObject",
+		},
+		{
+			name: "put",
+			operation: func(s *S3) error {
+				metadata := Metadata{SSECustomerKey: key}
+				return s.Put(context.Background(), bytes.NewReader([]byte("content")), u, metadata, 1, 5242880)
+			},
+			expectedOperation: "PutObject",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.UnmarshalError.Clear()
+			mockAPI.Handlers.Send.Clear()
+
+			var called bool
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+
+				assert.Equal(t, r.Operation.Name, tc.expectedOperation)
+				called = true
+
+				assert.Equal(t, valueAtPath(r.Params, "SSECustomerAlgorithm"), "AES256")
+				assert.Equal(t, valueAtPath(r.Params, "SSECustomerKey"), key)
+				assert.Equal(t, r.HTTPRequest.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5") != "", true)
+
+				if tc.expectedCopySrcKey != "" {
+					assert.Equal(t, valueAtPath(r.Params, "CopySourceSSECustomerAlgorithm"), "AES256")
+					assert.Equal(t, valueAtPath(r.Params, "CopySourceSSECustomerKey"), tc.expectedCopySrcKey)
+				}
+			})
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				if awsErr, ok := r.Error.(awserr.Error); ok && awsErr.Code() == request.ErrCodeSerialization {
+					r.Error = nil
+				}
+			})
+
+			mockS3 := &S3{
+				api:            mockAPI,
+				uploader:       s3manager.NewUploaderWithClient(mockAPI),
+				sseCustomerKey: key,
+			}
+
+			if err := tc.operation(mockS3); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Assert(t, called)
+		})
+	}
+}
+
 func TestS3listObjectsV2(t *testing.T) {
 	const (
 		numObjectsToReturn = 10100
@@ -1257,6 +2010,223 @@ func TestAWSLogLevel(t *testing.T) {
 	}
 }
 
+func TestS3TaggingRequest(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags := map[string]string{"retention": "30d", "project": "s5cmd"}
+
+	testcases := []struct {
+		name              string
+		operation         func(*S3) error
+		expectedOperation string
+		expectedParams    map[string]interface{}
+	}{
+		{
+			name: "put",
+			operation: func(s *S3) error {
+				return s.Put(context.Background(), bytes.NewReader([]byte("content")), u, Metadata{Tags: tags}, 1, 5242880)
+			},
+			expectedOperation: "PutObject",
+			expectedParams: map[string]interface{}{
+				"Tagging": "project=s5cmd&retention=30d",
+			},
+		},
+		{
+			name: "copy",
+			operation: func(s *S3) error {
+				return s.Copy(context.Background(), u, u, Metadata{Tags: tags}, 1, 0)
+			},
+			expectedOperation: "CopyObject",
+			expectedParams: map[string]interface{}{
+				"Tagging":          "project=s5cmd&retention=30d",
+				"TaggingDirective": "REPLACE",
+			},
+		},
+		{
+			name: "copy without tags",
+			operation: func(s *S3) error {
+				return s.Copy(context.Background(), u, u, Metadata{}, 1, 0)
+			},
+			expectedOperation: "CopyObject",
+			expectedParams: map[string]interface{}{
+				"Tagging":          nil,
+				"TaggingDirective": nil,
+			},
+		},
+		{
+			name: "set tags",
+			operation: func(s *S3) error {
+				return s.SetTags(context.Background(), u, tags)
+			},
+			expectedOperation: "PutObjectTagging",
+			expectedParams: map[string]interface{}{
+				"Tagging.TagSet[0].Key":   "project",
+				"Tagging.TagSet[0].Value": "s5cmd",
+				"Tagging.TagSet[1].Key":   "retention",
+				"Tagging.TagSet[1].Value": "30d",
+			},
+		},
+		{
+			name: "delete tags",
+			operation: func(s *S3) error {
+				return s.DeleteTags(context.Background(), u)
+			},
+			expectedOperation: "DeleteObjectTagging",
+			expectedParams: map[string]interface{}{
+				"Key": "key",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.UnmarshalError.Clear()
+			mockAPI.Handlers.Send.Clear()
+
+			var called bool
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+
+				assert.Equal(t, r.Operation.Name, tc.expectedOperation)
+				called = true
+
+				for path, expected := range tc.expectedParams {
+					assert.Equal(t, valueAtPath(r.Params, path), expected, path)
+				}
+			})
+			mockAPI.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+				if awsErr, ok := r.Error.(awserr.Error); ok && awsErr.Code() == request.ErrCodeSerialization {
+					r.Error = nil
+				}
+			})
+
+			mockS3 := &S3{
+				api:      mockAPI,
+				uploader: s3manager.NewUploaderWithClient(mockAPI),
+			}
+
+			if err := tc.operation(mockS3); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assert.Assert(t, called)
+		})
+	}
+}
+
+func TestS3GetTags(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mockAPI := s3.New(unit.Session)
+
+	mockAPI.Handlers.Unmarshal.Clear()
+	mockAPI.Handlers.UnmarshalMeta.Clear()
+	mockAPI.Handlers.UnmarshalError.Clear()
+	mockAPI.Handlers.Send.Clear()
+
+	mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}
+		r.Data.(*s3.GetObjectTaggingOutput).TagSet = []*s3.Tag{
+			{Key: aws.String("project"), Value: aws.String("s5cmd")},
+		}
+	})
+
+	mockS3 := &S3{api: mockAPI}
+
+	tags, err := mockS3.GetTags(context.Background(), u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, tags, map[string]string{"project": "s5cmd"})
+}
+
+func TestS3Restore(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testcases := []struct {
+		name        string
+		err         error
+		expectedErr bool
+	}{
+		{
+			name: "restore",
+		},
+		{
+			name: "restore already in progress",
+			err:  awserr.New("RestoreAlreadyInProgress", "object restore is already in progress", nil),
+		},
+		{
+			name:        "not archived",
+			err:         awserr.New("InvalidObjectState", "operation is not valid for the object's storage class", nil),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.UnmarshalError.Clear()
+			mockAPI.Handlers.Send.Clear()
+
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+
+				assert.Equal(t, r.Operation.Name, "RestoreObject")
+				assert.Equal(t, valueAtPath(r.Params, "RestoreRequest.Days"), int64(7))
+				assert.Equal(t, valueAtPath(r.Params, "RestoreRequest.GlacierJobParameters.Tier"), "Bulk")
+
+				r.Error = tc.err
+			})
+
+			mockS3 := &S3{api: mockAPI}
+
+			err := mockS3.Restore(context.Background(), u, 7, "Bulk")
+			assert.Equal(t, err != nil, tc.expectedErr, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestParseRestoreStatus(t *testing.T) {
+	testcases := []struct {
+		header   string
+		expected RestoreStatus
+	}{
+		{header: "", expected: ""},
+		{header: `ongoing-request="true"`, expected: RestoreOngoing},
+		{header: `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`, expected: RestoreCompleted},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, parseRestoreStatus(tc.header), tc.expected, tc.header)
+	}
+}
+
 func valueAtPath(i interface{}, s string) interface{} {
 	v, err := awsutil.ValuesAtPath(i, s)
 	if err != nil || len(v) == 0 {
@@ -1295,3 +2265,94 @@ func (e tempError) Error() string { return e.err.Error() }
 func (e tempError) Temporary() bool { return e.temp }
 
 func (e *tempError) Unwrap() error { return e.err }
+
+func TestS3StatHeaders(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testcases := []struct {
+		name     string
+		output   s3.HeadObjectOutput
+		expected Object
+	}{
+		{
+			name: "headers",
+			output: s3.HeadObjectOutput{
+				ContentType:          aws.String("text/plain"),
+				ContentEncoding:      aws.String("gzip"),
+				ContentDisposition:   aws.String("inline"),
+				CacheControl:         aws.String("no-cache"),
+				Expires:              aws.String("Wed, 21 Oct 2015 07:28:00 GMT"),
+				ServerSideEncryption: aws.String("aws:kms"),
+				SSEKMSKeyId:          aws.String("key-id"),
+				StorageClass:         aws.String("STANDARD_IA"),
+				VersionId:            aws.String("version"),
+				Metadata:             map[string]*string{"file-mtime": aws.String("1600000000")},
+			},
+			expected: Object{
+				ContentType:        "text/plain",
+				ContentEncoding:    "gzip",
+				ContentDisposition: "inline",
+				CacheControl:       "no-cache",
+				Expires:            "Wed, 21 Oct 2015 07:28:00 GMT",
+				EncryptionMethod:   "aws:kms",
+				EncryptionKeyID:    "key-id",
+				StorageClass:       StorageClass("STANDARD_IA"),
+				VersionID:          "version",
+				UserDefined:        map[string]string{"file-mtime": "1600000000"},
+			},
+		},
+		{
+			name: "customer provided key",
+			output: s3.HeadObjectOutput{
+				SSECustomerAlgorithm: aws.String("AES256"),
+				VersionId:            aws.String("null"),
+			},
+			expected: Object{
+				EncryptionMethod: "SSE-C",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockAPI := s3.New(unit.Session)
+
+			mockAPI.Handlers.Unmarshal.Clear()
+			mockAPI.Handlers.UnmarshalMeta.Clear()
+			mockAPI.Handlers.UnmarshalError.Clear()
+			mockAPI.Handlers.Send.Clear()
+
+			mockAPI.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}
+
+				assert.Equal(t, r.Operation.Name, "HeadObject")
+				*r.Data.(*s3.HeadObjectOutput) = tc.output
+			})
+
+			mockS3 := &S3{api: mockAPI}
+
+			obj, err := mockS3.Stat(context.Background(), u)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, obj.ContentType, tc.expected.ContentType)
+			assert.Equal(t, obj.ContentEncoding, tc.expected.ContentEncoding)
+			assert.Equal(t, obj.ContentDisposition, tc.expected.ContentDisposition)
+			assert.Equal(t, obj.CacheControl, tc.expected.CacheControl)
+			assert.Equal(t, obj.Expires, tc.expected.Expires)
+			assert.Equal(t, obj.EncryptionMethod, tc.expected.EncryptionMethod)
+			assert.Equal(t, obj.EncryptionKeyID, tc.expected.EncryptionKeyID)
+			assert.Equal(t, obj.StorageClass, tc.expected.StorageClass)
+			assert.Equal(t, obj.VersionID, tc.expected.VersionID)
+			assert.DeepEqual(t, obj.UserDefined, tc.expected.UserDefined)
+		})
+	}
+}
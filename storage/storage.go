@@ -9,6 +9,7 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"time"
@@ -52,12 +53,12 @@ type Storage interface {
 
 	// Copy src to dst, optionally setting the given metadata. Src and dst
 	// arguments are of the same type. If src is a remote type, server side
-	// copying will be used.
-	Copy(ctx context.Context, src, dst *url.URL, metadata Metadata) error
+	// copying will be used, in parts of the given size for large objects.
+	Copy(ctx context.Context, src, dst *url.URL, metadata Metadata, concurrency int, partSize int64) error
 }
 
 func NewLocalClient(opts Options) *Filesystem {
-	return &Filesystem{dryRun: opts.DryRun}
+	return &Filesystem{dryRun: opts.DryRun, useIgnoreFiles: opts.UseIgnoreFiles}
 }
 
 func NewRemoteClient(ctx context.Context, url *url.URL, opts Options) (*S3, error) {
@@ -72,6 +73,7 @@ func NewRemoteClient(ctx context.Context, url *url.URL, opts Options) (*S3, erro
 		RequestPayer:           opts.RequestPayer,
 		Profile:                opts.Profile,
 		CredentialFile:         opts.CredentialFile,
+		SSECustomerKey:         opts.SSECustomerKey,
 		LogLevel:               opts.LogLevel,
 		bucket:                 url.Bucket,
 		region:                 opts.region,
@@ -79,11 +81,106 @@ func NewRemoteClient(ctx context.Context, url *url.URL, opts Options) (*S3, erro
 	return newS3Storage(ctx, newOpts)
 }
 
-func NewClient(ctx context.Context, url *url.URL, opts Options) (Storage, error) {
-	if url.IsRemote() {
+// RemoteStorage is the interface of the remote storages which objects can be
+// read, downloaded and uploaded in addition to the common storage operations.
+type RemoteStorage interface {
+	Storage
+
+	// Read returns a reader of the src object.
+	Read(ctx context.Context, src *url.URL) (io.ReadCloser, error)
+
+	// ReadRange returns a reader of the length bytes of the src object
+	// which start at the given offset.
+	ReadRange(ctx context.Context, src *url.URL, offset, length int64) (io.ReadCloser, error)
+
+	// Get downloads the src object into dst, and returns its size.
+	Get(ctx context.Context, src *url.URL, dst io.WriterAt, concurrency int, partSize int64) (int64, error)
+
+	// Put uploads the content of src to dst with the given metadata.
+	Put(ctx context.Context, src io.Reader, dst *url.URL, metadata Metadata, concurrency int, partSize int64) error
+
+	// CreateDir creates an object which represents the dst directory.
+	CreateDir(ctx context.Context, dst *url.URL, metadata Metadata) error
+}
+
+// TaggingStorage is the interface of the remote storages which support
+// object tags in addition to the common storage operations.
+type TaggingStorage interface {
+	Storage
+
+	// GetTags returns the tags of the src object.
+	GetTags(ctx context.Context, src *url.URL) (map[string]string, error)
+
+	// SetTags replaces the tags of the dst object with the given tags.
+	SetTags(ctx context.Context, dst *url.URL, tags map[string]string) error
+
+	// DeleteTags removes all tags of the dst object.
+	DeleteTags(ctx context.Context, dst *url.URL) error
+}
+
+// NewTaggingStorage returns the storage of the given URL. It fails if the
+// backend of the URL does not support object tags.
+func NewTaggingStorage(ctx context.Context, url *url.URL, opts Options) (TaggingStorage, error) {
+	client, err := NewClient(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tagging, ok := client.(TaggingStorage)
+	if !ok {
+		return nil, fmt.Errorf("%q storage does not support object tags", url.Scheme)
+	}
+	return tagging, nil
+}
+
+// BackendFunc creates the storage of a backend for the given URL.
+type BackendFunc func(ctx context.Context, url *url.URL, opts Options) (Storage, error)
+
+// backends are the remote storage backends, keyed by their URL schemes.
+var backends = map[string]BackendFunc{}
+
+func init() {
+	RegisterBackend("s3", func(ctx context.Context, url *url.URL, opts Options) (Storage, error) {
 		return NewRemoteClient(ctx, url, opts)
+	})
+	RegisterBackend(memoryScheme, newMemoryStorage)
+}
+
+// RegisterBackend registers a remote storage backend for the URLs with the
+// given scheme. It is not safe to call concurrently with NewClient, hence
+// backends should be registered in init functions.
+func RegisterBackend(scheme string, fn BackendFunc) {
+	url.RegisterScheme(scheme)
+	backends[scheme] = fn
+}
+
+// NewClient returns the storage of the given URL, which is either the local
+// filesystem or the remote storage backend registered for the URL scheme.
+func NewClient(ctx context.Context, url *url.URL, opts Options) (Storage, error) {
+	if !url.IsRemote() {
+		return NewLocalClient(opts), nil
+	}
+
+	fn, ok := backends[url.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no storage backend for %q urls", url.Scheme)
+	}
+	return fn(ctx, url, opts)
+}
+
+// NewRemoteStorage returns the remote storage of the given URL. It fails if
+// the backend of the URL does not support reading and writing objects.
+func NewRemoteStorage(ctx context.Context, url *url.URL, opts Options) (RemoteStorage, error) {
+	client, err := NewClient(ctx, url, opts)
+	if err != nil {
+		return nil, err
 	}
-	return NewLocalClient(opts), nil
+
+	remote, ok := client.(RemoteStorage)
+	if !ok {
+		return nil, fmt.Errorf("%q storage does not support transferring objects", url.Scheme)
+	}
+	return remote, nil
 }
 
 // Options stores configuration for storage.
@@ -99,6 +196,8 @@ type Options struct {
 	RequestPayer           string
 	Profile                string
 	CredentialFile         string
+	SSECustomerKey         string
+	UseIgnoreFiles         bool
 	bucket                 string
 	region                 string
 }
@@ -122,8 +221,29 @@ type Object struct {
 	Err          error        `json:"error,omitempty"`
 	retryID      string
 
-	// the VersionID field exist only for JSON Marshall, it must not be used for
-	// any other purpose. URL.VersionID must be used instead.
+	// DeleteMarker reports whether the object is a delete marker. It is only
+	// set by the listings of object versions.
+	DeleteMarker bool `json:"delete_marker,omitempty"`
+
+	// The headers of the object below are only set by Stat.
+	ContentType        string        `json:"content_type,omitempty"`
+	ContentEncoding    string        `json:"content_encoding,omitempty"`
+	ContentDisposition string        `json:"content_disposition,omitempty"`
+	CacheControl       string        `json:"cache_control,omitempty"`
+	Expires            string        `json:"expires,omitempty"`
+	EncryptionMethod   string        `json:"encryption,omitempty"`
+	EncryptionKeyID    string        `json:"encryption_key_id,omitempty"`
+	Restore            RestoreStatus `json:"restore,omitempty"`
+
+	// UserDefined is the user metadata of the object. It is only set by
+	// Stat.
+	UserDefined map[string]string `json:"-"`
+
+	// the VersionID field is the version of the object in JSON output, it
+	// must not be used for any other purpose. URL.VersionID must be used
+	// instead. It is set from URL.VersionID when the object is marshalled,
+	// and by Stat to the current version of the object if no version is
+	// given in the URL.
 	VersionID string `json:"version_id,omitempty"`
 }
 
@@ -134,7 +254,7 @@ func (o *Object) String() string {
 
 // JSON returns the JSON representation of Object.
 func (o *Object) JSON() string {
-	if o.URL != nil {
+	if o.URL != nil && o.URL.VersionID != "" {
 		o.VersionID = o.URL.VersionID
 	}
 	return strutil.JSON(o)
@@ -224,6 +344,23 @@ func (s StorageClass) IsGlacier() bool {
 	return s == "GLACIER"
 }
 
+// IsArchived reports whether the objects of the storage class must be
+// restored before they can be read.
+func (s StorageClass) IsArchived() bool {
+	return s == "GLACIER" || s == "DEEP_ARCHIVE"
+}
+
+// RestoreStatus is the status of the restore of an archived object.
+type RestoreStatus string
+
+const (
+	// RestoreOngoing is the status of the objects which are being restored.
+	RestoreOngoing RestoreStatus = "ongoing"
+	// RestoreCompleted is the status of the restored objects, which can be
+	// read until their restored copies expire.
+	RestoreCompleted RestoreStatus = "completed"
+)
+
 type Metadata struct {
 	ACL                string
 	CacheControl       string
@@ -240,6 +377,25 @@ type Metadata struct {
 	FileUID            string
 	FileGID            string
 
+	// SSECustomerKey is the customer provided key to encrypt the object with
+	// on the server side, and SSECopySourceCustomerKey is the key of the
+	// source object of a copy.
+	SSECustomerKey           string
+	SSECopySourceCustomerKey string
+
+	// Tags are the tags of the object. The tags of the source object are
+	// kept on copies if no tags are given.
+	Tags map[string]string
+
+	// MetadataDirective is the metadata directive of remote copies. The
+	// metadata of the source object is replaced with the given metadata if
+	// it is REPLACE, and kept otherwise.
+	MetadataDirective string
+
+	// SourceSize is the size of the source object of a remote copy. The
+	// sources larger than 5GiB are copied in parts.
+	SourceSize int64
+
 	UserDefined map[string]string
 }
 
@@ -261,6 +417,8 @@ func (o Object) ToBytes() []byte {
 	enc.Encode(o.ModTime.Format(time.RFC3339Nano))
 	enc.Encode(o.Type.mode)
 	enc.Encode(o.Size)
+	enc.Encode(o.Etag)
+	enc.Encode(o.StorageClass)
 
 	return buf.Bytes()
 }
@@ -279,6 +437,8 @@ func FromBytes(data []byte) extsort.SortType {
 	o.ModTime = &tmp
 	dec.Decode(&o.Type.mode)
 	dec.Decode(&o.Size)
+	dec.Decode(&o.Etag)
+	dec.Decode(&o.StorageClass)
 	return o
 }
 
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/lanrat/extsort"
 	"github.com/peak/s5cmd/v2/strutil"
@@ -30,6 +31,33 @@ const (
 	matchAllRe string = ".*"
 )
 
+// remoteSchemes are the schemes of remote URLs. Schemes other than s3 are
+// registered by the storage backends.
+var remoteSchemes = struct {
+	sync.RWMutex
+	schemes map[string]struct{}
+}{
+	schemes: map[string]struct{}{"s3": {}},
+}
+
+// RegisterScheme registers the scheme of a remote storage backend, so that
+// URLs with the scheme are parsed as remote URLs.
+func RegisterScheme(scheme string) {
+	remoteSchemes.Lock()
+	defer remoteSchemes.Unlock()
+
+	remoteSchemes.schemes[scheme] = struct{}{}
+}
+
+// isRemoteScheme reports whether the scheme belongs to a remote storage.
+func isRemoteScheme(scheme string) bool {
+	remoteSchemes.RLock()
+	defer remoteSchemes.RUnlock()
+
+	_, ok := remoteSchemes.schemes[scheme]
+	return ok
+}
+
 type urlType int
 
 const (
@@ -99,8 +127,8 @@ func New(s string, opts ...Option) (*URL, error) {
 		return url, nil
 	}
 
-	if scheme != "s3" {
-		return nil, fmt.Errorf("s3 url should start with %q", s3Scheme)
+	if !isRemoteScheme(scheme) {
+		return nil, fmt.Errorf("url scheme %q is not supported", scheme)
 	}
 
 	parts := strings.SplitN(rest, s3Separator, 2)
@@ -112,7 +140,7 @@ func New(s string, opts ...Option) (*URL, error) {
 	}
 
 	if bucket == "" {
-		return nil, fmt.Errorf("s3 url should have a bucket")
+		return nil, fmt.Errorf("%s url should have a bucket", scheme)
 	}
 
 	if hasGlobCharacter(bucket) {
@@ -121,7 +149,7 @@ func New(s string, opts ...Option) (*URL, error) {
 
 	url := &URL{
 		Type:   remoteObject,
-		Scheme: "s3",
+		Scheme: scheme,
 		Bucket: bucket,
 		Path:   key,
 	}
@@ -455,7 +483,7 @@ func hasGlobCharacter(s string) bool {
 }
 
 func (u *URL) EscapedPath() string {
-	sourceKey := strings.TrimPrefix(u.String(), "s3://")
+	sourceKey := strings.TrimPrefix(u.String(), u.Scheme+"://")
 	sourceKeyElements := strings.Split(sourceKey, "/")
 	for i, element := range sourceKeyElements {
 		sourceKeyElements[i] = url.QueryEscape(element)
@@ -61,6 +61,11 @@ func TestNew(t *testing.T) {
 			object:  "s3://a*b",
 			wantErr: true,
 		},
+		{
+			name:    "error_if_scheme_is_not_registered",
+			object:  "unknown://bucket/key",
+			wantErr: true,
+		},
 		{
 			name:   "url_with_no_wildcard",
 			object: "s3://bucket/key",
@@ -119,6 +124,33 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithRegisteredScheme(t *testing.T) {
+	RegisterScheme("test")
+
+	got, err := New("test://bucket/key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &URL{
+		Scheme:    "test",
+		Bucket:    "bucket",
+		Path:      "key",
+		Prefix:    "key",
+		Delimiter: "/",
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(URL{})); diff != "" {
+		t.Errorf("URL mismatch (-want +got):\n%v", diff)
+	}
+
+	if !got.IsRemote() {
+		t.Errorf("expected URL with registered scheme to be remote")
+	}
+	if got.Absolute() != "test://bucket/key" {
+		t.Errorf("unexpected absolute URL: %v", got.Absolute())
+	}
+}
+
 func TestJoin(t *testing.T) {
 	tests := []struct {
 		name       string
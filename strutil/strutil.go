@@ -3,6 +3,7 @@ package strutil
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -37,6 +38,46 @@ func HumanizeBytes(b int64) string {
 	return fmt.Sprintf("%.1f%s", float64(b)/float64(div), suffix)
 }
 
+// byteUnits are the multipliers of the size suffixes, which are powers of
+// 1024.
+var byteUnits = map[string]float64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+	"P": 1 << 50,
+}
+
+// ParseBytes parses a size in bytes which may end with a unit, e.g. "512",
+// "10K", "1.5GB" or "2MiB". It is the reverse of HumanizeBytes.
+func ParseBytes(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if strings.HasSuffix(s, "IB") {
+		s = strings.TrimSuffix(s, "IB")
+	} else {
+		s = strings.TrimSuffix(s, "B")
+	}
+
+	var unit string
+	if s != "" {
+		if last := s[len(s)-1:]; last >= "A" && last <= "Z" {
+			unit, s = last, s[:len(s)-1]
+		}
+	}
+
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(n * multiplier), nil
+}
+
 // JSON is a helper function for creating JSON-encoded strings.
 func JSON(v interface{}) string {
 	bytes, _ := json.Marshal(v)
@@ -1,6 +1,10 @@
 package strutil
 
-import "testing"
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
 
 func TestCapitalizeFirstLetter(t *testing.T) {
 	tests := []struct {
@@ -74,3 +78,41 @@ func Test_WildCardToRegexp(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBytes(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		value     string
+		expected  int64
+		expectErr bool
+	}{
+		{value: "0", expected: 0},
+		{value: "512", expected: 512},
+		{value: "512B", expected: 512},
+		{value: "10K", expected: 10 * 1024},
+		{value: "10kb", expected: 10 * 1024},
+		{value: "2MiB", expected: 2 * 1024 * 1024},
+		{value: "1.5GB", expected: 3 * 512 * 1024 * 1024},
+		{value: "1T", expected: 1 << 40},
+		{value: "-1K", expectErr: true},
+		{value: "10X", expectErr: true},
+		{value: "GB", expectErr: true},
+		{value: "", expectErr: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseBytes(tc.value)
+			if tc.expectErr {
+				assert.ErrorContains(t, err, "invalid size")
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.expected)
+		})
+	}
+}